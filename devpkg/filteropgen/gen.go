@@ -1,17 +1,21 @@
 package filtergen
 
 import (
+	"encoding"
 	"fmt"
 	"go/types"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/octohelm/gengo/pkg/camelcase"
 	"github.com/octohelm/gengo/pkg/gengo"
 	"github.com/octohelm/gengo/pkg/gengo/snippet"
+	typesx "github.com/octohelm/x/types"
 
 	tablegenutil "github.com/octohelm/storage/devpkg/tablegen/util"
 	"github.com/octohelm/storage/pkg/filter"
+	"github.com/octohelm/storage/pkg/sort"
 	"github.com/octohelm/storage/pkg/sqlbuilder"
 	"github.com/octohelm/storage/pkg/sqlpipe"
 	sqlpipefilter "github.com/octohelm/storage/pkg/sqlpipe/filter"
@@ -67,6 +71,7 @@ func (g *filteropGen) GenerateType(c gengo.Context, srcNamed *types.Named) error
 
 func (g *filteropGen) generateIndexedFilter(c gengo.Context, t sqlbuilder.Table, named *types.Named, domainName, ofDomainName string) {
 	indexedFields := make([]string, 0)
+	sortableFields := make([]string, 0)
 
 	cols := map[string]bool{}
 
@@ -114,18 +119,12 @@ func (g *filteropGen) generateIndexedFilter(c gengo.Context, t sqlbuilder.Table,
 			return ""
 		}())
 
-		domainFieldName := camelcase.LowerCamelCase(fieldName)
-
-		if jsonTag, ok := def.StructTag.Lookup("json"); ok {
-			if jsonTag != "-" && jsonTag != "" {
-				domainFieldName = strings.SplitN(jsonTag, ",", 2)[0]
-			}
-		}
+		domainFieldName := domainFieldNameOf(t, fieldName)
 
 		c.RenderT(`
 type @ModelTypeName'By@DomainFieldName struct {
 	@fieldComment
-	@DomainFieldName *@filterFilter[@FieldType] `+"`"+`name:"@domainName~@domainFieldName,omitzero" in:"query"`+"`"+`
+	@DomainFieldName *@filterFilter[@FieldType] `+"`"+`name:"@domainName~@domainFieldName,omitzero" in:"query"@opsTag`+"`"+`
 }
 
 func (f *@ModelTypeName'By@DomainFieldName) OperatorType() @sqlpipeOperatorType {
@@ -145,6 +144,7 @@ func (f *@ModelTypeName'By@DomainFieldName) Next(src @sqlpipeSource[@Type]) @sql
 			"domainName":      snippet.ID(camelcase.LowerKebabCase(domainName)),
 			"DomainFieldName": snippet.ID(camelcase.UpperCamelCase(domainFieldName)),
 			"domainFieldName": snippet.ID(domainFieldName),
+			"opsTag":          snippet.ID(opsTagOf(def)),
 
 			"sqlpipeSource":         snippet.PkgExposeFor[sqlpipe.P]("Source"),
 			"sqlpipeOperatorType":   snippet.PkgExposeFor[sqlpipe.OperatorType](),
@@ -155,6 +155,8 @@ func (f *@ModelTypeName'By@DomainFieldName) Next(src @sqlpipeSource[@Type]) @sql
 
 		_, sortable := def.StructTag.Lookup("sortable")
 		if sortable {
+			sortableFields = append(sortableFields, fieldName)
+
 			c.RenderT(`
 type @ModelTypeName'SortBy@DomainFieldName struct {
 }
@@ -192,4 +194,237 @@ func (f *@ModelTypeName'SortBy@DomainFieldName) Sort(src @sqlpipeSource[@Type],
 			})
 		}
 	}
+
+	if len(indexedFields) == 0 {
+		return
+	}
+
+	c.RenderT(`
+@fieldsComment
+var @ModelTypeName'Fields = @sqlpipefilterFields{
+	@fieldValues
+}
+`, snippet.Args{
+		"ModelTypeName": snippet.ID(camelcase.UpperCamelCase(domainModel)),
+		"fieldsComment": snippet.Comment(fmt.Sprintf("%sFields 汇总 %s 的可筛选字段，用于翻译 filter.Composed", camelcase.UpperCamelCase(domainModel), camelcase.UpperCamelCase(domainModel))),
+
+		"sqlpipefilterFields": snippet.PkgExposeFor[sqlpipefilter.P]("Fields"),
+
+		"fieldValues": snippet.Snippets(func(yield func(snippet.Snippet) bool) {
+			for _, fieldName := range indexedFields {
+				if !yield(snippet.T(`
+@sqlpipefilterFieldOf(@name, @Type'T.@FieldName),
+`, snippet.Args{
+					"Type":      snippet.ID(named.Obj()),
+					"FieldName": snippet.ID(fieldName),
+					"name":      snippet.Value(camelcase.LowerKebabCase(domainName) + "~" + domainFieldNameOf(t, fieldName)),
+
+					"sqlpipefilterFieldOf": snippet.PkgExposeFor[sqlpipefilter.P]("FieldOf"),
+				})) {
+					return
+				}
+			}
+		}),
+	})
+
+	if len(sortableFields) > 0 {
+		g.generateSorter(c, t, named, domainModel, domainName, sortableFields)
+	}
+}
+
+func (g *filteropGen) generateSorter(c gengo.Context, t sqlbuilder.Table, named *types.Named, domainModel string, domainName string, sortableFields []string) {
+	modelTypeName := camelcase.UpperCamelCase(domainModel)
+
+	c.RenderT(`
+@sortFieldComment
+type @ModelTypeName'SortField string
+
+const (
+	@sortFieldValues
+)
+
+func (@ModelTypeName'SortField) EnumValues() []any {
+	return []any{
+		@sortFieldNames
+	}
+}
+
+func (v @ModelTypeName'SortField) Label() string {
+	switch v {
+	@sortFieldLabels
+	}
+	return string(v)
+}
+
+func (v *@ModelTypeName'SortField) UnmarshalText(data []byte) error {
+	x := @ModelTypeName'SortField(data)
+	for _, e := range x.EnumValues() {
+		if e == any(x) {
+			*v = x
+			return nil
+		}
+	}
+	return &@filterErrUnsupportedQLField{FieldName: string(data)}
+}
+
+@sortFieldsComment
+var @ModelTypeName'SortFields = @sqlpipefilterSortFields[@Type, @ModelTypeName'SortField]{
+	@sortFieldColumns
+}
+
+@sortComment
+type @ModelTypeName'Sort struct {
+	Sort []@sortBy[@ModelTypeName'SortField] `+"`"+`name:"sort,omitzero" in:"query"`+"`"+`
+}
+
+func (s *@ModelTypeName'Sort) OperatorType() @sqlpipeOperatorType {
+	return @sqlpipeOperatorSort
+}
+
+func (s *@ModelTypeName'Sort) Next(src @sqlpipeSource[@Type]) @sqlpipeSource[@Type] {
+	return src.Pipe(@sqlpipefilterAsSort(@ModelTypeName'SortFields, s.Sort))
+}
+`, snippet.Args{
+		"ModelTypeName": snippet.ID(modelTypeName),
+		"Type":          snippet.ID(named.Obj()),
+
+		"sortFieldComment":  snippet.Comment(fmt.Sprintf("%sSortField 表示 %s 的可排序字段", modelTypeName, modelTypeName)),
+		"sortFieldsComment": snippet.Comment(fmt.Sprintf("%sSortFields 汇总 %s 可排序字段对应的列", modelTypeName, modelTypeName)),
+		"sortComment":       snippet.Comment(fmt.Sprintf("%sSort 按 sort 参数对 %s 多键排序，未参与排序的主键列作为兜底", modelTypeName, modelTypeName)),
+
+		"sortFieldValues": snippet.Snippets(func(yield func(snippet.Snippet) bool) {
+			for _, fieldName := range sortableFields {
+				if !yield(snippet.T(`
+@ModelTypeName'SortField@FieldName @ModelTypeName'SortField = @name
+`, snippet.Args{
+					"ModelTypeName": snippet.ID(modelTypeName),
+					"FieldName":     snippet.ID(camelcase.UpperCamelCase(domainFieldNameOf(t, fieldName))),
+					"name":          snippet.Value(camelcase.LowerKebabCase(domainName) + "~" + domainFieldNameOf(t, fieldName)),
+				})) {
+					return
+				}
+			}
+		}),
+		"sortFieldNames": snippet.Snippets(func(yield func(snippet.Snippet) bool) {
+			for _, fieldName := range sortableFields {
+				if !yield(snippet.T(`
+@ModelTypeName'SortField@FieldName,
+`, snippet.Args{
+					"ModelTypeName": snippet.ID(modelTypeName),
+					"FieldName":     snippet.ID(camelcase.UpperCamelCase(domainFieldNameOf(t, fieldName))),
+				})) {
+					return
+				}
+			}
+		}),
+		"sortFieldLabels": snippet.Snippets(func(yield func(snippet.Snippet) bool) {
+			for _, fieldName := range sortableFields {
+				if comment := sqlbuilder.GetColumnDef(t.F(fieldName)).Comment; comment != "" {
+					if !yield(snippet.T(`
+case @ModelTypeName'SortField@FieldName:
+	return @label
+`, snippet.Args{
+						"ModelTypeName": snippet.ID(modelTypeName),
+						"FieldName":     snippet.ID(camelcase.UpperCamelCase(domainFieldNameOf(t, fieldName))),
+						"label":         snippet.Value(comment),
+					})) {
+						return
+					}
+				}
+			}
+		}),
+		"sortFieldColumns": snippet.Snippets(func(yield func(snippet.Snippet) bool) {
+			for _, fieldName := range sortableFields {
+				if !yield(snippet.T(`
+@ModelTypeName'SortField@DomainFieldName: @Type'T.@FieldName,
+`, snippet.Args{
+					"ModelTypeName":   snippet.ID(modelTypeName),
+					"Type":            snippet.ID(named.Obj()),
+					"FieldName":       snippet.ID(fieldName),
+					"DomainFieldName": snippet.ID(camelcase.UpperCamelCase(domainFieldNameOf(t, fieldName))),
+				})) {
+					return
+				}
+			}
+		}),
+
+		"filterErrUnsupportedQLField": snippet.PkgExposeFor[filter.ErrUnsupportedQLField](),
+		"sortBy":                      snippet.PkgExposeFor[sort.Order]("By"),
+		"sqlpipeSource":               snippet.PkgExposeFor[sqlpipe.P]("Source"),
+		"sqlpipeOperatorType":         snippet.PkgExposeFor[sqlpipe.OperatorType](),
+		"sqlpipeOperatorSort":         snippet.PkgExposeFor[sqlpipe.P]("OperatorSort"),
+		"sqlpipefilterSortFields":     snippet.PkgExposeFor[sqlpipefilter.P]("SortFields"),
+		"sqlpipefilterAsSort":         snippet.PkgExposeFor[sqlpipefilter.P]("AsSort"),
+	})
+}
+
+var typeTimeLike = reflect.TypeFor[interface{ Unix() int64 }]()
+
+// timeOps 为时间列声明的操作符，在比较之外开放 between/since/before/day。
+var timeOps = []filter.Op{
+	filter.OP__EQ, filter.OP__NEQ, filter.OP__IN, filter.OP__NOTIN,
+	filter.OP__GT, filter.OP__GTE, filter.OP__LT, filter.OP__LTE,
+	filter.OP__BETWEEN, filter.OP__SINCE, filter.OP__BEFORE, filter.OP__DAY,
+}
+
+// opsTagOf 生成 ops 标签声明字段可用的操作符，filter.Composed 的 Schema 与 Validate 均以其为准。
+func opsTagOf(def sqlbuilder.ColumnDef) string {
+	ops := make([]string, 0)
+	for _, op := range opsOf(def) {
+		ops = append(ops, strings.ToLower(op.String()))
+	}
+	return fmt.Sprintf(` ops:"%s"`, strings.Join(ops, ","))
+}
+
+var (
+	typeEnum          = reflect.TypeFor[interface{ EnumValues() []any }]()
+	typeTextMarshaler = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// opsOf 按列类型推断可用操作符：时间列（带 Unix() int64 方法的类型，如 time.Time、sqltype/time.Timestamp）开放时间操作符，
+// 文本列开放文本匹配，数值列开放比较，枚举与布尔列仅开放相等判断，可空列额外开放 isnull/notnull。
+func opsOf(def sqlbuilder.ColumnDef) []filter.Op {
+	ops := []filter.Op{filter.OP__EQ, filter.OP__NEQ, filter.OP__IN, filter.OP__NOTIN}
+
+	typ := def.Type
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == nil:
+	case def.Type.Implements(typesx.FromRType(typeTimeLike)):
+		ops = timeOps
+	case typ.Implements(typesx.FromRType(typeEnum)), typ.Kind() == reflect.Bool:
+	case typ.Kind() == reflect.String || def.Type.Implements(typesx.FromRType(typeTextMarshaler)):
+		ops = append(ops,
+			filter.OP__PREFIX, filter.OP__SUFFIX, filter.OP__CONTAINS, filter.OP__NOTCONTAINS,
+			filter.OP__IPREFIX, filter.OP__ISUFFIX, filter.OP__ICONTAINS, filter.OP__NOTICONTAINS,
+		)
+	default:
+		ops = append(ops, filter.OP__GT, filter.OP__GTE, filter.OP__LT, filter.OP__LTE)
+	}
+
+	if isNullable(def) {
+		ops = append(slices.Clip(ops), filter.OP__ISNULL, filter.OP__NOTNULL)
+	}
+
+	return ops
+}
+
+// isNullable 判断列是否可为 NULL，即声明了 null 或字段为指针类型。
+func isNullable(def sqlbuilder.ColumnDef) bool {
+	return def.Null || (def.Type != nil && def.Type.Kind() == reflect.Pointer)
+}
+
+func domainFieldNameOf(t sqlbuilder.Table, fieldName string) string {
+	domainFieldName := camelcase.LowerCamelCase(fieldName)
+
+	if jsonTag, ok := sqlbuilder.GetColumnDef(t.F(fieldName)).StructTag.Lookup("json"); ok {
+		if jsonTag != "-" && jsonTag != "" {
+			domainFieldName = strings.SplitN(jsonTag, ",", 2)[0]
+		}
+	}
+
+	return domainFieldName
 }
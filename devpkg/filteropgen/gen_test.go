@@ -49,10 +49,15 @@ func TestFilteropGen(t *testing.T) {
 	m := newTestingModule(t, map[string]string{
 		"sample/types.go": `package sample
 
+import "time"
+
 // +gengo:table
 // @def primary ID
 // @def unique_index i_name Name
 // @def index i_display_name DisplayName
+// @def index i_created_at CreatedAt
+// @def index i_nickname Nickname
+// @def index i_removed_at RemovedAt
 type User struct {
 	// 用户ID
 	ID uint64 ` + "`" + `db:"f_id,autoincrement"` + "`" + `
@@ -60,6 +65,12 @@ type User struct {
 	Name string ` + "`" + `db:"f_name,size=255,default=''"` + "`" + `
 	// 显示名
 	DisplayName string ` + "`" + `db:"f_display_name,size=255,default=''" json:"displayName" sortable:"true"` + "`" + `
+	// 创建时间
+	CreatedAt time.Time ` + "`" + `db:"f_created_at"` + "`" + `
+	// 昵称
+	Nickname *string ` + "`" + `db:"f_nickname,null"` + "`" + `
+	// 移除时间
+	RemovedAt *time.Time ` + "`" + `db:"f_removed_at,null"` + "`" + `
 }
 
 // +gengo:table
@@ -102,20 +113,36 @@ type FilterSet struct {
 			testingutil.Contains(
 				"Code generated by gengo:filterop DO NOT EDIT.",
 				"type UserByID struct",
-				`ID *filter.Filter[uint64] `+"`"+`name:"user~id,omitzero" in:"query"`+"`",
+				`ID *filter.Filter[uint64] `+"`"+`name:"user~id,omitzero" in:"query" ops:"eq,neq,in,notin,gt,gte,lt,lte"`+"`",
 				"type UserByName struct",
-				`Name *filter.Filter[string] `+"`"+`name:"user~name,omitzero" in:"query"`+"`",
+				`Name *filter.Filter[string] `+"`"+`name:"user~name,omitzero" in:"query" ops:"eq,neq,in,notin,prefix,suffix,contains,notcontains,iprefix,isuffix,icontains,noticontains"`+"`",
 				"type UserByDisplayName struct",
-				`DisplayName *filter.Filter[string] `+"`"+`name:"user~displayName,omitzero" in:"query"`+"`",
+				`DisplayName *filter.Filter[string] `+"`"+`name:"user~displayName,omitzero" in:"query" ops:"eq,neq,in,notin,prefix,suffix,contains,notcontains,iprefix,isuffix,icontains,noticontains"`+"`",
+				`CreatedAt *filter.Filter[time.Time] `+"`"+`name:"user~createdAt,omitzero" in:"query" ops:"eq,neq,in,notin,gt,gte,lt,lte,between,since,before,day"`+"`",
+				`Nickname *filter.Filter[*string] `+"`"+`name:"user~nickname,omitzero" in:"query" ops:"eq,neq,in,notin,prefix,suffix,contains,notcontains,iprefix,isuffix,icontains,noticontains,isnull,notnull"`+"`",
+				`RemovedAt *filter.Filter[*time.Time] `+"`"+`name:"user~removedAt,omitzero" in:"query" ops:"eq,neq,in,notin,gt,gte,lt,lte,between,since,before,day,isnull,notnull"`+"`",
 				"type UserSortByDisplayName struct",
 				`return "user~displayName"`,
 				`return "显示名"`,
+				"type UserSortField string",
+				`UserSortFieldDisplayName UserSortField = "user~displayName"`,
+				"var UserSortFields = sqlpipefilter.SortFields[User, UserSortField]{",
+				"UserSortFieldDisplayName: UserT.DisplayName,",
+				"type UserSort struct",
+				`Sort []sort.By[UserSortField] `+"`"+`name:"sort,omitzero" in:"query"`+"`",
+				"return src.Pipe(sqlpipefilter.AsSort(UserSortFields, s.Sort))",
 				"type OrgTeamByCode struct",
-				`Code *filter.Filter[string] `+"`"+`name:"org~code,omitzero" in:"query"`+"`",
+				`Code *filter.Filter[string] `+"`"+`name:"org~code,omitzero" in:"query" ops:"eq,neq,in,notin,prefix,suffix,contains,notcontains,iprefix,isuffix,icontains,noticontains"`+"`",
+				"var UserFields = sqlpipefilter.Fields{",
+				`sqlpipefilter.FieldOf("user~displayName", UserT.DisplayName),`,
+				"var OrgTeamFields = sqlpipefilter.Fields{",
+				`sqlpipefilter.FieldOf("org~code", TeamT.Code),`,
 			),
 			testingutil.NotContains(
 				"type AuditLogByTitle struct",
 				"type TeamSortByCode struct",
+				"type OrgTeamSortField",
+				"var AuditLogFields",
 			),
 		))),
 	)
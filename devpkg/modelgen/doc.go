@@ -0,0 +1,3 @@
+// Package modelgen 提供数据库 catalog 到模型的反向生成器，
+// 用于为已有数据库生成带 `db` 标签与 `@def` 索引指令的模型定义。
+package modelgen
@@ -0,0 +1,346 @@
+package modelgen
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"go/format"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/octohelm/gengo/pkg/camelcase"
+
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+)
+
+// DefaultTypeMapping 是数据库类型到 Go 类型的默认映射，键为小写且去掉长度的数据类型。
+//
+// Go 类型可带导入路径，如 encoding/json.RawMessage。
+var DefaultTypeMapping = TypeMapping{
+	"boolean": "bool",
+	"bool":    "bool",
+
+	"smallint":         "int16",
+	"integer":          "int32",
+	"int":              "int32",
+	"bigint":           "int64",
+	"serial":           "uint32",
+	"bigserial":        "uint64",
+	"unsigned big int": "uint64",
+
+	"real":             "float32",
+	"float":            "float64",
+	"double":           "float64",
+	"double precision": "float64",
+	"numeric":          "float64",
+	"decimal":          "float64",
+
+	"character varying": "string",
+	"varchar":           "string",
+	"character":         "string",
+	"char":              "string",
+	"text":              "string",
+	"uuid":              "string",
+
+	"bytea": "[]byte",
+	"blob":  "[]byte",
+
+	"json":  "encoding/json.RawMessage",
+	"jsonb": "encoding/json.RawMessage",
+
+	"date":                        "time.Time",
+	"datetime":                    "time.Time",
+	"timestamp":                   "time.Time",
+	"timestamp with time zone":    "time.Time",
+	"timestamp without time zone": "time.Time",
+}
+
+// TypeMapping 表示数据库类型到 Go 类型的映射。
+type TypeMapping map[string]string
+
+// Generator 把数据库 catalog 生成为带 db 标签与 @def 指令的 Go 模型。
+type Generator struct {
+	// Package 生成文件的包名，默认 model
+	Package string
+	// TablePrefix 生成类型名时去掉的表名前缀，默认 t_
+	TablePrefix string
+	// ColumnPrefix 生成字段名时去掉的列名前缀，默认 f_
+	ColumnPrefix string
+	// TypeMapping 覆盖 DefaultTypeMapping 中的映射
+	TypeMapping TypeMapping
+	// Strict 为 true 时遇到未映射的数据类型返回错误，否则以 string 生成并在字段前注释提醒
+	Strict bool
+}
+
+func (g *Generator) SetDefaults() {
+	if g.Package == "" {
+		g.Package = "model"
+	}
+	if g.TablePrefix == "" {
+		g.TablePrefix = "t_"
+	}
+	if g.ColumnPrefix == "" {
+		g.ColumnPrefix = "f_"
+	}
+}
+
+// Generate 按表名顺序生成 catalog 中全部表的模型源码。
+func (g *Generator) Generate(cat sqlbuilder.Catalog) ([]byte, error) {
+	g.SetDefaults()
+
+	tables := slices.SortedFunc(cat.Tables(), func(a, b sqlbuilder.Table) int {
+		return cmp.Compare(a.TableName(), b.TableName())
+	})
+
+	imports := map[string]bool{}
+	body := bytes.NewBuffer(nil)
+	unmapped := make([]string, 0)
+
+	for _, t := range tables {
+		unmapped = append(unmapped, g.writeTable(body, t, imports)...)
+	}
+
+	if g.Strict && len(unmapped) > 0 {
+		return nil, fmt.Errorf("unmapped data types of %s, declare them in TypeMapping", strings.Join(unmapped, ", "))
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	_, _ = fmt.Fprintf(buf, "package %s\n\n", g.Package)
+
+	if len(imports) > 0 {
+		buf.WriteString("import (\n")
+		for _, importPath := range slices.Sorted(maps.Keys(imports)) {
+			_, _ = fmt.Fprintf(buf, "\t%q\n", importPath)
+		}
+		buf.WriteString(")\n\n")
+	}
+
+	buf.Write(body.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+// writeTable 写入单个表的模型，返回数据类型未映射的列，形如 t_user.f_geom(geometry)。
+func (g *Generator) writeTable(w *bytes.Buffer, t sqlbuilder.Table, imports map[string]bool) []string {
+	unmapped := make([]string, 0)
+
+	typeName := camelcase.UpperCamelCase(strings.TrimPrefix(t.TableName(), g.TablePrefix))
+
+	_, _ = fmt.Fprintf(w, "// %s\n", typeName)
+	w.WriteString("// +gengo:table\n")
+
+	if defaultTableName := camelcase.LowerSnakeCase("t_" + typeName); defaultTableName != t.TableName() {
+		_, _ = fmt.Fprintf(w, "// +gengo:table:name=%s\n", t.TableName())
+	}
+
+	for _, d := range g.keyDefines(t) {
+		_, _ = fmt.Fprintf(w, "// @def %s\n", d)
+	}
+
+	for _, check := range slices.SortedFunc(sqlbuilder.GetTableChecks(t), func(a, b sqlbuilder.Check) int {
+		return cmp.Compare(a.Name(), b.Name())
+	}) {
+		_, _ = fmt.Fprintf(w, "// @def check %s %s\n", check.Name(), check.Expr())
+	}
+
+	_, _ = fmt.Fprintf(w, "type %s struct {\n", typeName)
+
+	for col := range t.Cols() {
+		def := sqlbuilder.GetColumnDef(col)
+
+		if def.Generated != nil {
+			// nullability of generated column follows its expression
+			def.Null = false
+			def.Default = nil
+		}
+
+		goType, known := g.goTypeOf(def, imports)
+		if !known {
+			unmapped = append(unmapped, fmt.Sprintf("%s.%s(%s)", t.TableName(), col.Name(), def.DataType))
+			_, _ = fmt.Fprintf(w, "\t// FIXME 未映射的数据类型 %s，暂以 string 生成，需在 TypeMapping 中声明映射\n", def.DataType)
+		}
+
+		if def.Generated != nil {
+			_, _ = fmt.Fprintf(w, "\t%s %s `db:%q generated:%q`\n", g.fieldNameOf(col.Name()), goType, g.dbTagOf(col.Name(), def), def.Generated.Expr)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "\t%s %s `db:%q`\n", g.fieldNameOf(col.Name()), goType, g.dbTagOf(col.Name(), def))
+	}
+
+	w.WriteString("}\n\n")
+
+	return unmapped
+}
+
+func (g *Generator) keyDefines(t sqlbuilder.Table) []string {
+	defines := make([]string, 0)
+	hasPrimary := false
+
+	keys := slices.Collect(t.Keys())
+
+	slices.SortStableFunc(keys, func(a, b sqlbuilder.Key) int {
+		// primary first
+		if a.IsPrimary() != b.IsPrimary() {
+			if a.IsPrimary() {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.Name(), b.Name())
+	})
+
+	for _, key := range keys {
+		keyDef, ok := key.(sqlbuilder.KeyDef)
+		if !ok {
+			continue
+		}
+
+		fields := make([]string, 0)
+		for _, o := range keyDef.FieldNameAndOptions() {
+			f := o.Name()
+			if !o.IsExpr() {
+				f = g.fieldNameOf(f)
+			}
+			if options := o.Options(); len(options) > 0 {
+				f += "," + strings.ToLower(strings.Join(options, ","))
+			}
+			fields = append(fields, f)
+		}
+
+		if key.IsPrimary() {
+			hasPrimary = true
+			defines = append(defines, "primary "+strings.Join(fields, " "))
+			continue
+		}
+
+		kind := "index"
+		if key.IsUnique() {
+			kind = "unique_index"
+		}
+
+		name := key.Name()
+		if method := strings.ToUpper(keyDef.Method()); method != "" && method != "BTREE" {
+			name += "," + method
+		}
+
+		define := kind + " " + name + " " + strings.Join(fields, " ")
+		if where := keyDef.Where(); where != "" {
+			define += " WHERE " + where
+		}
+
+		defines = append(defines, define)
+	}
+
+	if !hasPrimary {
+		// sqlite catalog has no primary key, use autoincrement column instead
+		for col := range t.Cols() {
+			if sqlbuilder.GetColumnDef(col).AutoIncrement {
+				defines = append([]string{"primary " + g.fieldNameOf(col.Name())}, defines...)
+				break
+			}
+		}
+	}
+
+	return defines
+}
+
+func (g *Generator) fieldNameOf(columnName string) string {
+	return camelcase.UpperCamelCase(strings.TrimPrefix(columnName, g.ColumnPrefix))
+}
+
+func (g *Generator) goTypeOf(def sqlbuilder.ColumnDef, imports map[string]bool) (string, bool) {
+	dataType := normalizeDataType(def.DataType)
+
+	goType, ok := g.TypeMapping[dataType]
+	if !ok {
+		goType, ok = DefaultTypeMapping[dataType]
+	}
+
+	if !ok {
+		goType = "string"
+	} else if def.AutoIncrement && strings.HasPrefix(goType, "int") {
+		goType = "u" + goType
+	}
+
+	if i := strings.LastIndex(goType, "."); i > 0 {
+		importPath := strings.TrimPrefix(goType[0:i], "*")
+		imports[importPath] = true
+		goType = goType[0:strings.Index(goType, importPath)] + importPath[strings.LastIndex(importPath, "/")+1:] + goType[i:]
+	}
+
+	if def.Null && !strings.HasPrefix(goType, "[]") && !strings.HasPrefix(goType, "*") {
+		goType = "*" + goType
+	}
+
+	return goType, ok
+}
+
+func (g *Generator) dbTagOf(columnName string, def sqlbuilder.ColumnDef) string {
+	tag := columnName
+
+	if def.AutoIncrement {
+		tag += ",autoincrement"
+	}
+
+	if def.Length > 0 && isSizedDataType(normalizeDataType(def.DataType)) {
+		tag += fmt.Sprintf(",size=%d", def.Length)
+	}
+
+	if def.Decimal > 0 {
+		tag += fmt.Sprintf(",decimal=%d", def.Decimal)
+	}
+
+	if def.Null {
+		tag += ",null"
+	}
+
+	if def.Generated != nil && def.Generated.Stored {
+		tag += ",stored"
+	}
+
+	if def.Default != nil {
+		// value with comma could not be expressed in tag
+		if dv := normalizeDefaultValue(*def.Default); dv != "" && !strings.ContainsAny(dv, ",\"`") {
+			tag += ",default=" + dv
+		}
+	}
+
+	return tag
+}
+
+func isSizedDataType(dataType string) bool {
+	switch dataType {
+	case "character varying", "varchar", "character", "char":
+		return true
+	}
+	return false
+}
+
+// normalizeDataType 去掉长度、约束与大小写差异，如 "VARCHAR(255)" 转为 "varchar"。
+func normalizeDataType(dataType string) string {
+	dataType = strings.ToLower(strings.TrimSpace(dataType))
+
+	if i := strings.Index(dataType, "("); i > 0 {
+		dataType = strings.TrimSpace(dataType[0:i])
+	}
+
+	// sqlite autoincrement column aliases the 64-bit rowid
+	if strings.HasSuffix(dataType, " primary key autoincrement") {
+		return "bigint"
+	}
+
+	return dataType
+}
+
+// normalizeDefaultValue 去掉 PostgreSQL 默认值的类型转换，如 "'0'::integer" 转为 "'0'"。
+func normalizeDefaultValue(dv string) string {
+	if strings.HasPrefix(dv, "'") {
+		if i := strings.LastIndex(dv, "'::"); i > 0 {
+			return dv[0 : i+1]
+		}
+	}
+	return dv
+}
@@ -0,0 +1,86 @@
+package modelgen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestGenerator(t *testing.T) {
+	cat := &sqlbuilder.Tables{}
+
+	cat.Add(
+		sqlbuilder.T(
+			"t_user",
+			sqlbuilder.Col("f_id", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "bigint", AutoIncrement: true})),
+			sqlbuilder.Col("f_name", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "character varying", Length: 255, Default: ptr("''::character varying")})),
+			sqlbuilder.Col("f_org_id", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "bigint", Default: ptr("'0'::bigint")})),
+			sqlbuilder.Col("f_age", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "integer", Default: ptr("0")})),
+			sqlbuilder.Col("f_profile", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "jsonb", Null: true})),
+			sqlbuilder.Col("f_geom", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "geometry"})),
+			sqlbuilder.PrimaryKey(nil, sqlbuilder.IndexFieldNameAndOptions("f_id")),
+			sqlbuilder.UniqueIndex("i_name", nil, sqlbuilder.IndexUsing("BTREE"), sqlbuilder.IndexFieldNameAndOptions("f_name")),
+			sqlbuilder.Index("i_org", nil, sqlbuilder.IndexUsing("HASH"), sqlbuilder.IndexFieldNameAndOptions("f_org_id,DESC")),
+			sqlbuilder.Index("i_lower_name", nil, sqlbuilder.IndexWhere("f_org_id <> 0"), sqlbuilder.IndexFieldNameAndOptions("(lower(f_name))")),
+		),
+		sqlbuilder.T(
+			"t_org_stat",
+			sqlbuilder.Col("f_id", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "INTEGER PRIMARY KEY AUTOINCREMENT", AutoIncrement: true})),
+			sqlbuilder.Col("f_score", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "REAL"})),
+			sqlbuilder.Col("f_rank", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "REAL", Null: true, Generated: &sqlbuilder.ColumnGenerated{Expr: "f_score * 2", Stored: true}})),
+			sqlbuilder.CheckConstraint("ck_score", "f_score >= 0"),
+		),
+	)
+
+	g := &Generator{
+		TypeMapping: TypeMapping{
+			"real": "float64",
+		},
+	}
+
+	code := MustValue(t, func() ([]byte, error) {
+		return g.Generate(cat)
+	})
+
+	// normalize spaces to ignore gofmt alignment
+	normalized := strings.Join(strings.Fields(string(code)), " ")
+
+	Then(t, "按表名顺序生成模型与导入",
+		Expect(strings.HasPrefix(normalized, `package model import ( "encoding/json" ) // OrgStat`), Equal(true)),
+		Expect(strings.Index(normalized, "type OrgStat struct") < strings.Index(normalized, "type User struct"), Equal(true)),
+	)
+
+	Then(t, "生成 db 标签与 @def 指令",
+		Expect(strings.Contains(normalized, "// User // +gengo:table // @def primary ID // @def index i_lower_name (lower(f_name)) WHERE f_org_id <> 0 // @def unique_index i_name Name // @def index i_org,HASH OrgID,desc type User struct {"), Equal(true)),
+		Expect(strings.Contains(normalized, "ID uint64 `db:\"f_id,autoincrement\"`"), Equal(true)),
+		Expect(strings.Contains(normalized, "Name string `db:\"f_name,size=255,default=''\"`"), Equal(true)),
+		Expect(strings.Contains(normalized, "OrgID int64 `db:\"f_org_id,default='0'\"`"), Equal(true)),
+		Expect(strings.Contains(normalized, "Profile *json.RawMessage `db:\"f_profile,null\"`"), Equal(true)),
+		Expect(strings.Contains(normalized, "Age int32 `db:\"f_age,default=0\"`"), Equal(true)),
+		Expect(strings.Contains(normalized, "// FIXME 未映射的数据类型 geometry，暂以 string 生成，需在 TypeMapping 中声明映射 Geom string `db:\"f_geom\"`"), Equal(true)),
+	)
+
+	Then(t, "SQLite 以自增列作为主键并支持自定义类型映射",
+		Expect(strings.Contains(normalized, "// OrgStat // +gengo:table // @def primary ID // @def check ck_score f_score >= 0 type OrgStat struct {"), Equal(true)),
+		Expect(strings.Contains(normalized, "ID uint64 `db:\"f_id,autoincrement\"` Score float64 `db:\"f_score\"`"), Equal(true)),
+		Expect(strings.Contains(normalized, "Rank float64 `db:\"f_rank,stored\" generated:\"f_score * 2\"`"), Equal(true)),
+	)
+
+	Then(t, "Strict 模式下未映射的数据类型返回错误",
+		ExpectDo(
+			func() error {
+				_, err := (&Generator{Strict: true}).Generate(cat)
+				return err
+			},
+			ErrorMatch(regexp.MustCompile(`unmapped data types of t_user.f_geom\(geometry\)`)),
+		),
+	)
+}
@@ -1,15 +1,21 @@
 package tablegen
 
 import (
+	"context"
+	"go/token"
 	"go/types"
 	"reflect"
+	"strings"
 
+	"github.com/octohelm/gengo/pkg/camelcase"
 	"github.com/octohelm/gengo/pkg/gengo"
 	"github.com/octohelm/gengo/pkg/gengo/snippet"
 
 	tablegenutil "github.com/octohelm/storage/devpkg/tablegen/util"
 	"github.com/octohelm/storage/pkg/sqlbuilder"
 	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/pkg/sqlpipe/ex"
 )
 
 func init() {
@@ -39,6 +45,7 @@ func (g *tableGen) GenerateType(c gengo.Context, named *types.Named) error {
 
 		g.generateIndexInterfaces(c, t, named)
 		g.generateTableStatics(c, t, named)
+		g.generateRepositoryMethods(c, t, named)
 
 		return nil
 	}
@@ -262,10 +269,16 @@ func (g *tableGen) generateIndexInterfaces(c gengo.Context, t sqlbuilder.Table,
 		return values
 	}
 
+	predicates := sqlbuilder.IndexPredicates{}
+
 	for key := range t.Keys() {
 		keyDef := key.(sqlbuilder.KeyDef)
 		fields := keyDef.FieldNameAndOptions()
 
+		if where := keyDef.Where(); where != "" {
+			predicates[key.Name()] = where
+		}
+
 		if key.IsPrimary() {
 			primary = toStringSlice(fields)
 		} else {
@@ -327,4 +340,159 @@ func (@Type) Indexes() @sqlbuilderIndexes {
 			"indexes":           snippet.Value(indexes),
 		})
 	}
+
+	if len(predicates) > 0 {
+		c.RenderT(`
+func (@Type) IndexPredicates() @sqlbuilderIndexPredicates {
+	return @predicates
+}
+
+`, snippet.Args{
+			"Type":                      snippet.ID(named.Obj()),
+			"sqlbuilderIndexPredicates": snippet.ID(reflect.TypeFor[sqlbuilder.IndexPredicates]()),
+			"predicates":                snippet.Value(predicates),
+		})
+	}
+
+	checks := sqlbuilder.Checks{}
+	for check := range sqlbuilder.GetTableChecks(t) {
+		checks[check.Name()] = check.Expr()
+	}
+
+	if len(checks) > 0 {
+		c.RenderT(`
+func (@Type) Checks() @sqlbuilderChecks {
+	return @checks
+}
+
+`, snippet.Args{
+			"Type":             snippet.ID(named.Obj()),
+			"sqlbuilderChecks": snippet.ID(reflect.TypeFor[sqlbuilder.Checks]()),
+			"checks":           snippet.Value(checks),
+		})
+	}
+}
+
+// generateRepositoryMethods 在 +gengo:table:repository 时为每个唯一索引生成 FindBy、UpdateBy、DeleteBy 方法。
+//
+// 软删除字段不作为参数，由 sqlpipe.From 自动追加未删除条件；
+// 实现 SoftDeleteFieldAndZeroValue 时默认软删除字段为 DeletedAt，可通过 +gengo:table:softdelete 指定。
+func (g *tableGen) generateRepositoryMethods(c gengo.Context, t sqlbuilder.Table, named *types.Named) {
+	tags, _ := c.Doc(named.Obj())
+
+	if _, ok := tags["gengo:table:repository"]; !ok {
+		return
+	}
+
+	softDeleteField := ""
+	if r, ok := tags["gengo:table:softdelete"]; ok && len(r) > 0 {
+		softDeleteField = r[0]
+	} else if types.NewMethodSet(types.NewPointer(named)).Lookup(nil, "SoftDeleteFieldAndZeroValue") != nil {
+		softDeleteField = "DeletedAt"
+	}
+
+	generated := map[string]bool{}
+
+	for key := range t.Keys() {
+		if !key.IsUnique() {
+			continue
+		}
+
+		hasExpr := false
+		fieldNames := make([]string, 0)
+		for _, o := range key.(sqlbuilder.KeyDef).FieldNameAndOptions() {
+			// expression key could not be mapped to params
+			if o.IsExpr() {
+				hasExpr = true
+				break
+			}
+			if n := o.Name(); n != softDeleteField {
+				fieldNames = append(fieldNames, n)
+			}
+		}
+
+		by := strings.Join(fieldNames, "And")
+		if hasExpr || len(fieldNames) == 0 || generated[by] {
+			continue
+		}
+		generated[by] = true
+
+		c.RenderT(`
+// FindBy@By 按唯一索引 @keyName 查询一条记录，未命中时返回 nil。
+func (t *table@Type) FindBy@By(ctx @contextContext, @params) (*@Type, error) {
+	return @exFromSource(@sqlpipeFrom[@Type]()).PipeE(
+		@wheres
+	).FindOne(ctx)
+}
+
+// UpdateBy@By 按唯一索引 @keyName 更新 columns 指定的列。
+func (t *table@Type) UpdateBy@By(ctx @contextContext, @params m *@Type, columns ...@modelscopedColumn[@Type]) error {
+	return @exFromSource(@sqlpipeFrom[@Type]()).PipeE(
+		@wheres
+		@sqlpipeDoUpdateSet(m, columns...),
+	).Commit(ctx)
+}
+
+// DeleteBy@By 按唯一索引 @keyName 删除记录，模型支持软删除时执行软删除。
+func (t *table@Type) DeleteBy@By(ctx @contextContext, @params) error {
+	return @exFromSource(@sqlpipeFrom[@Type]()).PipeE(
+		@wheres
+		@sqlpipeDoDelete[@Type](),
+	).Commit(ctx)
+}
+`, snippet.Args{
+			"Type":    snippet.ID(named.Obj()),
+			"By":      snippet.ID(by),
+			"keyName": snippet.ID(key.Name()),
+
+			"params": snippet.Snippets(func(yield func(snippet.Snippet) bool) {
+				for _, fieldName := range fieldNames {
+					if !yield(snippet.T(`@name @FieldType, `, snippet.Args{
+						"name":      snippet.ID(paramNameOf(fieldName)),
+						"FieldType": snippet.ID(sqlbuilder.GetColumnDef(t.F(fieldName)).Type.String()),
+					})) {
+						return
+					}
+				}
+			}),
+
+			"wheres": snippet.Snippets(func(yield func(snippet.Snippet) bool) {
+				for _, fieldName := range fieldNames {
+					if !yield(snippet.T(`
+@sqlpipeWhere(t.@FieldName, @sqlbuilderEq(@name)),
+`, snippet.Args{
+						"name":      snippet.ID(paramNameOf(fieldName)),
+						"FieldName": snippet.ID(fieldName),
+
+						"sqlpipeWhere": snippet.PkgExposeFor[sqlpipe.P]("Where"),
+						"sqlbuilderEq": snippet.PkgExposeFor[sqlbuilder.P]("Eq"),
+					})) {
+						return
+					}
+				}
+			}),
+
+			"contextContext":     snippet.PkgExposeFor[context.Context](),
+			"exFromSource":       snippet.PkgExposeFor[ex.P]("FromSource"),
+			"sqlpipeFrom":        snippet.PkgExposeFor[sqlpipe.P]("From"),
+			"sqlpipeDoUpdateSet": snippet.PkgExposeFor[sqlpipe.P]("DoUpdateSet"),
+			"sqlpipeDoDelete":    snippet.PkgExposeFor[sqlpipe.P]("DoDelete"),
+			"modelscopedColumn":  snippet.PkgExposeFor[modelscoped.P]("Column"),
+		})
+	}
+}
+
+func paramNameOf(fieldName string) string {
+	name := camelcase.LowerCamelCase(fieldName)
+
+	switch name {
+	case "ctx", "t", "m", "columns":
+		return name + "Value"
+	}
+
+	if token.IsKeyword(name) {
+		return name + "Value"
+	}
+
+	return name
 }
@@ -62,6 +62,8 @@ func (registry) Add(v any) {}
 // @def primary ID
 // @def unique_index i_name Name DeletedAt
 // @def index i_created_at CreatedAt,desc
+// @def check ck_created_at f_created_at >= 0
+// @def index i_lower_name (lower(f_name)) WHERE f_deleted_at = 0
 type User struct {
 	// 用户ID
 	ID uint64 ` + "`" + `db:"f_id,autoincrement"` + "`" + `
@@ -97,6 +99,9 @@ type User struct {
 				`func (User) PrimaryKey() []string {`,
 				`func (User) UniqueIndexes() sqlbuilder.Indexes {`,
 				`func (User) Indexes() sqlbuilder.Indexes {`,
+				`func (User) Checks() sqlbuilder.Checks {`,
+				`func (User) IndexPredicates() sqlbuilder.IndexPredicates {`,
+				`"(lower(f_name))"`,
 				"// 用户ID",
 				"ID modelscoped.TypedColumn[User, uint64]",
 				"Name modelscoped.TypedColumn[User, string]",
@@ -134,3 +139,55 @@ type hidden struct {
 		Expect(len(files), Equal(0)),
 	)
 }
+
+func TestTableGenRepository(t *testing.T) {
+	m := newTestingModule(t, map[string]string{
+		"sample/types.go": `package sample
+
+import "database/sql/driver"
+
+// +gengo:table
+// +gengo:table:repository
+// @def primary ID
+// @def unique_index i_name Name DeletedAt
+// @def unique_index i_org_usr UserID OrgID
+type User struct {
+	ID uint64 ` + "`" + `db:"f_id,autoincrement"` + "`" + `
+	Name string ` + "`" + `db:"f_name,size=255,default=''"` + "`" + `
+	UserID uint64 ` + "`" + `db:"f_user_id"` + "`" + `
+	OrgID uint64 ` + "`" + `db:"f_org_id"` + "`" + `
+	DeletedAt int64 ` + "`" + `db:"f_deleted_at,default='0'"` + "`" + `
+}
+
+func (v User) SoftDeleteFieldAndZeroValue() (string, driver.Value) {
+	return "DeletedAt", int64(0)
+}
+`,
+	})
+
+	files := MustValue(t, func() (map[string]string, error) {
+		return m.Generate(gengo.GeneratorArgs{
+			Entrypoint:         []string{m.ImportPath("sample")},
+			OutputFileBaseName: "zz_generated_test",
+			Force:              true,
+		}, &tableGen{})
+	})
+
+	Then(
+		t, "应为每个唯一索引生成查询、更新和删除方法，并跳过软删除字段",
+		Expect(files, Be(testingutil.File(
+			"sample/zz_generated_test.table.go",
+			testingutil.Contains(
+				"func (t *tableUser) FindByID(ctx context.Context, ",
+				"func (t *tableUser) FindByName(ctx context.Context, name string",
+				"func (t *tableUser) UpdateByName(ctx context.Context, name string",
+				"func (t *tableUser) DeleteByName(ctx context.Context, name string",
+				"func (t *tableUser) FindByUserIDAndOrgID(ctx context.Context, ",
+				"sqlpipe.Where(t.OrgID, sqlbuilder.Eq(",
+				"sqlpipe.DoDelete[User](),",
+				"ex.FromSource(sqlpipe.From[User]())",
+			),
+			testingutil.NotContains("DeletedAt("),
+		))),
+	)
+}
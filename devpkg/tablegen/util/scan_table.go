@@ -103,6 +103,11 @@ func ScanTable(c gengo.Context, named *types.Named) (sqlbuilder.Table, error) {
 
 	if indexes, ok := tags["def"]; ok {
 		for i := range indexes {
+			if name, expr, ok := sqlbuilder.ParseCheckDefine(indexes[i]); ok {
+				t.(sqlbuilder.CheckCollectionManager).AddCheck(sqlbuilder.CheckConstraint(name, expr))
+				continue
+			}
+
 			def := sqlbuilder.ParseIndexDefine(indexes[i])
 			var key sqlbuilder.Key
 
@@ -110,9 +115,9 @@ func ScanTable(c gengo.Context, named *types.Named) (sqlbuilder.Table, error) {
 			case "primary":
 				key = sqlbuilder.PrimaryKey(nil, sqlbuilder.IndexFieldNameAndOptions(def.FieldNameAndOptions...))
 			case "index":
-				key = sqlbuilder.Index(def.Name, nil, sqlbuilder.IndexUsing(def.Method), sqlbuilder.IndexFieldNameAndOptions(def.FieldNameAndOptions...))
+				key = sqlbuilder.Index(def.Name, nil, sqlbuilder.IndexUsing(def.Method), sqlbuilder.IndexWhere(def.Where), sqlbuilder.IndexFieldNameAndOptions(def.FieldNameAndOptions...))
 			case "unique_index":
-				key = sqlbuilder.UniqueIndex(def.Name, nil, sqlbuilder.IndexUsing(def.Method), sqlbuilder.IndexFieldNameAndOptions(def.FieldNameAndOptions...))
+				key = sqlbuilder.UniqueIndex(def.Name, nil, sqlbuilder.IndexUsing(def.Method), sqlbuilder.IndexWhere(def.Where), sqlbuilder.IndexFieldNameAndOptions(def.FieldNameAndOptions...))
 			}
 
 			if key != nil {
@@ -5,6 +5,8 @@ go 1.26.2
 tool (
 	github.com/octohelm/storage/tool/internal/cmd/fmt
 	github.com/octohelm/storage/tool/internal/cmd/gen
+	github.com/octohelm/storage/tool/internal/cmd/modelgen
+	github.com/octohelm/storage/tool/internal/cmd/schemadrift
 	github.com/octohelm/storage/tool/internal/cmd/skills-install
 )
 
@@ -24,6 +26,7 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/jackc/pgx/v5 v5.9.2
 	golang.org/x/sync v0.20.0
+	gopkg.in/yaml.v3 v3.0.1
 	modernc.org/sqlite v1.50.0
 )
 
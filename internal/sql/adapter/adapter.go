@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"net/url"
+	"strings"
 
 	syncx "github.com/octohelm/x/sync"
 
@@ -47,9 +48,94 @@ type Dialect interface {
 	AddIndex(key sqlbuilder.Key) sqlfrag.Fragment
 	DropIndex(key sqlbuilder.Key) sqlfrag.Fragment
 
+	AddCheck(t sqlbuilder.Table, check sqlbuilder.Check) sqlfrag.Fragment
+	DropCheck(t sqlbuilder.Table, check sqlbuilder.Check) sqlfrag.Fragment
+
 	DataType(columnDef sqlbuilder.ColumnDef) sqlfrag.Fragment
 }
 
+// ConcurrentIndexDialect 由支持在事务外并发建索引的方言实现，返回的语句均不可在事务内执行。
+type ConcurrentIndexDialect interface {
+	AddIndexConcurrently(key sqlbuilder.Key) sqlfrag.Fragment
+	DropIndexConcurrently(key sqlbuilder.Key) sqlfrag.Fragment
+	// RenameIndex 把索引改名为 target 的索引名，用于以临时名建出的新索引替换旧索引
+	RenameIndex(key sqlbuilder.Key, target sqlbuilder.Key) sqlfrag.Fragment
+
+	// InvalidIndexes 查询给定表上构建失败遗留的无效索引，不含其他连接正在构建的索引，结果列为 table_name 与 index_name（不含表名前缀）
+	InvalidIndexes(tableNames ...string) sqlfrag.Fragment
+	// IndexBuildProgress 查询给定表上正在进行的建索引进度，结果列为 phase、blocks_done 与 blocks_total
+	IndexBuildProgress(t sqlbuilder.Table) sqlfrag.Fragment
+}
+
+// OnlineColumnDialect 由支持以影子列在线变更列类型的方言实现。
+// 变更分为扩展（添加影子列并由触发器同步写入）、分批回填与切换三个阶段，col 均为目标列定义。
+type OnlineColumnDialect interface {
+	// ExpandColumn 添加影子列并创建同步触发器，可重复执行
+	ExpandColumn(col sqlbuilder.Column) sqlfrag.Fragment
+	// BackfillColumn 按主键顺序回填 after 之后至多 batchSize 行的影子列，查询结果为本批最后一行的主键，无结果表示已扫描完整表
+	BackfillColumn(col sqlbuilder.Column, batchSize int, after []any) sqlfrag.Fragment
+	// CountColumnBackfill 查询尚未回填的行数，结果列为 remaining
+	CountColumnBackfill(col sqlbuilder.Column) sqlfrag.Fragment
+	// PrepareCutover 在锁表前以 NOT VALID 检查约束加 VALIDATE 证明影子列非空，使切换时 SET NOT NULL 无需在排他锁下扫表；
+	// 返回的语句须在事务外逐条执行，目标列可空时返回空
+	PrepareCutover(col sqlbuilder.Column) []sqlfrag.Fragment
+	// CutoverColumn 删除原列并把影子列改名为原列，须在事务内执行；keys 为已在影子列上以 NextKeyName 预建的索引，切换时改回原名
+	CutoverColumn(col sqlbuilder.Column, keys ...sqlbuilder.Key) sqlfrag.Fragment
+	// DropShadowColumn 放弃未完成的变更，删除影子列及其触发器
+	DropShadowColumn(shadow sqlbuilder.Column) sqlfrag.Fragment
+}
+
+// EnumDialect 由支持原生枚举类型的方言实现。
+type EnumDialect interface {
+	// AddEnumValues 为列的枚举类型追加取值，已存在的取值忽略
+	AddEnumValues(col sqlbuilder.Column, values []string) sqlfrag.Fragment
+}
+
+// UpsertDialect 由支持冲突更新的方言实现，决定 ON CONFLICT 与 MERGE 的渲染方式。
+type UpsertDialect interface {
+	// Excluded 返回冲突更新中引用待插入值的列
+	Excluded(col sqlbuilder.Column) sqlfrag.Fragment
+	// SupportMerge 判断是否支持以 MERGE 渲染插入
+	SupportMerge() bool
+}
+
+// FilterDialect 由需要以专有写法渲染过滤条件的方言实现；未实现时文本匹配使用 lower() 比较，集合列使用 json_each 展开。
+type FilterDialect interface {
+	// ILike 返回不区分大小写的模式匹配条件，not 为 true 时取反，unaccent 为 true 时同时忽略重音；pattern 中的通配符已转义
+	ILike(col sqlbuilder.Column, pattern string, not bool, unaccent bool) sqlfrag.Fragment
+	// JSONArrayContains 返回以 JSON 数组存储的集合列包含全部（all 为 true）或任一给定值的条件，values 已去重
+	JSONArrayContains(col sqlbuilder.Column, values []any, all bool) sqlfrag.Fragment
+}
+
+// ExprNormalizer 由会改写表达式存储形式的适配器实现，如 PostgreSQL 会为表达式补充括号与类型转换。
+type ExprNormalizer interface {
+	// NormalizeTable 返回目标表经数据库改写后的形式，仅用于比较索引表达式、部分索引谓词、CHECK 约束与生成列表达式
+	NormalizeTable(ctx context.Context, t sqlbuilder.Table) (sqlbuilder.Table, error)
+}
+
+// ExprCanonicalizer 由会改写表达式存储形式的方言实现，仅以文本规则把表达式改写为可比较的形式，不访问数据库。
+type ExprCanonicalizer interface {
+	// CanonicalExpr 去掉类型转换、多余括号等存储差异，结果仅用于比较，可能把仅括号位置不同的表达式视为相同
+	CanonicalExpr(expr string) string
+}
+
+const shadowColumnPrefix, shadowColumnSuffix = "__", "_online"
+
+// NextKeyName 返回替换索引期间以临时名预建的索引名。
+func NextKeyName(name string) string {
+	return name + "_next"
+}
+
+// ShadowColumnName 返回列在线变更期间使用的影子列名。
+func ShadowColumnName(name string) string {
+	return shadowColumnPrefix + name + shadowColumnSuffix
+}
+
+// IsShadowColumnName 判断列名是否为在线变更的影子列。
+func IsShadowColumnName(name string) bool {
+	return strings.HasPrefix(name, shadowColumnPrefix) && strings.HasSuffix(name, shadowColumnSuffix)
+}
+
 var adapters = syncx.Map[string, Adapter]{}
 
 // Register 按驱动名及别名注册适配器。
@@ -171,3 +171,56 @@ func TestWrappedDBErrors(t *testing.T) {
 		ExpectDo(func() error { return err }, ErrorMatch(regexp.MustCompile(`^query failed: .*: SELECT f FROM t$`))),
 	)
 }
+
+func TestAfterCommit(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	wrapped := Wrap(sqlDB, func(err error) error { return err })
+
+	fired := make([]string, 0)
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	err = wrapped.Transaction(context.Background(), func(ctx context.Context) error {
+		return wrapped.Transaction(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func(ctx context.Context) {
+				fired = append(fired, "nested")
+			})
+
+			Then(
+				t, "事务提交前不执行回调",
+				Expect(len(fired), Equal(0)),
+			)
+			return nil
+		})
+	})
+
+	Then(
+		t, "最外层事务提交后执行嵌套事务中注册的回调",
+		Expect(err, Equal(error(nil))),
+		Expect(fired, Equal([]string{"nested"})),
+	)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	_ = wrapped.Transaction(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, func(ctx context.Context) {
+			fired = append(fired, "rollback")
+		})
+		return fmt.Errorf("action failed")
+	})
+
+	AfterCommit(context.Background(), func(ctx context.Context) {
+		fired = append(fired, "no tx")
+	})
+
+	Then(
+		t, "回滚时不执行回调，不在事务中时立即执行",
+		Expect(fired, Equal([]string{"nested", "no tx"})),
+		ExpectDo(mock.ExpectationsWereMet),
+	)
+}
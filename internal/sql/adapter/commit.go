@@ -0,0 +1,40 @@
+package adapter
+
+import (
+	"context"
+	"sync"
+)
+
+type afterCommitContext struct{}
+
+type afterCommits struct {
+	mu  sync.Mutex
+	fns []func(ctx context.Context)
+}
+
+func (c *afterCommits) add(fn func(ctx context.Context)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fns = append(c.fns, fn)
+}
+
+func (c *afterCommits) run(ctx context.Context) {
+	c.mu.Lock()
+	fns := c.fns
+	c.fns = nil
+	c.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(ctx)
+	}
+}
+
+// AfterCommit 注册在最外层事务提交成功后执行的回调；不在事务中时立即执行。
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if c, ok := ctx.Value(afterCommitContext{}).(*afterCommits); ok {
+		c.add(fn)
+		return
+	}
+	fn(ctx)
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"runtime"
 
+	contextx "github.com/octohelm/x/context"
+
 	"github.com/octohelm/storage/pkg/sqlfrag"
 )
 
@@ -39,6 +41,7 @@ func (d *db) Exec(ctx context.Context, frag sqlfrag.Fragment) (sql.Result, error
 		if err != nil {
 			return nil, fmt.Errorf("exec failed: %w: %s", d.convertErr(err), query)
 		}
+		observeWrite(ctx, frag)
 		return result, nil
 	}
 
@@ -46,6 +49,7 @@ func (d *db) Exec(ctx context.Context, frag sqlfrag.Fragment) (sql.Result, error
 	if err != nil {
 		return nil, fmt.Errorf("exec failed: %w: %s", d.convertErr(err), query)
 	}
+	observeWrite(ctx, frag)
 	return result, nil
 }
 
@@ -60,6 +64,7 @@ func (d *db) Query(ctx context.Context, frag sqlfrag.Fragment) (*sql.Rows, error
 		if err != nil {
 			return nil, fmt.Errorf("query failed: %w: %s", err, query)
 		}
+		observeWrite(ctx, frag)
 		return rows, err
 	}
 
@@ -67,6 +72,7 @@ func (d *db) Query(ctx context.Context, frag sqlfrag.Fragment) (*sql.Rows, error
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w: %s", err, query)
 	}
+	observeWrite(ctx, frag)
 	return rows, err
 }
 
@@ -80,6 +86,8 @@ func (d *db) Transaction(ctx context.Context, action func(ctx context.Context) e
 		}
 	}
 
+	var commits *afterCommits
+
 	if txn == nil {
 		tx, err := d.BeginTx(ctx, nil)
 		if err != nil {
@@ -87,6 +95,7 @@ func (d *db) Transaction(ctx context.Context, action func(ctx context.Context) e
 		}
 		inScopeOfTxnCreated = true
 		txn = tx
+		commits = &afterCommits{}
 	}
 
 	defer func() {
@@ -107,9 +116,16 @@ func (d *db) Transaction(ctx context.Context, action func(ctx context.Context) e
 				_ = txn.Rollback()
 			} else {
 				err = txn.Commit()
+				if err == nil {
+					commits.run(ctx)
+				}
 			}
 		}
 	}()
 
+	if commits != nil {
+		return action(ContextWithSqlDo(contextx.WithValue(ctx, afterCommitContext{}, commits), txn))
+	}
+
 	return action(ContextWithSqlDo(ctx, txn))
 }
@@ -0,0 +1,136 @@
+package adapter
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+)
+
+// TrimParens 去掉 SQL 表达式最外层成对的括号。
+func TrimParens(expr string) string {
+	expr = strings.TrimSpace(expr)
+
+	for len(expr) > 1 && expr[0] == '(' && expr[len(expr)-1] == ')' {
+		depth := 0
+
+		for i := range len(expr) - 1 {
+			switch expr[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+
+			// outer parens closed before the end, like (a) AND (b)
+			if depth == 0 {
+				return expr
+			}
+		}
+
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+
+	return expr
+}
+
+// ExprEqual 忽略空白、外层括号与大小写比较两个 SQL 表达式。
+func ExprEqual(a string, b string) bool {
+	return strings.EqualFold(compactExpr(a), compactExpr(b))
+}
+
+func compactExpr(expr string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, TrimParens(expr))
+}
+
+// CutIndexDef 解析 catalog 中形如 (f_a, lower(f_b) DESC) WHERE (f_c = 0) 的索引定义，返回索引项与部分索引谓词。
+func CutIndexDef(def string) (fieldNameAndOptions []sqlbuilder.FieldNameAndOption, where string) {
+	start := strings.Index(def, "(")
+	if start == -1 {
+		return nil, ""
+	}
+
+	end := closingParen(def, start)
+	if end == -1 {
+		return nil, ""
+	}
+
+	for _, item := range splitTopLevel(def[start+1:end], ',') {
+		fieldNameAndOptions = append(fieldNameAndOptions, toFieldNameAndOption(item))
+	}
+
+	if w, ok := strings.CutPrefix(strings.TrimSpace(def[end+1:]), "WHERE "); ok {
+		where = TrimParens(w)
+	}
+
+	return fieldNameAndOptions, where
+}
+
+// toFieldNameAndOption 转换单个索引项，表达式统一包裹一层括号
+func toFieldNameAndOption(item string) sqlbuilder.FieldNameAndOption {
+	name := item
+	options := ""
+
+	if i := strings.LastIndex(item, ")"); i != -1 {
+		name = "(" + TrimParens(item[0:i+1]) + ")"
+		options = item[i+1:]
+	} else if i := strings.Index(item, " "); i != -1 {
+		name = item[0:i]
+		options = item[i+1:]
+	}
+
+	if fields := strings.Fields(options); len(fields) > 0 {
+		return sqlbuilder.FieldNameAndOption(name + "," + strings.Join(fields, ","))
+	}
+
+	return sqlbuilder.FieldNameAndOption(name)
+}
+
+func closingParen(s string, start int) int {
+	depth := 0
+
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func splitTopLevel(s string, sep byte) []string {
+	parts := make([]string, 0)
+	depth := 0
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+
+	return parts
+}
@@ -0,0 +1,38 @@
+package adapter
+
+import (
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+)
+
+func TestExprEqual(t *testing.T) {
+	Then(
+		t, "TrimParens 只去掉成对包裹整个表达式的括号",
+		Expect(TrimParens("((f_age >= 0))"), Equal("f_age >= 0")),
+		Expect(TrimParens("(f_a > 0) AND (f_b > 0)"), Equal("(f_a > 0) AND (f_b > 0)")),
+	)
+
+	Then(
+		t, "ExprEqual 忽略空白、外层括号与大小写",
+		Expect(ExprEqual("(f_price > = 0)", "f_price>=0"), Equal(true)),
+		Expect(ExprEqual("f_a in (1, 2)", "F_A IN (1,2)"), Equal(true)),
+		Expect(ExprEqual("f_a > 1", "f_a > 2"), Equal(false)),
+	)
+}
+
+func TestCutIndexDef(t *testing.T) {
+	fieldNameAndOptions, where := CutIndexDef("t_user (f_name, lower((f_nickname)::text) DESC, ((f_a + f_b))) WHERE (f_deleted_at = 0)")
+
+	Then(
+		t, "CutIndexDef 解析列、表达式与部分索引谓词",
+		Expect(fieldNameAndOptions, Equal([]sqlbuilder.FieldNameAndOption{
+			"f_name",
+			"(lower((f_nickname)::text)),DESC",
+			"(f_a + f_b)",
+		})),
+		Expect(where, Equal("f_deleted_at = 0")),
+	)
+}
@@ -1,12 +1,11 @@
 package postgres
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
-	textscanner "text/scanner"
 
 	"github.com/octohelm/storage/internal/sql/adapter"
 	"github.com/octohelm/storage/internal/sql/scanner"
@@ -15,18 +14,57 @@ import (
 )
 
 func (a *pgAdapter) Catalog(ctx context.Context) (*sqlbuilder.Tables, error) {
-	return catalog(ctx, a, a.dbName)
+	return catalog(ctx, a, "public")
+}
+
+var errNormalized = errors.New("normalized")
+
+// NormalizeTable 在回滚的事务中把 t 建为同名临时表再从 catalog 读回，得到 PostgreSQL 改写后的表达式。
+func (a *pgAdapter) NormalizeTable(ctx context.Context, t sqlbuilder.Table) (normalized sqlbuilder.Table, err error) {
+	err = a.Transaction(ctx, func(ctx context.Context) error {
+		// unqualified objects are created in pg_temp first
+		if _, err := a.Exec(ctx, sqlfrag.Const("SET LOCAL search_path TO pg_temp, public;")); err != nil {
+			return err
+		}
+
+		for _, frag := range a.dialect.CreateTableIsNotExists(t) {
+			if _, err := a.Exec(ctx, frag); err != nil {
+				return err
+			}
+		}
+
+		rows, err := a.Query(ctx, sqlfrag.Const("SELECT pg_my_temp_schema()::regnamespace::text;"))
+		if err != nil {
+			return err
+		}
+
+		tempSchema := ""
+		if err := scanner.Scan(ctx, rows, &tempSchema); err != nil {
+			return err
+		}
+
+		cat, err := catalog(ctx, a, tempSchema)
+		if err != nil {
+			return err
+		}
+
+		normalized = cat.Table(t.TableName())
+
+		return errNormalized
+	})
+	if errors.Is(err, errNormalized) {
+		return normalized, nil
+	}
+	return nil, err
 }
 
 var reUsing = regexp.MustCompile(`USING ([^ ]+)`)
 
-func catalog(ctx context.Context, a adapter.Adapter, dbName string) (*sqlbuilder.Tables, error) {
+func catalog(ctx context.Context, a adapter.Adapter, tableSchema string) (*sqlbuilder.Tables, error) {
 	cat := &sqlbuilder.Tables{}
 
 	tableColumnSchema := sqlbuilder.TableFromModel(&columnSchema{})
 
-	tableSchema := "public"
-
 	stmt := sqlbuilder.Select(sqlbuilder.ColumnCollect(tableColumnSchema.Cols())).From(
 		tableColumnSchema,
 		sqlbuilder.Where(
@@ -47,6 +85,11 @@ func catalog(ctx context.Context, a adapter.Adapter, dbName string) (*sqlbuilder
 		return nil, err
 	}
 
+	enums, err := queryEnums(ctx, a, tableSchema)
+	if err != nil {
+		return nil, err
+	}
+
 	for i := range colSchemaList {
 		colSchema := colSchemaList[i]
 
@@ -56,7 +99,7 @@ func catalog(ctx context.Context, a adapter.Adapter, dbName string) (*sqlbuilder
 			cat.Add(table)
 		}
 
-		table.(sqlbuilder.ColumnCollectionManger).AddCol(colSchema.ToColumn())
+		table.(sqlbuilder.ColumnCollectionManger).AddCol(colSchema.ToColumn(enums))
 	}
 
 	if cols := sqlbuilder.ColumnCollect(tableColumnSchema.Cols()); cols.Len() != 0 {
@@ -109,6 +152,11 @@ ORDER BY conrelid::regclass::text, contype DESC;
 		for _, idxSchema := range indexList {
 			t := cat.Table(idxSchema.TABLE_NAME)
 
+			if check := idxSchema.ToCheck(t); check != nil {
+				t.(sqlbuilder.CheckCollectionManager).AddCheck(check)
+				continue
+			}
+
 			key := idxSchema.ToKey(t)
 			if key == nil {
 				continue
@@ -121,23 +169,56 @@ ORDER BY conrelid::regclass::text, contype DESC;
 	return cat, nil
 }
 
+type enumSchema struct {
+	ENUM_NAME  string `db:"enum_name"`
+	ENUM_LABEL string `db:"enum_label"`
+}
+
+// queryEnums 查询 schema 下全部枚举类型及按声明顺序排列的取值。
+func queryEnums(ctx context.Context, a adapter.Adapter, tableSchema string) (map[string][]string, error) {
+	rows, err := a.Query(ctx, sqlfrag.Pair(`
+SELECT t.typname AS enum_name, e.enumlabel AS enum_label
+FROM pg_type t
+JOIN pg_enum e ON e.enumtypid = t.oid
+WHERE t.typnamespace = ?::regnamespace
+ORDER BY t.typname, e.enumsortorder;
+`, tableSchema))
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]enumSchema, 0)
+	if err := scanner.Scan(ctx, rows, &list); err != nil {
+		return nil, err
+	}
+
+	enums := map[string][]string{}
+	for _, e := range list {
+		enums[e.ENUM_NAME] = append(enums[e.ENUM_NAME], e.ENUM_LABEL)
+	}
+	return enums, nil
+}
+
 type columnSchema struct {
 	TABLE_SCHEMA             string `db:"table_schema"`
 	TABLE_NAME               string `db:"table_name"`
 	COLUMN_NAME              string `db:"column_name"`
 	DATA_TYPE                string `db:"data_type"`
+	UDT_NAME                 string `db:"udt_name"`
 	IS_NULLABLE              string `db:"is_nullable"`
 	COLUMN_DEFAULT           string `db:"column_default"`
 	CHARACTER_MAXIMUM_LENGTH uint64 `db:"character_maximum_length"`
 	NUMERIC_PRECISION        uint64 `db:"numeric_precision"`
 	NUMERIC_SCALE            uint64 `db:"numeric_scale"`
+	IS_GENERATED             string `db:"is_generated"`
+	GENERATION_EXPRESSION    string `db:"generation_expression"`
 }
 
 func (columnSchema) TableName() string {
 	return "information_schema.columns"
 }
 
-func (columnSchema *columnSchema) ToColumn() sqlbuilder.Column {
+func (columnSchema *columnSchema) ToColumn(enums map[string][]string) sqlbuilder.Column {
 	defaultValue := columnSchema.COLUMN_DEFAULT
 	def := sqlbuilder.ColumnDef{}
 
@@ -162,6 +243,18 @@ func (columnSchema *columnSchema) ToColumn() sqlbuilder.Column {
 		}
 	}
 
+	// enum or extension types
+	if dataType == "USER-DEFINED" {
+		dataType = columnSchema.UDT_NAME
+
+		if values, ok := enums[dataType]; ok {
+			def.Enum = &sqlbuilder.ColumnEnum{
+				Name:   dataType,
+				Values: values,
+			}
+		}
+	}
+
 	def.DataType = dataType
 
 	// numeric type
@@ -178,6 +271,13 @@ func (columnSchema *columnSchema) ToColumn() sqlbuilder.Column {
 		def.Null = true
 	}
 
+	if columnSchema.IS_GENERATED == "ALWAYS" {
+		def.Generated = &sqlbuilder.ColumnGenerated{
+			Expr:   adapter.TrimParens(columnSchema.GENERATION_EXPRESSION),
+			Stored: true,
+		}
+	}
+
 	return sqlbuilder.Col(columnSchema.COLUMN_NAME, sqlbuilder.ColDef(def))
 }
 
@@ -192,6 +292,19 @@ func (indexSchema) TableName() string {
 	return "pg_indexes"
 }
 
+func (idxSchema *indexSchema) ToCheck(table sqlbuilder.Table) sqlbuilder.Check {
+	// CHECK ((f_age >= 0))
+	expr, ok := strings.CutPrefix(idxSchema.INDEX_DEF, "CHECK ")
+	if !ok {
+		return nil
+	}
+
+	expr = strings.TrimSuffix(expr, " NOT VALID")
+	name := strings.TrimPrefix(strings.ToLower(idxSchema.INDEX_NAME), table.TableName()+"_")
+
+	return sqlbuilder.CheckConstraint(name, adapter.TrimParens(expr))
+}
+
 func (idxSchema *indexSchema) ToKey(table sqlbuilder.Table) sqlbuilder.Key {
 	// ignore pg 18 not_null indexes
 	// https://www.enterprisedb.com/blog/changes-not-null-postgres-18?utm_source=chatgpt.com
@@ -218,33 +331,11 @@ func (idxSchema *indexSchema) ToKey(table sqlbuilder.Table) sqlbuilder.Key {
 		colParts = strings.TrimSpace(reUsing.Split(idxSchema.INDEX_DEF, 2)[1])
 	}
 
-	colNameAndOptions := make([]sqlbuilder.FieldNameAndOption, 0)
-
-	s := &textscanner.Scanner{}
-	s.Init(bytes.NewBufferString(colParts))
-
-	parts := make([]string, 0)
-
-	for t := s.Scan(); t != textscanner.EOF; t = s.Scan() {
-		part := s.TokenText()
+	// (f_a, lower((f_b)::text) DESC) WHERE (f_deleted_at = 0)
+	colNameAndOptions, where := adapter.CutIndexDef(colParts)
 
-		switch part {
-		case "(":
-			continue
-		case ",", ")":
-			colNameAndOption := parts[0]
-			if len(parts) > 1 {
-				colNameAndOption += "," + strings.ReplaceAll(parts[1], " ", ",")
-			}
-			colNameAndOptions = append(colNameAndOptions, sqlbuilder.FieldNameAndOption(colNameAndOption))
-			// reset
-			parts = make([]string, 0)
-			continue
-		}
-		parts = append(parts, part)
-	}
 	if isUnique {
-		return sqlbuilder.UniqueIndex(name, nil, sqlbuilder.IndexUsing(method), sqlbuilder.IndexFieldNameAndOptions(colNameAndOptions...))
+		return sqlbuilder.UniqueIndex(name, nil, sqlbuilder.IndexUsing(method), sqlbuilder.IndexWhere(where), sqlbuilder.IndexFieldNameAndOptions(colNameAndOptions...))
 	}
-	return sqlbuilder.Index(name, nil, sqlbuilder.IndexUsing(method), sqlbuilder.IndexFieldNameAndOptions(colNameAndOptions...))
+	return sqlbuilder.Index(name, nil, sqlbuilder.IndexUsing(method), sqlbuilder.IndexWhere(where), sqlbuilder.IndexFieldNameAndOptions(colNameAndOptions...))
 }
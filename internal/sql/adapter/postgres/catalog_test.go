@@ -3,6 +3,8 @@ package postgres
 import (
 	"testing"
 
+	testingx "github.com/octohelm/x/testing"
+
 	"github.com/octohelm/storage/pkg/sqlbuilder"
 )
 
@@ -15,3 +17,34 @@ func TestIndexSchema_ToKey(t *testing.T) {
 	}
 	_ = i.ToKey(sqlbuilder.T("t_kubepkg_version"))
 }
+
+func TestIndexSchema_ToCheck(t *testing.T) {
+	i := indexSchema{
+		TABLE_SCHEMA: "public",
+		TABLE_NAME:   "t_user",
+		INDEX_NAME:   "t_user_ck_age",
+		INDEX_DEF:    "CHECK ((f_age >= 0))",
+	}
+
+	check := i.ToCheck(sqlbuilder.T("t_user"))
+
+	testingx.Expect(t, check.Name(), testingx.Be("ck_age"))
+	testingx.Expect(t, check.Expr(), testingx.Be("f_age >= 0"))
+}
+
+func TestIndexSchema_ToKeyWithExprAndWhere(t *testing.T) {
+	i := indexSchema{
+		TABLE_SCHEMA: "public",
+		TABLE_NAME:   "t_user",
+		INDEX_NAME:   "t_user_i_name",
+		INDEX_DEF:    "CREATE UNIQUE INDEX t_user_i_name ON public.t_user USING btree (lower((f_name)::text) DESC, f_org_id) WHERE (f_deleted_at = 0)",
+	}
+
+	keyDef := sqlbuilder.GetKeyDef(i.ToKey(sqlbuilder.T("t_user")))
+
+	testingx.Expect(t, keyDef.FieldNameAndOptions(), testingx.Equal([]sqlbuilder.FieldNameAndOption{
+		"(lower((f_name)::text)),DESC",
+		"f_org_id",
+	}))
+	testingx.Expect(t, keyDef.Where(), testingx.Be("f_deleted_at = 0"))
+}
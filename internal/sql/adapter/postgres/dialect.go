@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"iter"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/go-json-experiment/json"
 	typex "github.com/octohelm/x/types"
 
 	"github.com/octohelm/storage/internal/sql/adapter"
@@ -16,7 +18,15 @@ import (
 	"github.com/octohelm/storage/pkg/sqlfrag"
 )
 
-var _ adapter.Dialect = (*dialect)(nil)
+var (
+	_ adapter.Dialect                = (*dialect)(nil)
+	_ adapter.ConcurrentIndexDialect = (*dialect)(nil)
+	_ adapter.OnlineColumnDialect    = (*dialect)(nil)
+	_ adapter.EnumDialect            = (*dialect)(nil)
+	_ adapter.UpsertDialect          = (*dialect)(nil)
+	_ adapter.FilterDialect          = (*dialect)(nil)
+	_ adapter.ExprCanonicalizer      = (*dialect)(nil)
+)
 
 type dialect struct{}
 
@@ -24,6 +34,45 @@ func (dialect) DriverName() string {
 	return "postgres"
 }
 
+func (dialect) Excluded(col sqlbuilder.Column) sqlfrag.Fragment {
+	return col.Fragment("EXCLUDED.?", sqlfrag.Const(col.Name()))
+}
+
+// SupportMerge MERGE 需要 PostgreSQL 15+，带 RETURNING 时需要 17+
+func (dialect) SupportMerge() bool {
+	return true
+}
+
+// ILike unaccent 需启用 unaccent 扩展
+func (dialect) ILike(col sqlbuilder.Column, pattern string, not bool, unaccent bool) sqlfrag.Fragment {
+	op := "ILIKE"
+	if not {
+		op = "NOT ILIKE"
+	}
+	if unaccent {
+		return col.Fragment("unaccent(#) "+op+" unaccent(?)", pattern)
+	}
+	return col.Fragment("# "+op+" ?", pattern)
+}
+
+// JSONArrayContains 转为 jsonb 后使用 @> 与 ANY
+func (dialect) JSONArrayContains(col sqlbuilder.Column, values []any, all bool) sqlfrag.Fragment {
+	if all {
+		return col.Fragment("CAST(# AS jsonb) @> CAST(? AS jsonb)", jsonArrayOf(values...))
+	}
+
+	elements := make([]sqlfrag.Fragment, 0, len(values))
+	for _, v := range values {
+		elements = append(elements, sqlfrag.Pair("CAST(? AS jsonb)", jsonArrayOf(v)))
+	}
+	return col.Fragment("CAST(# AS jsonb) @> ANY (ARRAY[?])", sqlfrag.JoinValues(",", elements...))
+}
+
+func jsonArrayOf(values ...any) string {
+	raw, _ := json.Marshal(values)
+	return string(raw)
+}
+
 func (c *dialect) indexName(key sqlbuilder.Key) sqlfrag.Fragment {
 	name := key.Name()
 	if name == "primary" {
@@ -33,19 +82,32 @@ func (c *dialect) indexName(key sqlbuilder.Key) sqlfrag.Fragment {
 }
 
 func (c *dialect) AddIndex(key sqlbuilder.Key) sqlfrag.Fragment {
+	return c.addIndex(key, false)
+}
+
+func (c *dialect) AddIndexConcurrently(key sqlbuilder.Key) sqlfrag.Fragment {
+	return c.addIndex(key, true)
+}
+
+func (c *dialect) addIndex(key sqlbuilder.Key, concurrently bool) sqlfrag.Fragment {
 	if key.IsPrimary() {
 		return sqlfrag.Pair("\nALTER TABLE ? ADD PRIMARY KEY (?);", sqlbuilder.GetKeyTable(key), sqlbuilder.ColumnCollect(key.Cols()))
 	}
 
 	keyDef := key.(sqlbuilder.KeyDef)
 
-	return sqlfrag.Pair("\nCREATE @index_type @index_name ON @table @index_method (@columnAndOptions);", sqlfrag.NamedArgSet{
+	return sqlfrag.Pair("\nCREATE @index_type @index_name ON @table @index_method (@columnAndOptions)@where;", sqlfrag.NamedArgSet{
 		"table": sqlbuilder.GetKeyTable(key),
 		"index_type": func() sqlfrag.Fragment {
+			indexType := "INDEX"
 			if key.IsUnique() {
-				return sqlfrag.Const("UNIQUE INDEX")
+				indexType = "UNIQUE INDEX"
 			}
-			return sqlfrag.Const("INDEX")
+			if concurrently {
+				// IF NOT EXISTS makes retry safe after invalid index dropped
+				indexType += " CONCURRENTLY IF NOT EXISTS"
+			}
+			return sqlfrag.Const(indexType)
 		}(),
 		"index_name": c.indexName(key),
 		"index_method": func() sqlfrag.Fragment {
@@ -58,9 +120,17 @@ func (c *dialect) AddIndex(key sqlbuilder.Key) sqlfrag.Fragment {
 			return sqlfrag.Empty()
 		}(),
 		"columnAndOptions": sqlbuilder.AsKeyColumnsTableDef(key),
+		"where":            c.indexWhere(key),
 	})
 }
 
+func (c *dialect) indexWhere(key sqlbuilder.Key) sqlfrag.Fragment {
+	if keyDef := sqlbuilder.GetKeyDef(key); keyDef != nil && keyDef.Where() != "" {
+		return sqlfrag.Const(" WHERE " + adapter.TrimParens(keyDef.Where()))
+	}
+	return sqlfrag.Empty()
+}
+
 func (c *dialect) DropIndex(key sqlbuilder.Key) sqlfrag.Fragment {
 	if key.IsPrimary() {
 		return sqlfrag.Pair("\nALTER TABLE ? DROP CONSTRAINT ?;", sqlbuilder.GetKeyTable(key), c.indexName(key))
@@ -68,7 +138,66 @@ func (c *dialect) DropIndex(key sqlbuilder.Key) sqlfrag.Fragment {
 	return sqlfrag.Pair("\nDROP INDEX IF EXISTS ?;", c.indexName(key))
 }
 
+func (c *dialect) DropIndexConcurrently(key sqlbuilder.Key) sqlfrag.Fragment {
+	if key.IsPrimary() {
+		return c.DropIndex(key)
+	}
+	return sqlfrag.Pair("\nDROP INDEX CONCURRENTLY IF EXISTS ?;", c.indexName(key))
+}
+
+func (c *dialect) RenameIndex(key sqlbuilder.Key, target sqlbuilder.Key) sqlfrag.Fragment {
+	return sqlfrag.Pair("\nALTER INDEX ? RENAME TO ?;", c.indexName(key), c.indexName(target))
+}
+
+func (c *dialect) InvalidIndexes(tableNames ...string) sqlfrag.Fragment {
+	return sqlfrag.Pair(`
+SELECT t.relname                                  AS table_name,
+       substr(i.relname, length(t.relname) + 2) AS index_name
+FROM pg_index x
+         JOIN pg_class i ON i.oid = x.indexrelid
+         JOIN pg_class t ON t.oid = x.indrelid
+WHERE t.relnamespace = 'public'::regnamespace
+  AND NOT x.indisvalid
+  AND t.relname IN (?)
+  AND left(i.relname, length(t.relname) + 1) = t.relname || '_'
+  -- skip indexes still being built concurrently by other sessions
+  AND NOT EXISTS (SELECT 1 FROM pg_stat_progress_create_index p WHERE p.index_relid = i.oid)
+ORDER BY t.relname, i.relname;
+`, tableNames)
+}
+
+func (c *dialect) IndexBuildProgress(t sqlbuilder.Table) sqlfrag.Fragment {
+	return sqlfrag.Pair(`
+SELECT phase, blocks_done, blocks_total
+FROM pg_stat_progress_create_index
+WHERE relid = ?::regclass;
+`, t.TableName())
+}
+
+func (c *dialect) checkName(t sqlbuilder.Table, check sqlbuilder.Check) sqlfrag.Fragment {
+	return sqlfrag.Const(t.TableName() + "_" + check.Name())
+}
+
+func (c *dialect) AddCheck(t sqlbuilder.Table, check sqlbuilder.Check) sqlfrag.Fragment {
+	return sqlfrag.Pair("\nALTER TABLE ? ADD CONSTRAINT ? CHECK ?;", t, c.checkName(t, check), sqlfrag.Const(wrapParens(check.Expr())))
+}
+
+func (c *dialect) DropCheck(t sqlbuilder.Table, check sqlbuilder.Check) sqlfrag.Fragment {
+	return sqlfrag.Pair("\nALTER TABLE ? DROP CONSTRAINT IF EXISTS ?;", t, c.checkName(t, check))
+}
+
 func (c *dialect) CreateTableIsNotExists(t sqlbuilder.Table) (exprs []sqlfrag.Fragment) {
+	enumNames := map[string]bool{}
+
+	for col := range t.Cols() {
+		def := sqlbuilder.GetColumnDef(col)
+		if def.DeprecatedActions != nil || def.Enum == nil || enumNames[def.Enum.Name] {
+			continue
+		}
+		enumNames[def.Enum.Name] = true
+		exprs = append(exprs, c.createEnumType(def.Enum))
+	}
+
 	exprs = append(exprs, sqlfrag.Pair("\nCREATE TABLE IF NOT EXISTS @table (@def\n);", sqlfrag.NamedArgSet{
 		"table": t,
 		"def": sqlfrag.Func(func(ctx context.Context) iter.Seq2[string, []any] {
@@ -121,6 +250,14 @@ func (c *dialect) CreateTableIsNotExists(t sqlbuilder.Table) (exprs []sqlfrag.Fr
 						}
 					}
 				}
+
+				for check := range sqlbuilder.GetTableChecks(t) {
+					for q, args := range sqlfrag.Pair(",\n\tCONSTRAINT ? CHECK ?", c.checkName(t, check), sqlfrag.Const(wrapParens(check.Expr()))).Frag(ctx) {
+						if !yield(q, args) {
+							return
+						}
+					}
+				}
 			}
 		}),
 	}))
@@ -147,13 +284,56 @@ func (c *dialect) TruncateTable(t sqlbuilder.Table) sqlfrag.Fragment {
 }
 
 func (c *dialect) AddColumn(col sqlbuilder.Column) sqlfrag.Fragment {
-	return sqlfrag.Pair("\nALTER TABLE @table ADD COLUMN @col @dataType;", sqlfrag.NamedArgSet{
+	def := sqlbuilder.GetColumnDef(col)
+
+	addColumn := sqlfrag.Pair("\nALTER TABLE @table ADD COLUMN @col @dataType;", sqlfrag.NamedArgSet{
 		"table":    sqlbuilder.GetColumnTable(col),
 		"col":      col,
-		"dataType": c.DataType(sqlbuilder.GetColumnDef(col)),
+		"dataType": c.DataType(def),
+	})
+
+	if def.Enum != nil {
+		return sqlfrag.JoinValues("", c.createEnumType(def.Enum), addColumn)
+	}
+
+	return addColumn
+}
+
+// createEnumType 创建枚举类型；同名类型已存在且含有未声明的取值时报错，避免复用其他枚举的同名类型。
+func (c *dialect) createEnumType(e *sqlbuilder.ColumnEnum) sqlfrag.Fragment {
+	labels := make([]string, 0, len(e.Values))
+	for _, v := range e.Values {
+		labels = append(labels, quoteLiteral(v))
+	}
+
+	return sqlfrag.Pair(`
+DO $$ BEGIN
+	CREATE TYPE @enum AS ENUM (@labels);
+EXCEPTION WHEN duplicate_object THEN
+	IF EXISTS (SELECT 1 FROM pg_enum WHERE enumtypid = @enumName::regtype AND NOT enumlabel = ANY (ARRAY[@labels])) THEN
+		RAISE EXCEPTION 'enum type % exists with undeclared labels', @enumName;
+	END IF;
+END $$;`, sqlfrag.NamedArgSet{
+		"enum":     sqlfrag.Const(e.Name),
+		"enumName": sqlfrag.Const(quoteLiteral(e.Name)),
+		"labels":   sqlfrag.Const(strings.Join(labels, ", ")),
 	})
 }
 
+func (c *dialect) AddEnumValues(col sqlbuilder.Column, values []string) sqlfrag.Fragment {
+	def := sqlbuilder.GetColumnDef(col)
+	if def.Enum == nil || len(values) == 0 {
+		return nil
+	}
+
+	stmts := make([]sqlfrag.Fragment, 0, len(values))
+	for _, v := range values {
+		stmts = append(stmts, sqlfrag.Pair("\nALTER TYPE ? ADD VALUE IF NOT EXISTS ?;", sqlfrag.Const(def.Enum.Name), sqlfrag.Const(quoteLiteral(v))))
+	}
+
+	return sqlfrag.JoinValues("", stmts...)
+}
+
 func (c *dialect) RenameColumn(col sqlbuilder.Column, target sqlbuilder.Column) sqlfrag.Fragment {
 	return sqlfrag.Pair("\nALTER TABLE @table RENAME COLUMN @oldCol TO @newCol;", sqlfrag.NamedArgSet{
 		"table":  sqlbuilder.GetColumnTable(col),
@@ -170,16 +350,35 @@ func (c *dialect) ModifyColumn(col sqlbuilder.Column, prev sqlbuilder.Column) sq
 		return nil
 	}
 
+	// generated column could not be altered, only to recreate
+	if def.Generated != nil || prevDef.Generated != nil {
+		return sqlfrag.JoinValues("", c.DropColumn(prev), c.AddColumn(col))
+	}
+
 	dbDataType := c.dataType(def.Type, def)
 	prevDbDataType := c.dataType(prevDef.Type, prevDef)
 
 	actions := make([]sqlfrag.Fragment, 0)
+	defaultDropped := false
 
 	if dbDataType != prevDbDataType {
-		actions = append(actions, sqlfrag.Pair(
-			"ALTER COLUMN ? TYPE ? /* FROM ? */",
-			col, sqlfrag.Const(dbDataType), sqlfrag.Const(prevDbDataType),
-		))
+		if def.Enum != nil || prevDef.Enum != nil {
+			// default value could not be cast between enum and other types automatically
+			if prevDef.Default != nil {
+				actions = append(actions, sqlfrag.Pair("ALTER COLUMN ? DROP DEFAULT", col))
+				defaultDropped = true
+			}
+
+			actions = append(actions, sqlfrag.Pair(
+				"ALTER COLUMN ? TYPE ? USING (?::text)::? /* FROM ? */",
+				col, sqlfrag.Const(dbDataType), col, sqlfrag.Const(dbDataType), sqlfrag.Const(prevDbDataType),
+			))
+		} else {
+			actions = append(actions, sqlfrag.Pair(
+				"ALTER COLUMN ? TYPE ? /* FROM ? */",
+				col, sqlfrag.Const(dbDataType), sqlfrag.Const(prevDbDataType),
+			))
+		}
 	}
 
 	if def.Null != prevDef.Null {
@@ -200,7 +399,7 @@ func (c *dialect) ModifyColumn(col sqlbuilder.Column, prev sqlbuilder.Column) sq
 	if defaultValue != prevDefaultValue {
 		if def.Default != nil {
 			actions = append(actions, sqlfrag.Pair("ALTER COLUMN ? SET DEFAULT ? /* FROM ? */", col, sqlfrag.Const(defaultValue), sqlfrag.Const(prevDefaultValue)))
-		} else {
+		} else if !defaultDropped {
 			actions = append(actions, sqlfrag.Pair("ALTER COLUMN ? DROP DEFAULT", col))
 		}
 	}
@@ -209,10 +408,16 @@ func (c *dialect) ModifyColumn(col sqlbuilder.Column, prev sqlbuilder.Column) sq
 		return nil
 	}
 
-	return sqlfrag.Pair("\nALTER TABLE @table @actions;", sqlfrag.NamedArgSet{
+	alterTable := sqlfrag.Pair("\nALTER TABLE @table @actions;", sqlfrag.NamedArgSet{
 		"table":   sqlbuilder.GetColumnTable(col),
 		"actions": sqlfrag.JoinValues(", ", actions...),
 	})
+
+	if def.Enum != nil && dbDataType != prevDbDataType {
+		return sqlfrag.JoinValues("", c.createEnumType(def.Enum), alterTable)
+	}
+
+	return alterTable
 }
 
 func (c *dialect) DropColumn(col sqlbuilder.Column) sqlfrag.Fragment {
@@ -222,6 +427,171 @@ func (c *dialect) DropColumn(col sqlbuilder.Column) sqlfrag.Fragment {
 	})
 }
 
+func (c *dialect) shadowSyncName(t sqlbuilder.Table, shadowName string) sqlfrag.Fragment {
+	return sqlfrag.Const(t.TableName() + "_" + strings.TrimPrefix(shadowName, "__") + "_sync")
+}
+
+func (c *dialect) shadowCheckName(t sqlbuilder.Table, shadowName string) sqlfrag.Fragment {
+	return sqlfrag.Const(t.TableName() + "_" + strings.TrimPrefix(shadowName, "__") + "_not_null")
+}
+
+func (c *dialect) onlineColumnArgs(col sqlbuilder.Column) sqlfrag.NamedArgSet {
+	def := sqlbuilder.GetColumnDef(col)
+	t := sqlbuilder.GetColumnTable(col)
+	shadowName := adapter.ShadowColumnName(col.Name())
+
+	return sqlfrag.NamedArgSet{
+		"table":    t,
+		"col":      sqlfrag.Const(col.Name()),
+		"shadow":   sqlfrag.Const(shadowName),
+		"sync":     c.shadowSyncName(t, shadowName),
+		"check":    c.shadowCheckName(t, shadowName),
+		"dataType": sqlfrag.Const(c.dataType(def.Type, def)),
+	}
+}
+
+func (c *dialect) ExpandColumn(col sqlbuilder.Column) sqlfrag.Fragment {
+	return sqlfrag.Pair(`
+ALTER TABLE @table ADD COLUMN IF NOT EXISTS @shadow @dataType;
+CREATE OR REPLACE FUNCTION @sync() RETURNS trigger AS $$
+BEGIN
+	NEW.@shadow := CAST(NEW.@col AS @dataType);
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS @sync ON @table;
+CREATE TRIGGER @sync BEFORE INSERT OR UPDATE ON @table FOR EACH ROW EXECUTE FUNCTION @sync();`, c.onlineColumnArgs(col))
+}
+
+func (c *dialect) BackfillColumn(col sqlbuilder.Column, batchSize int, after []any) sqlfrag.Fragment {
+	pk := primaryKeyOf(sqlbuilder.GetColumnTable(col))
+	if pk == nil {
+		return nil
+	}
+
+	pkCols := sqlbuilder.ColumnCollect(pk.Cols())
+
+	desc := make([]sqlfrag.Fragment, 0)
+	for c := range pkCols.Cols() {
+		desc = append(desc, sqlfrag.Pair("? DESC", sqlfrag.Const(c.Name())))
+	}
+
+	args := c.onlineColumnArgs(col)
+	args["batchSize"] = sqlfrag.Const(strconv.Itoa(batchSize))
+	args["pk"] = pkCols
+	args["pkDesc"] = sqlfrag.JoinValues(", ", desc...)
+	args["after"] = sqlfrag.Empty()
+	if len(after) > 0 {
+		// keyset paging, each batch seeks by primary key instead of rescanning backfilled rows
+		args["after"] = sqlfrag.Pair(" WHERE (?) > (?)", pkCols, after)
+	}
+
+	return sqlfrag.Pair(`
+WITH batch AS (
+	SELECT @pk FROM @table@after ORDER BY @pk LIMIT @batchSize
+), backfilled AS (
+	UPDATE @table SET @shadow = CAST(@col AS @dataType)
+	WHERE (@pk) IN (SELECT @pk FROM batch) AND @shadow IS NULL AND @col IS NOT NULL
+)
+SELECT @pk FROM batch ORDER BY @pkDesc LIMIT 1;`, args)
+}
+
+func primaryKeyOf(t sqlbuilder.Table) sqlbuilder.Key {
+	for key := range t.Keys() {
+		if key.IsPrimary() {
+			return key
+		}
+	}
+	return nil
+}
+
+func (c *dialect) CountColumnBackfill(col sqlbuilder.Column) sqlfrag.Fragment {
+	return sqlfrag.Pair(`
+SELECT count(1) AS remaining FROM @table WHERE @shadow IS NULL AND @col IS NOT NULL;`, c.onlineColumnArgs(col))
+}
+
+func (c *dialect) PrepareCutover(col sqlbuilder.Column) []sqlfrag.Fragment {
+	if sqlbuilder.GetColumnDef(col).Null {
+		return nil
+	}
+
+	args := c.onlineColumnArgs(col)
+
+	// adding a NOT VALID check holds the exclusive lock without scanning,
+	// validating it scans under a lock which still allows reads and writes, so both run outside one transaction
+	return []sqlfrag.Fragment{
+		sqlfrag.Pair(`
+ALTER TABLE @table DROP CONSTRAINT IF EXISTS @check;
+ALTER TABLE @table ADD CONSTRAINT @check CHECK (@shadow IS NOT NULL) NOT VALID;`, args),
+		sqlfrag.Pair(`
+ALTER TABLE @table VALIDATE CONSTRAINT @check;`, args),
+	}
+}
+
+func (c *dialect) CutoverColumn(col sqlbuilder.Column, keys ...sqlbuilder.Key) sqlfrag.Fragment {
+	def := sqlbuilder.GetColumnDef(col)
+	args := c.onlineColumnArgs(col)
+
+	modifies := make([]sqlfrag.Fragment, 0, 2)
+	if def.Default != nil {
+		modifies = append(modifies, sqlfrag.Pair("ALTER COLUMN ? SET DEFAULT ?", args["col"], sqlfrag.Const(normalizeDefaultValue(def.Default, c.dataType(def.Type, def)))))
+	}
+	if !def.Null {
+		modifies = append(modifies, sqlfrag.Pair("ALTER COLUMN ? SET NOT NULL", args["col"]))
+	}
+
+	modify := make([]sqlfrag.Fragment, 0, 2)
+	if len(modifies) > 0 {
+		modify = append(modify, sqlfrag.Pair("\nALTER TABLE ? ?;", args["table"], sqlfrag.JoinValues(", ", modifies...)))
+	}
+	if !def.Null {
+		// SET NOT NULL is implied by the check validated in PrepareCutover, which is useless after that
+		modify = append(modify, sqlfrag.Pair("\nALTER TABLE ? DROP CONSTRAINT IF EXISTS ?;", args["table"], args["check"]))
+	}
+	args["modify"] = sqlfrag.Empty()
+	if len(modify) > 0 {
+		args["modify"] = sqlfrag.JoinValues("", modify...)
+	}
+
+	renames := make([]sqlfrag.Fragment, 0, len(keys))
+	for _, key := range keys {
+		next := sqlbuilder.Index(adapter.NextKeyName(key.Name()), nil).Of(sqlbuilder.GetKeyTable(key))
+
+		if key.IsPrimary() {
+			// primary key constraint dropped with origin column
+			renames = append(renames, sqlfrag.Pair("\nALTER TABLE ? ADD CONSTRAINT ? PRIMARY KEY USING INDEX ?;", args["table"], c.indexName(key), c.indexName(next)))
+			continue
+		}
+
+		renames = append(renames, c.RenameIndex(next, key))
+	}
+	args["renames"] = sqlfrag.Empty()
+	if len(renames) > 0 {
+		args["renames"] = sqlfrag.JoinValues("", renames...)
+	}
+
+	// rows are synced by trigger since expand, remaining rows and not null are checked before locking
+	return sqlfrag.Pair(`
+LOCK TABLE @table IN ACCESS EXCLUSIVE MODE;
+DROP TRIGGER IF EXISTS @sync ON @table;
+DROP FUNCTION IF EXISTS @sync();
+ALTER TABLE @table DROP COLUMN @col;
+ALTER TABLE @table RENAME COLUMN @shadow TO @col;@modify@renames`, args)
+}
+
+func (c *dialect) DropShadowColumn(shadow sqlbuilder.Column) sqlfrag.Fragment {
+	t := sqlbuilder.GetColumnTable(shadow)
+
+	return sqlfrag.Pair(`
+DROP TRIGGER IF EXISTS @sync ON @table;
+DROP FUNCTION IF EXISTS @sync();
+ALTER TABLE @table DROP COLUMN IF EXISTS @shadow;`, sqlfrag.NamedArgSet{
+		"table":  t,
+		"shadow": sqlfrag.Const(shadow.Name()),
+		"sync":   c.shadowSyncName(t, shadow.Name()),
+	})
+}
+
 func (c *dialect) DataType(columnType sqlbuilder.ColumnDef) sqlfrag.Fragment {
 	dbDataType := dealias(c.dbDataType(columnType.Type, columnType))
 	return sqlfrag.Pair(dbDataType + autocompleteSize(dbDataType, columnType) + c.dataTypeModify(columnType, dbDataType))
@@ -237,6 +607,10 @@ func (c *dialect) dbDataType(typ typex.Type, columnType sqlbuilder.ColumnDef) st
 		return columnType.DataType
 	}
 
+	if columnType.Enum != nil {
+		return columnType.Enum.Name
+	}
+
 	if rv, ok := typex.TryNew(typ); ok {
 		if dtd, ok := rv.Interface().(sqlbuilder.DataTypeDescriber); ok {
 			return dtd.DataType(c.DriverName())
@@ -293,6 +667,14 @@ func (c *dialect) dbDataType(typ typex.Type, columnType sqlbuilder.ColumnDef) st
 func (c *dialect) dataTypeModify(columnType sqlbuilder.ColumnDef, dataType string) string {
 	buf := bytes.NewBuffer(nil)
 
+	// postgres only support stored generated column
+	if g := columnType.Generated; g != nil {
+		buf.WriteString(" GENERATED ALWAYS AS ")
+		buf.WriteString(wrapParens(g.Expr))
+		buf.WriteString(" STORED")
+		return buf.String()
+	}
+
 	if !columnType.Null {
 		buf.WriteString(" NOT NULL")
 	}
@@ -327,6 +709,69 @@ func normalizeDefaultValue(defaultValue *string, dataType string) string {
 	return dv
 }
 
+var (
+	reCast     = regexp.MustCompile(`(?i)::(?:"[^"]+"|[a-z_][a-z0-9_]*(?: varying| precision| with(?:out)? time zone)?)(?:\(\d+(?:,\s*\d+)?\))?(?:\[\])*`)
+	reAnyArray = regexp.MustCompile(`(?i)=\s*ANY\s*\(\(?ARRAY\[([^\]]*)\]\)?\)`)
+	reAllArray = regexp.MustCompile(`(?i)<>\s*ALL\s*\(\(?ARRAY\[([^\]]*)\]\)?\)`)
+
+	likeOperators = strings.NewReplacer("!~~*", " NOT ILIKE ", "~~*", " ILIKE ", "!~~", " NOT LIKE ", "~~", " LIKE ")
+)
+
+// CanonicalExpr 去掉 PostgreSQL 补充的类型转换与分组括号，并把 = ANY (ARRAY[...])、~~ 等还原为 IN、LIKE 写法
+func (dialect) CanonicalExpr(expr string) string {
+	expr = reCast.ReplaceAllString(expr, "")
+	expr = reAnyArray.ReplaceAllString(expr, "IN ($1)")
+	expr = reAllArray.ReplaceAllString(expr, "NOT IN ($1)")
+	expr = likeOperators.Replace(expr)
+
+	return strings.Join(strings.Fields(dropGroupingParens(expr)), " ")
+}
+
+// dropGroupingParens 去掉除函数调用外的括号，字符串字面量内的括号保留
+func dropGroupingParens(expr string) string {
+	b := &strings.Builder{}
+	kept := make([]bool, 0)
+	quoted := false
+
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			call := i > 0 && isIdentChar(expr[i-1])
+			kept = append(kept, call)
+			if !call {
+				continue
+			}
+		case c == ')' && len(kept) > 0:
+			call := kept[len(kept)-1]
+			kept = kept[:len(kept)-1]
+			if !call {
+				continue
+			}
+		}
+
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func wrapParens(expr string) string {
+	return "(" + adapter.TrimParens(expr) + ")"
+}
+
 func autocompleteSize(dataType string, columnType sqlbuilder.ColumnDef) string {
 	switch dataType {
 	case "character varying", "character":
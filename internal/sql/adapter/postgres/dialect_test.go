@@ -4,13 +4,16 @@ import (
 	"context"
 	"database/sql/driver"
 	"fmt"
+	"reflect"
 	"testing"
 
 	testingx "github.com/octohelm/x/testing"
+	typex "github.com/octohelm/x/types"
 
 	"github.com/octohelm/storage/pkg/sqlbuilder"
 	"github.com/octohelm/storage/pkg/sqlfrag"
 	"github.com/octohelm/storage/pkg/sqlfrag/testutil"
+	"github.com/octohelm/storage/testdata/model"
 )
 
 func TestPostgresDialect(t *testing.T) {
@@ -27,6 +30,8 @@ func TestPostgresDialect(t *testing.T) {
 		sqlbuilder.PrimaryKey(sqlbuilder.Cols("F_id")),
 		sqlbuilder.UniqueIndex("I_name", sqlbuilder.Cols("F_id", "F_name"), sqlbuilder.IndexUsing("BTREE")),
 		sqlbuilder.Index("I_created_at", sqlbuilder.Cols("F_created_at"), sqlbuilder.IndexUsing("BTREE")),
+		sqlbuilder.UniqueIndex("I_name_active", sqlbuilder.Cols("F_name"), sqlbuilder.IndexUsing("BTREE"), sqlbuilder.IndexWhere("(f_created_at > 0)")),
+		sqlbuilder.Index("I_lower_name", nil, sqlbuilder.IndexUsing("BTREE"), sqlbuilder.IndexFieldNameAndOptions("(lower(f_name)),desc", "f_created_at")),
 		sqlbuilder.Index("I_geo", sqlbuilder.Cols("F_geo"), sqlbuilder.IndexUsing("GIST")),
 	)
 
@@ -46,6 +51,26 @@ func TestPostgresDialect(t *testing.T) {
 			c.AddIndex(table.K("i_geo")),
 			sqlfrag.Pair( /* language=PostgreSQL */ "CREATE INDEX t_i_geo ON t USING GIST (f_geo);"),
 		},
+		"AddPartialIndex": {
+			c.AddIndex(table.K("I_name_active")),
+			sqlfrag.Pair( /* language=PostgreSQL */ "CREATE UNIQUE INDEX t_i_name_active ON t USING BTREE (f_name) WHERE f_created_at > 0;"),
+		},
+		"AddExprIndex": {
+			c.AddIndex(table.K("I_lower_name")),
+			sqlfrag.Pair( /* language=PostgreSQL */ "CREATE INDEX t_i_lower_name ON t USING BTREE ((lower(f_name)) DESC,f_created_at);"),
+		},
+		"AddIndexConcurrently": {
+			c.AddIndexConcurrently(table.K("I_name_active")),
+			sqlfrag.Pair( /* language=PostgreSQL */ "CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS t_i_name_active ON t USING BTREE (f_name) WHERE f_created_at > 0;"),
+		},
+		"RenameIndex": {
+			c.RenameIndex(sqlbuilder.Index("i_name_next", nil).Of(table), table.K("i_name")),
+			sqlfrag.Pair( /* language=PostgreSQL */ "ALTER INDEX t_i_name_next RENAME TO t_i_name;"),
+		},
+		"DropIndexConcurrently": {
+			c.DropIndexConcurrently(table.K("i_name")),
+			sqlfrag.Pair( /* language=PostgreSQL */ "DROP INDEX CONCURRENTLY IF EXISTS t_i_name;"),
+		},
 		"DropIndex": {
 			c.DropIndex(table.K("i_name")),
 			sqlfrag.Pair( /* language=PostgreSQL */ "DROP INDEX IF EXISTS t_i_name;"),
@@ -81,6 +106,26 @@ func TestPostgresDialect(t *testing.T) {
 			c.DropColumn(table.F("f_name")),
 			sqlfrag.Pair( /* language=PostgreSQL */ "ALTER TABLE t DROP COLUMN f_name;"),
 		},
+		"Excluded": {
+			c.Excluded(table.F("f_name")),
+			sqlfrag.Pair( /* language=PostgreSQL */ "EXCLUDED.f_name"),
+		},
+		"ILike": {
+			c.ILike(table.F("f_name"), "%Ali%", false, false),
+			sqlfrag.Pair( /* language=PostgreSQL */ "f_name ILIKE ?", "%Ali%"),
+		},
+		"NotILikeUnaccent": {
+			c.ILike(table.F("f_name"), "%é%", true, true),
+			sqlfrag.Pair( /* language=PostgreSQL */ "unaccent(f_name) NOT ILIKE unaccent(?)", "%é%"),
+		},
+		"JSONArrayContainsAll": {
+			c.JSONArrayContains(table.F("f_name"), []any{"a", "b"}, true),
+			sqlfrag.Pair( /* language=PostgreSQL */ "CAST(f_name AS jsonb) @> CAST(? AS jsonb)", `["a","b"]`),
+		},
+		"JSONArrayContainsAny": {
+			c.JSONArrayContains(table.F("f_name"), []any{"a", "b"}, false),
+			sqlfrag.Pair( /* language=PostgreSQL */ "CAST(f_name AS jsonb) @> ANY (ARRAY[CAST(? AS jsonb),CAST(? AS jsonb)])", `["a"]`, `["b"]`),
+		},
 	}
 
 	for name, c := range cases {
@@ -108,3 +153,243 @@ func (Point) ValueEx() string {
 func (p Point) Value() (driver.Value, error) {
 	return fmt.Sprintf("POINT(%v %v)", p.X, p.Y), nil
 }
+
+func TestPostgresDialect_CheckAndGenerated(t *testing.T) {
+	c := &dialect{}
+
+	table := sqlbuilder.T(
+		"t",
+		sqlbuilder.Col("f_price", sqlbuilder.ColTypeOf(int64(0), "")),
+		sqlbuilder.Col("f_total", sqlbuilder.ColDef(sqlbuilder.ColumnDef{
+			Type:      typex.FromRType(reflect.TypeFor[int64]()),
+			Generated: &sqlbuilder.ColumnGenerated{Expr: "f_price * 2"},
+		})),
+		sqlbuilder.CheckConstraint("ck_price", "f_price >= 0"),
+	)
+
+	cases := map[string]struct {
+		expr   sqlfrag.Fragment
+		expect sqlfrag.Fragment
+	}{
+		"CreateTableIsNotExists": {
+			c.CreateTableIsNotExists(table)[0],
+			sqlfrag.Pair( /* language=PostgreSQL */ `CREATE TABLE IF NOT EXISTS t (
+	f_price bigint NOT NULL,
+	f_total bigint GENERATED ALWAYS AS (f_price * 2) STORED,
+	CONSTRAINT t_ck_price CHECK (f_price >= 0)
+);`),
+		},
+		"AddCheck": {
+			c.AddCheck(table, sqlbuilder.CheckConstraint("ck_price", "(f_price >= 0)")),
+			sqlfrag.Pair( /* language=PostgreSQL */ "ALTER TABLE t ADD CONSTRAINT t_ck_price CHECK (f_price >= 0);"),
+		},
+		"DropCheck": {
+			c.DropCheck(table, sqlbuilder.CheckConstraint("ck_price", "f_price >= 0")),
+			sqlfrag.Pair( /* language=PostgreSQL */ "ALTER TABLE t DROP CONSTRAINT IF EXISTS t_ck_price;"),
+		},
+		"ModifyGeneratedColumn": {
+			c.ModifyColumn(table.F("f_total"), sqlbuilder.Col("f_total", sqlbuilder.ColTypeOf(int64(0), "")).Of(table)),
+			sqlfrag.Pair( /* language=PostgreSQL */ "ALTER TABLE t DROP COLUMN f_total;\nALTER TABLE t ADD COLUMN f_total bigint GENERATED ALWAYS AS (f_price * 2) STORED;"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			q, args := sqlfrag.Collect(context.Background(), c.expect)
+
+			testingx.Expect(t, c.expr, testutil.BeFragment(q, args...))
+		})
+	}
+}
+
+func TestPostgresDialect_OnlineColumn(t *testing.T) {
+	c := &dialect{}
+
+	table := sqlbuilder.T(
+		"t",
+		sqlbuilder.Col("f_id", sqlbuilder.ColTypeOf(uint64(0), "")),
+		sqlbuilder.Col("f_amount", sqlbuilder.ColTypeOf(int64(0), ",online,default='0'")),
+		sqlbuilder.PrimaryKey(sqlbuilder.Cols("f_id")),
+		sqlbuilder.Index("i_amount", sqlbuilder.Cols("f_amount")),
+	)
+
+	col := table.F("f_amount")
+
+	cases := map[string]struct {
+		expr   sqlfrag.Fragment
+		expect sqlfrag.Fragment
+	}{
+		"ExpandColumn": {
+			c.ExpandColumn(col),
+			sqlfrag.Pair( /* language=PostgreSQL */ `ALTER TABLE t ADD COLUMN IF NOT EXISTS __f_amount_online bigint;
+CREATE OR REPLACE FUNCTION t_f_amount_online_sync() RETURNS trigger AS $$
+BEGIN
+	NEW.__f_amount_online := CAST(NEW.f_amount AS bigint);
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS t_f_amount_online_sync ON t;
+CREATE TRIGGER t_f_amount_online_sync BEFORE INSERT OR UPDATE ON t FOR EACH ROW EXECUTE FUNCTION t_f_amount_online_sync();`),
+		},
+		"BackfillColumn": {
+			c.BackfillColumn(col, 500, nil),
+			sqlfrag.Pair( /* language=PostgreSQL */ `WITH batch AS (
+	SELECT f_id FROM t ORDER BY f_id LIMIT 500
+), backfilled AS (
+	UPDATE t SET __f_amount_online = CAST(f_amount AS bigint)
+	WHERE (f_id) IN (SELECT f_id FROM batch) AND __f_amount_online IS NULL AND f_amount IS NOT NULL
+)
+SELECT f_id FROM batch ORDER BY f_id DESC LIMIT 1;`),
+		},
+		"BackfillColumnAfter": {
+			c.BackfillColumn(col, 500, []any{uint64(500)}),
+			sqlfrag.Pair( /* language=PostgreSQL */ `WITH batch AS (
+	SELECT f_id FROM t WHERE (f_id) > (?) ORDER BY f_id LIMIT 500
+), backfilled AS (
+	UPDATE t SET __f_amount_online = CAST(f_amount AS bigint)
+	WHERE (f_id) IN (SELECT f_id FROM batch) AND __f_amount_online IS NULL AND f_amount IS NOT NULL
+)
+SELECT f_id FROM batch ORDER BY f_id DESC LIMIT 1;`, uint64(500)),
+		},
+		"CountColumnBackfill": {
+			c.CountColumnBackfill(col),
+			sqlfrag.Pair( /* language=PostgreSQL */ `SELECT count(1) AS remaining FROM t WHERE __f_amount_online IS NULL AND f_amount IS NOT NULL;`),
+		},
+		"PrepareCutover": {
+			c.PrepareCutover(col)[0],
+			sqlfrag.Pair( /* language=PostgreSQL */ `ALTER TABLE t DROP CONSTRAINT IF EXISTS t_f_amount_online_not_null;
+ALTER TABLE t ADD CONSTRAINT t_f_amount_online_not_null CHECK (__f_amount_online IS NOT NULL) NOT VALID;`),
+		},
+		"PrepareCutoverValidate": {
+			c.PrepareCutover(col)[1],
+			sqlfrag.Pair( /* language=PostgreSQL */ `ALTER TABLE t VALIDATE CONSTRAINT t_f_amount_online_not_null;`),
+		},
+		"CutoverColumn": {
+			c.CutoverColumn(col, table.K("primary"), table.K("i_amount")),
+			sqlfrag.Pair( /* language=PostgreSQL */ `LOCK TABLE t IN ACCESS EXCLUSIVE MODE;
+DROP TRIGGER IF EXISTS t_f_amount_online_sync ON t;
+DROP FUNCTION IF EXISTS t_f_amount_online_sync();
+ALTER TABLE t DROP COLUMN f_amount;
+ALTER TABLE t RENAME COLUMN __f_amount_online TO f_amount;
+ALTER TABLE t ALTER COLUMN f_amount SET DEFAULT '0'::bigint, ALTER COLUMN f_amount SET NOT NULL;
+ALTER TABLE t DROP CONSTRAINT IF EXISTS t_f_amount_online_not_null;
+ALTER TABLE t ADD CONSTRAINT t_pkey PRIMARY KEY USING INDEX t_primary_next;
+ALTER INDEX t_i_amount_next RENAME TO t_i_amount;`),
+		},
+		"DropShadowColumn": {
+			c.DropShadowColumn(sqlbuilder.Col("__f_amount_online").Of(table)),
+			sqlfrag.Pair( /* language=PostgreSQL */ `DROP TRIGGER IF EXISTS t_f_amount_online_sync ON t;
+DROP FUNCTION IF EXISTS t_f_amount_online_sync();
+ALTER TABLE t DROP COLUMN IF EXISTS __f_amount_online;`),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			q, args := sqlfrag.Collect(context.Background(), c.expect)
+
+			testingx.Expect(t, c.expr, testutil.BeFragment(q, args...))
+		})
+	}
+}
+
+func TestPostgresDialect_Enum(t *testing.T) {
+	c := &dialect{}
+
+	table := sqlbuilder.T(
+		"t",
+		sqlbuilder.Col("f_gender", sqlbuilder.ColTypeOf(model.Gender(0), ",enum,default='0'")),
+	)
+
+	col := table.F("f_gender")
+
+	createEnumType := /* language=PostgreSQL */ `DO $$ BEGIN
+	CREATE TYPE e_model_gender AS ENUM ('0', '1', '2');
+EXCEPTION WHEN duplicate_object THEN
+	IF EXISTS (SELECT 1 FROM pg_enum WHERE enumtypid = 'e_model_gender'::regtype AND NOT enumlabel = ANY (ARRAY['0', '1', '2'])) THEN
+		RAISE EXCEPTION 'enum type % exists with undeclared labels', 'e_model_gender';
+	END IF;
+END $$;`
+
+	cases := map[string]struct {
+		expr   sqlfrag.Fragment
+		expect sqlfrag.Fragment
+	}{
+		"CreateEnumType": {
+			c.CreateTableIsNotExists(table)[0],
+			sqlfrag.Pair(createEnumType),
+		},
+		"CreateTableIsNotExists": {
+			c.CreateTableIsNotExists(table)[1],
+			sqlfrag.Pair( /* language=PostgreSQL */ `CREATE TABLE IF NOT EXISTS t (
+	f_gender e_model_gender NOT NULL DEFAULT '0'::e_model_gender
+);`),
+		},
+		"AddColumn": {
+			c.AddColumn(col),
+			sqlfrag.Pair(createEnumType + /* language=PostgreSQL */ `
+ALTER TABLE t ADD COLUMN f_gender e_model_gender NOT NULL DEFAULT '0'::e_model_gender;`),
+		},
+		"ModifyColumnToEnum": {
+			c.ModifyColumn(col, sqlbuilder.Col("f_gender", sqlbuilder.ColTypeOf(model.Gender(0), ",default='0'")).Of(table)),
+			sqlfrag.Pair(createEnumType + /* language=PostgreSQL */ `
+ALTER TABLE t ALTER COLUMN f_gender DROP DEFAULT, ALTER COLUMN f_gender TYPE e_model_gender USING (f_gender::text)::e_model_gender /* FROM integer */, ALTER COLUMN f_gender SET DEFAULT '0'::e_model_gender /* FROM '0'::integer */;`),
+		},
+		"AddEnumValues": {
+			c.AddEnumValues(col, []string{"3", "4"}),
+			sqlfrag.Pair( /* language=PostgreSQL */ `ALTER TYPE e_model_gender ADD VALUE IF NOT EXISTS '3';
+ALTER TYPE e_model_gender ADD VALUE IF NOT EXISTS '4';`),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			q, args := sqlfrag.Collect(context.Background(), c.expect)
+
+			testingx.Expect(t, c.expr, testutil.BeFragment(q, args...))
+		})
+	}
+}
+
+func TestPostgresDialect_CanonicalExpr(t *testing.T) {
+	c := &dialect{}
+
+	cases := map[string]struct {
+		stored   string
+		declared string
+		expect   string
+	}{
+		"表达式索引": {
+			stored:   "(lower((f_name)::text))",
+			declared: "(lower(f_name))",
+			expect:   "lower(f_name)",
+		},
+		"IN 列表": {
+			stored:   "((f_name)::text = ANY ((ARRAY['a'::character varying, 'b'::character varying])::text[]))",
+			declared: "f_name IN ('a', 'b')",
+			expect:   "f_name IN 'a', 'b'",
+		},
+		"NOT IN 列表": {
+			stored:   "((f_name)::text <> ALL (ARRAY['a'::text, 'b'::text]))",
+			declared: "f_name NOT IN ('a', 'b')",
+			expect:   "f_name NOT IN 'a', 'b'",
+		},
+		"LIKE 运算符": {
+			stored:   "((f_name)::text ~~ '(a)%'::text)",
+			declared: "f_name LIKE '(a)%'",
+			expect:   "f_name LIKE '(a)%'",
+		},
+		"分组括号": {
+			stored:   "((f_price > 0) AND (f_total >= (0)::numeric))",
+			declared: "f_price > 0 AND f_total >= 0",
+			expect:   "f_price > 0 AND f_total >= 0",
+		},
+	}
+
+	for name, x := range cases {
+		t.Run(name, func(t *testing.T) {
+			testingx.Expect(t, c.CanonicalExpr(x.stored), testingx.Be(x.expect))
+			testingx.Expect(t, c.CanonicalExpr(x.declared), testingx.Be(x.expect))
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	textscanner "text/scanner"
 
+	"github.com/octohelm/storage/internal/sql/adapter"
 	"github.com/octohelm/storage/internal/sql/scanner"
 	"github.com/octohelm/storage/pkg/sqlbuilder"
 )
@@ -30,6 +31,11 @@ func (a *sqliteAdapter) Catalog(ctx context.Context) (*sqlbuilder.Tables, error)
 	}
 
 	for _, schema := range schemaList {
+		// skip internal tables like sqlite_sequence
+		if strings.HasPrefix(schema.Table, "sqlite_") {
+			continue
+		}
+
 		if schema.Type == "table" {
 			table := cat.Table(schema.Table)
 			if table == nil {
@@ -43,12 +49,35 @@ func (a *sqliteAdapter) Catalog(ctx context.Context) (*sqlbuilder.Tables, error)
 					continue
 				}
 
+				if constraintName, ok := strings.CutPrefix(f, "CONSTRAINT "); ok {
+					// CHECK ( f_age > = 0 )
+					if expr, ok := strings.CutPrefix(colSql, "CHECK "); ok {
+						name := strings.TrimPrefix(strings.ToLower(constraintName), table.TableName()+"_")
+						table.(sqlbuilder.CheckCollectionManager).AddCheck(sqlbuilder.CheckConstraint(name, normalizeExpr(expr)))
+					}
+					continue
+				}
+
 				def := sqlbuilder.ColumnDef{}
 
 				if pkSQL, ok := cols["PRIMARY"]; ok {
 					def.AutoIncrement = strings.Contains(pkSQL, f)
 				}
 
+				if dataType, generated, ok := cutGenerated(colSql); ok {
+					def.DataType = dataType
+					def.Generated = generated
+					def.Null = true
+
+					table.(sqlbuilder.ColumnCollectionManger).AddCol(sqlbuilder.Col(f, sqlbuilder.ColDef(def)))
+					continue
+				}
+
+				if colDef, values, ok := cutEnumCheck(colSql); ok {
+					colSql = colDef
+					def.Enum = &sqlbuilder.ColumnEnum{Values: values}
+				}
+
 				defaultValue := ""
 				parts := strings.Split(colSql, " DEFAULT ")
 				if len(parts) > 1 {
@@ -76,22 +105,17 @@ func (a *sqliteAdapter) Catalog(ctx context.Context) (*sqlbuilder.Tables, error)
 
 			indexName := strings.ToLower(schema.Name[len(table.TableName())+1:])
 			isUnique := strings.Contains(schema.SQL, "UNIQUE")
-			indexColNameAndOptions := strings.Split(
-				strings.TrimSpace(schema.SQL[strings.Index(schema.SQL, "(")+1:strings.Index(schema.SQL, ")")]),
-				",",
-			)
-
-			colNameAndOptions := make([]sqlbuilder.FieldNameAndOption, len(indexColNameAndOptions))
-			for i, c := range indexColNameAndOptions {
-				colNameAndOptions[i] = sqlbuilder.FieldNameAndOption(strings.ReplaceAll(c, " ", ","))
-			}
+
+			// CREATE INDEX t_user_i_name ON t_user (f_name, lower(f_nickname)) WHERE f_deleted_at = 0
+			_, indexDef, _ := strings.Cut(schema.SQL, " ON ")
+			colNameAndOptions, where := adapter.CutIndexDef(indexDef)
 
 			var key sqlbuilder.Key
 
 			if isUnique {
-				key = sqlbuilder.UniqueIndex(indexName, nil, sqlbuilder.IndexFieldNameAndOptions(colNameAndOptions...))
+				key = sqlbuilder.UniqueIndex(indexName, nil, sqlbuilder.IndexWhere(where), sqlbuilder.IndexFieldNameAndOptions(colNameAndOptions...))
 			} else {
-				key = sqlbuilder.Index(indexName, nil, sqlbuilder.IndexFieldNameAndOptions(colNameAndOptions...))
+				key = sqlbuilder.Index(indexName, nil, sqlbuilder.IndexWhere(where), sqlbuilder.IndexFieldNameAndOptions(colNameAndOptions...))
 			}
 
 			table.(sqlbuilder.KeyCollectionManager).AddKey(key)
@@ -112,6 +136,38 @@ func (sqliteMaster) TableName() string {
 	return "sqlite_master"
 }
 
+// cutGenerated 解析生成列定义，如 BIGINT GENERATED ALWAYS AS ( f_price * 2 ) STORED
+func cutGenerated(colSql string) (dataType string, generated *sqlbuilder.ColumnGenerated, ok bool) {
+	dataType, rest, ok := strings.Cut(colSql, " GENERATED ALWAYS AS ")
+	if !ok {
+		return "", nil, false
+	}
+
+	depth := 0
+	end := -1
+
+	for i := 0; i < len(rest) && end < 0; i++ {
+		switch rest[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+	}
+
+	if end < 0 {
+		return "", nil, false
+	}
+
+	return strings.TrimSpace(dataType), &sqlbuilder.ColumnGenerated{
+		Expr:   normalizeExpr(rest[:end+1]),
+		Stored: strings.Contains(rest[end+1:], "STORED"),
+	}, true
+}
+
 func extractCols(r io.Reader) map[string]string {
 	s := &textscanner.Scanner{}
 	s.Init(r)
@@ -125,7 +181,12 @@ func extractCols(r io.Reader) map[string]string {
 		if len(parts) == 0 || scope != 1 {
 			return
 		}
-		cols[parts[0]] = strings.Join(parts[1:], " ")
+		// CONSTRAINT <name> CHECK (...)
+		if parts[0] == "CONSTRAINT" && len(parts) > 1 {
+			cols[parts[0]+" "+parts[1]] = strings.Join(parts[2:], " ")
+		} else {
+			cols[parts[0]] = strings.Join(parts[1:], " ")
+		}
 		parts = make([]string, 0)
 	}
 
@@ -142,6 +203,10 @@ func extractCols(r io.Reader) map[string]string {
 			collect()
 			scope--
 		case ",":
+			if scope > 1 {
+				// keep comma in nested scope, like CHECK ( f_a IN ( 1 , 2 ) )
+				break
+			}
 			collect()
 			continue
 		}
@@ -153,3 +218,19 @@ func extractCols(r io.Reader) map[string]string {
 
 	return cols
 }
+
+// cutEnumCheck 解析枚举列的列级 CHECK 约束，如 INTEGER NOT NULL DEFAULT '0' CHECK ( f_gender IN ( '0' , '1' ) )
+func cutEnumCheck(colSql string) (colDef string, values []string, ok bool) {
+	colDef, check, ok := strings.Cut(colSql, " CHECK ")
+	if !ok {
+		return colSql, nil, false
+	}
+
+	for _, part := range strings.Fields(check) {
+		if len(part) > 1 && part[0] == '\'' && part[len(part)-1] == '\'' {
+			values = append(values, strings.ReplaceAll(part[1:len(part)-1], "''", "'"))
+		}
+	}
+
+	return colDef, values, true
+}
@@ -2,9 +2,11 @@ package sqlite
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/octohelm/storage/internal/testutil"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
 )
 
 func Test_parseSQL(t *testing.T) {
@@ -31,3 +33,38 @@ CREATE TABLE t_user (
 		"f_username":   "TEXT NOT NULL DEFAULT ''",
 	}))
 }
+
+func Test_parseSQLWithCheckAndGenerated(t *testing.T) {
+	cols := extractCols(bytes.NewBuffer([]byte(`
+CREATE TABLE t_order (
+	f_price BIGINT NOT NULL,
+	f_total BIGINT GENERATED ALWAYS AS (f_price * 2) STORED,
+	CONSTRAINT t_order_ck_price CHECK (f_price >= 0 AND f_price IN (1, 2))
+)
+`)))
+	testutil.Expect(t, cols, testutil.Equal(map[string]string{
+		"f_price":                     "BIGINT NOT NULL",
+		"f_total":                     "BIGINT GENERATED ALWAYS AS ( f_price * 2 ) STORED",
+		"CONSTRAINT t_order_ck_price": "CHECK ( f_price > = 0 AND f_price IN ( 1 , 2 ) )",
+	}))
+
+	dataType, generated, ok := cutGenerated(cols["f_total"])
+	testutil.Expect(t, ok, testutil.Equal(true))
+	testutil.Expect(t, dataType, testutil.Equal("BIGINT"))
+	testutil.Expect(t, generated, testutil.Equal(&sqlbuilder.ColumnGenerated{Expr: "f_price * 2", Stored: true}))
+
+	testutil.Expect(t, normalizeExpr(strings.TrimPrefix(cols["CONSTRAINT t_order_ck_price"], "CHECK ")), testutil.Equal("f_price >= 0 AND f_price IN ( 1 , 2 )"))
+}
+
+func Test_cutEnumCheck(t *testing.T) {
+	cols := extractCols(bytes.NewBuffer([]byte(`
+CREATE TABLE t_user (
+	f_gender INTEGER NOT NULL DEFAULT '0' CHECK (f_gender IN ('0','1','2'))
+)
+`)))
+
+	colDef, values, ok := cutEnumCheck(cols["f_gender"])
+	testutil.Expect(t, ok, testutil.Equal(true))
+	testutil.Expect(t, colDef, testutil.Equal("INTEGER NOT NULL DEFAULT '0'"))
+	testutil.Expect(t, values, testutil.Equal([]string{"0", "1", "2"}))
+}
@@ -8,6 +8,8 @@ import (
 	"iter"
 	"reflect"
 	"slices"
+	"strings"
+	textscanner "text/scanner"
 
 	typex "github.com/octohelm/x/types"
 
@@ -16,7 +18,10 @@ import (
 	"github.com/octohelm/storage/pkg/sqlfrag"
 )
 
-var _ adapter.Dialect = (*dialect)(nil)
+var (
+	_ adapter.Dialect       = (*dialect)(nil)
+	_ adapter.UpsertDialect = (*dialect)(nil)
+)
 
 type dialect struct{}
 
@@ -24,12 +29,21 @@ func (dialect) DriverName() string {
 	return "sqlite"
 }
 
+func (dialect) Excluded(col sqlbuilder.Column) sqlfrag.Fragment {
+	return col.Fragment("excluded.?", sqlfrag.Const(col.Name()))
+}
+
+// SupportMerge sqlite 不支持 MERGE
+func (dialect) SupportMerge() bool {
+	return false
+}
+
 func (c *dialect) AddIndex(key sqlbuilder.Key) sqlfrag.Fragment {
 	if key.IsPrimary() {
 		return nil
 	}
 
-	return sqlfrag.Pair("\nCREATE @index_type @index_name ON @table (@columnAndOptions);", sqlfrag.NamedArgSet{
+	return sqlfrag.Pair("\nCREATE @index_type @index_name ON @table (@columnAndOptions)@where;", sqlfrag.NamedArgSet{
 		"table": sqlbuilder.GetKeyTable(key),
 		"index_type": func() sqlfrag.Fragment {
 			if key.IsUnique() {
@@ -39,9 +53,17 @@ func (c *dialect) AddIndex(key sqlbuilder.Key) sqlfrag.Fragment {
 		}(),
 		"index_name":       c.indexName(key),
 		"columnAndOptions": sqlbuilder.AsKeyColumnsTableDef(key),
+		"where":            c.indexWhere(key),
 	})
 }
 
+func (c *dialect) indexWhere(key sqlbuilder.Key) sqlfrag.Fragment {
+	if keyDef := sqlbuilder.GetKeyDef(key); keyDef != nil && keyDef.Where() != "" {
+		return sqlfrag.Const(" WHERE " + adapter.TrimParens(keyDef.Where()))
+	}
+	return sqlfrag.Empty()
+}
+
 func (c *dialect) DropIndex(key sqlbuilder.Key) sqlfrag.Fragment {
 	if key.IsPrimary() {
 		// pk could not changed
@@ -99,6 +121,14 @@ func (c *dialect) CreateTableIsNotExists(t sqlbuilder.Table) (exprs []sqlfrag.Fr
 							return
 						}
 					}
+
+					if enumCheck := c.enumCheck(col); enumCheck != nil {
+						for q, args := range enumCheck.Frag(ctx) {
+							if !yield(q, args) {
+								return
+							}
+						}
+					}
 				}
 
 				for key := range t.Keys() {
@@ -126,6 +156,14 @@ func (c *dialect) CreateTableIsNotExists(t sqlbuilder.Table) (exprs []sqlfrag.Fr
 						}
 					}
 				}
+
+				for check := range sqlbuilder.GetTableChecks(t) {
+					for q, args := range sqlfrag.Pair(",\n\tCONSTRAINT ? CHECK (?)", c.checkName(t, check), sqlfrag.Const(normalizeExpr(check.Expr()))).Frag(ctx) {
+						if !yield(q, args) {
+							return
+						}
+					}
+				}
 			}
 		}),
 	}))
@@ -141,6 +179,20 @@ func (c *dialect) CreateTableIsNotExists(t sqlbuilder.Table) (exprs []sqlfrag.Fr
 	return exprs
 }
 
+func (c *dialect) checkName(t sqlbuilder.Table, check sqlbuilder.Check) sqlfrag.Fragment {
+	return sqlfrag.Const(t.TableName() + "_" + check.Name())
+}
+
+// AddCheck sqlite 不支持对已有表追加 CHECK 约束，需要重建表，这里不做处理。
+func (c *dialect) AddCheck(t sqlbuilder.Table, check sqlbuilder.Check) sqlfrag.Fragment {
+	return nil
+}
+
+// DropCheck sqlite 不支持删除已有 CHECK 约束，需要重建表，这里不做处理。
+func (c *dialect) DropCheck(t sqlbuilder.Table, check sqlbuilder.Check) sqlfrag.Fragment {
+	return nil
+}
+
 func (c *dialect) DropTable(t sqlbuilder.Table) sqlfrag.Fragment {
 	return sqlfrag.Pair("\nDROP TABLE IF EXISTS @table;", sqlfrag.NamedArgSet{
 		"table": t,
@@ -153,14 +205,39 @@ func (c *dialect) TruncateTable(t sqlbuilder.Table) sqlfrag.Fragment {
 	})
 }
 
+// AddColumn 为已有表添加列；sqlite 只能为已有表添加 VIRTUAL 生成列，STORED 生成列返回 nil，需要重建表。
 func (c *dialect) AddColumn(col sqlbuilder.Column) sqlfrag.Fragment {
+	if g := sqlbuilder.GetColumnDef(col).Generated; g != nil && g.Stored {
+		return nil
+	}
+
+	dataType := c.DataType(sqlbuilder.GetColumnDef(col))
+	if enumCheck := c.enumCheck(col); enumCheck != nil {
+		dataType = sqlfrag.JoinValues("", dataType, enumCheck)
+	}
+
 	return sqlfrag.Pair("\nALTER TABLE @table ADD COLUMN @col @dataType;", sqlfrag.NamedArgSet{
 		"table":    sqlbuilder.GetColumnTable(col),
 		"col":      col,
-		"dataType": c.DataType(sqlbuilder.GetColumnDef(col)),
+		"dataType": dataType,
 	})
 }
 
+// enumCheck 以列级 CHECK 约束限定枚举列的取值；sqlite 无法修改已有约束，枚举取值变更需要重建表。
+func (c *dialect) enumCheck(col sqlbuilder.Column) sqlfrag.Fragment {
+	def := sqlbuilder.GetColumnDef(col)
+	if def.Enum == nil || def.Generated != nil {
+		return nil
+	}
+
+	values := make([]string, 0, len(def.Enum.Values))
+	for _, v := range def.Enum.Values {
+		values = append(values, "'"+strings.ReplaceAll(v, "'", "''")+"'")
+	}
+
+	return sqlfrag.Pair(" CHECK (? IN (?))", col, sqlfrag.Const(strings.Join(values, ",")))
+}
+
 func (c *dialect) RenameColumn(col sqlbuilder.Column, target sqlbuilder.Column) sqlfrag.Fragment {
 	return sqlfrag.Pair("\nALTER TABLE @table RENAME COLUMN @oldCol TO @newCol;", sqlfrag.NamedArgSet{
 		"table":  sqlbuilder.GetColumnTable(col),
@@ -177,6 +254,12 @@ func (c *dialect) ModifyColumn(col sqlbuilder.Column, prevCol sqlbuilder.Column)
 		return nil
 	}
 
+	// generated column could not be altered, only to recreate.
+	// notice sqlite could only add VIRTUAL generated column to exists table.
+	if def.Generated != nil || sqlbuilder.GetColumnDef(prevCol).Generated != nil {
+		return sqlfrag.JoinValues("", c.DropColumn(prevCol), c.AddColumn(col))
+	}
+
 	prevTmpCol := sqlbuilder.Col(
 		"__"+prevCol.Name(),
 		sqlbuilder.ColDef(sqlbuilder.GetColumnDef(prevCol)),
@@ -269,6 +352,18 @@ func (c *dialect) dbDataType(typ typex.Type, columnType sqlbuilder.ColumnDef) st
 func (c *dialect) dataTypeModify(columnType sqlbuilder.ColumnDef, dataType string) string {
 	buf := bytes.NewBuffer(nil)
 
+	if g := columnType.Generated; g != nil {
+		buf.WriteString(" GENERATED ALWAYS AS (")
+		buf.WriteString(normalizeExpr(g.Expr))
+		buf.WriteString(")")
+		if g.Stored {
+			buf.WriteString(" STORED")
+		} else {
+			buf.WriteString(" VIRTUAL")
+		}
+		return buf.String()
+	}
+
 	if !columnType.Null {
 		buf.WriteString(" NOT NULL")
 	}
@@ -285,6 +380,41 @@ func (c dialect) indexName(key sqlbuilder.Key) sqlfrag.Fragment {
 	return sqlfrag.Pair(fmt.Sprintf("%s_%s", sqlbuilder.GetKeyTable(key).TableName(), key.Name()))
 }
 
+var multiCharOperators = map[string]bool{
+	">=":  true,
+	"<=":  true,
+	"<>":  true,
+	"!=":  true,
+	"==":  true,
+	"||":  true,
+	"<<":  true,
+	">>":  true,
+	"->":  true,
+	"->>": true,
+}
+
+// normalizeExpr 按 catalog 解析时的分词方式重新拼接表达式，使声明与 catalog 中的表达式一致。
+func normalizeExpr(expr string) string {
+	s := &textscanner.Scanner{}
+	s.Init(strings.NewReader(adapter.TrimParens(expr)))
+	s.Error = func(s *textscanner.Scanner, msg string) {}
+
+	parts := make([]string, 0)
+
+	for tok := s.Scan(); tok != textscanner.EOF; tok = s.Scan() {
+		part := s.TokenText()
+
+		if n := len(parts); n > 0 && multiCharOperators[parts[n-1]+part] {
+			parts[n-1] += part
+			continue
+		}
+
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, " ")
+}
+
 func normalizeDefaultValue(defaultValue *string, dataType string) string {
 	if defaultValue == nil {
 		return ""
@@ -2,13 +2,16 @@ package sqlite
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	testingx "github.com/octohelm/x/testing"
+	typex "github.com/octohelm/x/types"
 
 	"github.com/octohelm/storage/pkg/sqlbuilder"
 	"github.com/octohelm/storage/pkg/sqlfrag"
 	"github.com/octohelm/storage/pkg/sqlfrag/testutil"
+	"github.com/octohelm/storage/testdata/model"
 )
 
 func TestSqliteDialect(t *testing.T) {
@@ -24,6 +27,8 @@ func TestSqliteDialect(t *testing.T) {
 		sqlbuilder.PrimaryKey(sqlbuilder.Cols("F_id")),
 		sqlbuilder.UniqueIndex("I_name", sqlbuilder.Cols("F_id", "F_name"), sqlbuilder.IndexUsing("BTREE")),
 		sqlbuilder.Index("I_created_at", sqlbuilder.Cols("F_created_at"), sqlbuilder.IndexUsing("BTREE")),
+		sqlbuilder.UniqueIndex("I_name_active", sqlbuilder.Cols("F_name"), sqlbuilder.IndexUsing("BTREE"), sqlbuilder.IndexWhere("(f_created_at > 0)")),
+		sqlbuilder.Index("I_lower_name", nil, sqlbuilder.IndexUsing("BTREE"), sqlbuilder.IndexFieldNameAndOptions("(lower(f_name)),desc", "f_created_at")),
 	)
 
 	cases := map[string]struct {
@@ -55,6 +60,10 @@ func TestSqliteDialect(t *testing.T) {
 			c.DropColumn(table.F("f_name")),
 			sqlfrag.Pair( /* language=sqlite */ "ALTER TABLE t DROP COLUMN f_name;"),
 		},
+		"Excluded": {
+			c.Excluded(table.F("f_name")),
+			sqlfrag.Pair( /* language=sqlite */ "excluded.f_name"),
+		},
 		"AddIndex": {
 			c.AddIndex(table.K("I_name")),
 			sqlfrag.Pair( /* language=sqlite */ "CREATE UNIQUE INDEX t_i_name ON t (f_id,f_name);"),
@@ -63,6 +72,14 @@ func TestSqliteDialect(t *testing.T) {
 			c.AddIndex(table.K("PRIMARY")),
 			sqlfrag.Pair( /* language=sqlite */ ""),
 		},
+		"AddPartialIndex": {
+			c.AddIndex(table.K("I_name_active")),
+			sqlfrag.Pair( /* language=sqlite */ "CREATE UNIQUE INDEX t_i_name_active ON t (f_name) WHERE f_created_at > 0;"),
+		},
+		"AddExprIndex": {
+			c.AddIndex(table.K("I_lower_name")),
+			sqlfrag.Pair( /* language=sqlite */ "CREATE INDEX t_i_lower_name ON t ((lower(f_name)) DESC,f_created_at);"),
+		},
 		"DropIndex": {
 			c.DropIndex(table.K("I_name")),
 			sqlfrag.Pair( /* language=sqlite */ "DROP INDEX IF EXISTS t_i_name;"),
@@ -77,3 +94,89 @@ func TestSqliteDialect(t *testing.T) {
 		})
 	}
 }
+
+func TestSqliteDialect_CheckAndGenerated(t *testing.T) {
+	c := &dialect{}
+
+	table := sqlbuilder.T(
+		"t",
+		sqlbuilder.Col("f_price", sqlbuilder.ColTypeOf(int64(0), "")),
+		sqlbuilder.Col("f_total", sqlbuilder.ColDef(sqlbuilder.ColumnDef{
+			Type:      typex.FromRType(reflect.TypeFor[int64]()),
+			Generated: &sqlbuilder.ColumnGenerated{Expr: "(f_price*2)"},
+		})),
+		sqlbuilder.Col("f_tax", sqlbuilder.ColDef(sqlbuilder.ColumnDef{
+			Type:      typex.FromRType(reflect.TypeFor[int64]()),
+			Generated: &sqlbuilder.ColumnGenerated{Expr: "f_price / 10", Stored: true},
+		})),
+		sqlbuilder.CheckConstraint("ck_price", "f_price>=0"),
+	)
+
+	cases := map[string]struct {
+		expr   sqlfrag.Fragment
+		expect sqlfrag.Fragment
+	}{
+		"CreateTableIsNotExists": {
+			c.CreateTableIsNotExists(table)[0],
+			sqlfrag.Pair( /* language=sqlite */ `CREATE TABLE IF NOT EXISTS t (
+	f_price BIGINT NOT NULL,
+	f_total BIGINT GENERATED ALWAYS AS (f_price * 2) VIRTUAL,
+	f_tax BIGINT GENERATED ALWAYS AS (f_price / 10) STORED,
+	CONSTRAINT t_ck_price CHECK (f_price >= 0)
+);`),
+		},
+		"AddCheck": {
+			c.AddCheck(table, sqlbuilder.CheckConstraint("ck_price", "f_price >= 0")),
+			sqlfrag.Pair(""),
+		},
+		"AddStoredGeneratedColumn": {
+			c.AddColumn(table.F("f_tax")),
+			sqlfrag.Pair(""),
+		},
+		"ModifyGeneratedColumn": {
+			c.ModifyColumn(table.F("f_total"), sqlbuilder.Col("f_total", sqlbuilder.ColTypeOf(int64(0), "")).Of(table)),
+			sqlfrag.Pair( /* language=sqlite */ "ALTER TABLE t DROP COLUMN f_total;\nALTER TABLE t ADD COLUMN f_total BIGINT GENERATED ALWAYS AS (f_price * 2) VIRTUAL;"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			q, args := sqlfrag.Collect(context.Background(), c.expect)
+
+			testingx.Expect(t, c.expr, testutil.BeFragment(q, args...))
+		})
+	}
+}
+
+func TestSqliteDialect_Enum(t *testing.T) {
+	c := &dialect{}
+
+	table := sqlbuilder.T(
+		"t",
+		sqlbuilder.Col("f_gender", sqlbuilder.ColTypeOf(model.Gender(0), ",enum,default='0'")),
+	)
+
+	cases := map[string]struct {
+		expr   sqlfrag.Fragment
+		expect sqlfrag.Fragment
+	}{
+		"CreateTableIsNotExists": {
+			c.CreateTableIsNotExists(table)[0],
+			sqlfrag.Pair( /* language=sqlite */ `CREATE TABLE IF NOT EXISTS t (
+	f_gender INTEGER NOT NULL DEFAULT '0' CHECK (f_gender IN ('0','1','2'))
+);`),
+		},
+		"AddColumn": {
+			c.AddColumn(table.F("f_gender")),
+			sqlfrag.Pair( /* language=sqlite */ "ALTER TABLE t ADD COLUMN f_gender INTEGER NOT NULL DEFAULT '0' CHECK (f_gender IN ('0','1','2'));"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			q, args := sqlfrag.Collect(context.Background(), c.expect)
+
+			testingx.Expect(t, c.expr, testutil.BeFragment(q, args...))
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package adapter
+
+import (
+	"context"
+
+	contextx "github.com/octohelm/x/context"
+
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+)
+
+type writeObserverContext struct{}
+
+// ContextWithWriteObserver 注入写入观察者，Exec 或 Query 成功执行写入语句后以其写入的表调用；observe 为 nil 时不再通知。
+func ContextWithWriteObserver(ctx context.Context, observe func(ctx context.Context, tables ...string)) context.Context {
+	return contextx.WithValue(ctx, writeObserverContext{}, observe)
+}
+
+func observeWrite(ctx context.Context, frag sqlfrag.Fragment) {
+	observe, ok := ctx.Value(writeObserverContext{}).(func(ctx context.Context, tables ...string))
+	if !ok || observe == nil {
+		return
+	}
+
+	if t := sqlbuilder.GetStmtTable(frag); t != nil {
+		observe(ctx, t.TableName())
+	}
+}
@@ -28,8 +28,9 @@ type Table struct {
 type Column struct {
 	Head
 
-	Type string `json:"type"`
-	Of   string `json:"of,omitzero"`
+	Type string   `json:"type"`
+	Of   string   `json:"of,omitzero"`
+	Enum []string `json:"enum,omitzero"`
 
 	GoType string `json:"-"`
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"iter"
+	"reflect"
 	"slices"
 	"strings"
 
@@ -23,8 +24,10 @@ func FromCatalog(ctx context.Context, s session.Session, catalog sqlbuilder.Cata
 	}
 
 	c := &collector{
-		s:       s,
-		idTypes: make(map[string]string),
+		s:           s,
+		idTypes:     make(map[string]string),
+		modelTables: make(map[string]sqlbuilder.Table),
+		relations:   make(map[*er.OrderedColumn][]string),
 	}
 
 	for t := range c.tables(ctx, catalog) {
@@ -33,6 +36,11 @@ func FromCatalog(ctx context.Context, s session.Session, catalog sqlbuilder.Cata
 
 	for _, t := range erd.Tables.KeyValues() {
 		for _, col := range t.Columns.KeyValues() {
+			if of, ok := c.relationOf(col); ok {
+				col.Of = of
+				continue
+			}
+
 			if of, ok := c.idTypes[col.GoType]; ok {
 				if !strings.HasPrefix(of, t.Name+".") {
 					col.Of = of
@@ -48,6 +56,29 @@ type collector struct {
 	s session.Session
 
 	idTypes map[string]string
+	// modelTables 按模型类型名索引表，用于解析 `rel:"Model.Field"`
+	modelTables map[string]sqlbuilder.Table
+	relations   map[*er.OrderedColumn][]string
+}
+
+// relationOf 把列声明的关联 {Model}.{Field} 解析为 {table}.{column}。
+func (c *collector) relationOf(col *er.OrderedColumn) (string, bool) {
+	rel, ok := c.relations[col]
+	if !ok || len(rel) < 2 {
+		return "", false
+	}
+
+	t, ok := c.modelTables[rel[len(rel)-2]]
+	if !ok {
+		return "", false
+	}
+
+	refCol := t.F(rel[len(rel)-1])
+	if refCol == nil {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s.%s", t.TableName(), refCol.Name()), true
 }
 
 func (c *collector) tables(ctx context.Context, catalog sqlbuilder.Catalog) iter.Seq[*er.OrderedTable] {
@@ -65,6 +96,8 @@ func (c *collector) tables(ctx context.Context, catalog sqlbuilder.Catalog) iter
 			}
 			m := v.New()
 
+			c.modelTables[reflect.Indirect(reflect.ValueOf(m)).Type().Name()] = table
+
 			for col := range c.columns(ctx, table, m) {
 				t.Columns.Set(col.Name, col)
 			}
@@ -103,6 +136,14 @@ func (c *collector) columns(ctx context.Context, table sqlbuilder.Table, m sqlbu
 
 			c2.Type, _ = sqlfrag.Collect(ctx, c.s.Adapter().Dialect().DataType(def))
 
+			if def.Enum != nil {
+				c2.Enum = def.Enum.Values
+			}
+
+			if len(def.Relation) > 0 {
+				c.relations[c2] = def.Relation
+			}
+
 			c.mayCollectRuntimeDoc(m, &c2.Head, col.FieldName())
 
 			if !yield(c2) {
@@ -10,14 +10,37 @@ import (
 	"github.com/octohelm/storage/pkg/session"
 	sessiondb "github.com/octohelm/storage/pkg/session/db"
 	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
 	"github.com/octohelm/storage/testdata/model"
 )
 
+// Invitation 以 `rel:"User.ID"` 声明邀请人关联，邀请人 ID 不是 ID 类型，只能经关联解析。
+type Invitation struct {
+	ID        uint64      `db:"f_id,autoincrement"`
+	InviterID uint64      `db:"f_inviter_id" rel:"User.ID"`
+	OrgID     model.OrgID `db:"f_org_id"`
+}
+
+func (Invitation) TableName() string {
+	return "t_invitation"
+}
+
+func (Invitation) PrimaryKey() []string {
+	return []string{"ID"}
+}
+
+func (Invitation) ColRelations() map[string][]string {
+	return map[string][]string{
+		"InviterID": {"User", "ID"},
+	}
+}
+
 func TestFromCatalog(t *testing.T) {
 	tables := &sqlbuilder.Tables{}
 	tables.Add(model.UserT)
 	tables.Add(model.OrgT)
 	tables.Add(model.OrgUserT)
+	tables.Add(modelscoped.FromModel[Invitation]())
 
 	db := &sessiondb.Database{
 		EnableMigrate: true,
@@ -42,8 +42,9 @@ type OrderedTable struct {
 type OrderedColumn struct {
 	Head
 
-	Type string `json:"type"`
-	Of   string `json:"of,omitzero"`
+	Type string   `json:"type"`
+	Of   string   `json:"of,omitzero"`
+	Enum []string `json:"enum,omitzero"`
 
 	GoType string `json:"-"`
 }
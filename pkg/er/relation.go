@@ -0,0 +1,84 @@
+package er
+
+import (
+	"iter"
+	"strings"
+)
+
+// Relation 表示由列的 Of 推导出的表间引用关系。
+type Relation struct {
+	// Table 引用方表名
+	Table string
+	// Column 引用方列名
+	Column string
+	// RefTable 被引用表名
+	RefTable string
+	// RefColumn 被引用列名
+	RefColumn string
+	// OneToOne 引用列单独构成唯一约束时为一对一，否则为多对一
+	OneToOne bool
+}
+
+// Relations 按表与列顺序返回全部引用关系；被引用表不在数据库中时忽略。
+func (d *OrderedDatabase) Relations() iter.Seq[*Relation] {
+	return func(yield func(*Relation) bool) {
+		for tableName, t := range d.Tables.KeyValues() {
+			for columnName, col := range t.Columns.KeyValues() {
+				if col.Of == "" {
+					continue
+				}
+
+				refTable, refColumn, ok := strings.Cut(col.Of, ".")
+				if !ok {
+					continue
+				}
+
+				if _, ok := d.Tables.Get(refTable); !ok {
+					continue
+				}
+
+				r := &Relation{
+					Table:     tableName,
+					Column:    columnName,
+					RefTable:  refTable,
+					RefColumn: refColumn,
+					OneToOne:  t.IsUniqueColumn(columnName),
+				}
+
+				if !yield(r) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// IsPrimaryColumn 判断列是否属于主键。
+func (t *OrderedTable) IsPrimaryColumn(columnName string) bool {
+	for _, c := range t.Constraints.KeyValues() {
+		if c.Primary && c.HasColumn(columnName) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUniqueColumn 判断列是否单独构成唯一约束。
+func (t *OrderedTable) IsUniqueColumn(columnName string) bool {
+	for _, c := range t.Constraints.KeyValues() {
+		if c.Unique && len(c.ColumnNames) == 1 && c.ColumnNames[0].Name == columnName {
+			return true
+		}
+	}
+	return false
+}
+
+// HasColumn 判断约束是否包含列。
+func (c *OrderedConstraint) HasColumn(columnName string) bool {
+	for _, cn := range c.ColumnNames {
+		if cn.Name == columnName {
+			return true
+		}
+	}
+	return false
+}
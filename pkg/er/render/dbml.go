@@ -0,0 +1,107 @@
+package render
+
+import (
+	"io"
+	"strings"
+
+	"github.com/octohelm/storage/pkg/er"
+)
+
+// DBML 把 ER 数据库结构渲染为 DBML。
+func DBML(w io.Writer, d *er.OrderedDatabase) error {
+	return write(w, func(b *strings.Builder) {
+		first := true
+
+		for name, t := range d.Tables.KeyValues() {
+			if !first {
+				b.WriteString("\n")
+			}
+			first = false
+
+			b.WriteString("Table " + name + " {\n")
+
+			for _, col := range columns(t) {
+				dataType := col.Type
+				if strings.Contains(dataType, " ") {
+					dataType = `"` + dataType + `"`
+				}
+
+				b.WriteString("  " + col.Name + " " + dataType)
+
+				settings := make([]string, 0, 2)
+				if col.NotNull {
+					settings = append(settings, "not null")
+				}
+				if col.Title != "" {
+					settings = append(settings, "note: "+dbmlString(col.Title))
+				}
+				if len(settings) > 0 {
+					b.WriteString(" [" + strings.Join(settings, ", ") + "]")
+				}
+
+				b.WriteString("\n")
+			}
+
+			if t.Constraints.Len() > 0 {
+				b.WriteString("\n  indexes {\n")
+
+				for constraintName, c := range t.Constraints.KeyValues() {
+					names := make([]string, 0, len(c.ColumnNames))
+					for _, cn := range c.ColumnNames {
+						names = append(names, cn.Name)
+					}
+
+					b.WriteString("    ")
+					if len(names) == 1 {
+						b.WriteString(names[0])
+					} else {
+						b.WriteString("(" + strings.Join(names, ", ") + ")")
+					}
+
+					settings := make([]string, 0, 3)
+					switch {
+					case c.Primary:
+						settings = append(settings, "pk")
+					case c.Unique:
+						settings = append(settings, "unique", "name: "+dbmlString(constraintName))
+					default:
+						settings = append(settings, "name: "+dbmlString(constraintName))
+					}
+					if method := strings.ToLower(c.Method); method != "" {
+						settings = append(settings, "type: "+method)
+					}
+
+					b.WriteString(" [" + strings.Join(settings, ", ") + "]\n")
+				}
+
+				b.WriteString("  }\n")
+			}
+
+			if t.Title != "" {
+				b.WriteString("\n  Note: " + dbmlString(t.Title) + "\n")
+			}
+
+			b.WriteString("}\n")
+		}
+
+		first = true
+
+		for r := range d.Relations() {
+			if first {
+				b.WriteString("\n")
+				first = false
+			}
+
+			op := ">"
+			if r.OneToOne {
+				op = "-"
+			}
+
+			b.WriteString("Ref: " + r.Table + "." + r.Column + " " + op + " " + r.RefTable + "." + r.RefColumn + "\n")
+		}
+	})
+}
+
+func dbmlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
@@ -0,0 +1,83 @@
+package render
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/octohelm/storage/pkg/er"
+)
+
+// Graphviz 把 ER 数据库结构渲染为 Graphviz DOT，每列作为 record 节点的一个端口。
+func Graphviz(w io.Writer, d *er.OrderedDatabase) error {
+	return write(w, func(b *strings.Builder) {
+		name := d.Name
+		if name == "" {
+			name = "er"
+		}
+
+		b.WriteString("digraph " + strconv.Quote(name) + " {\n")
+		b.WriteString("  graph [rankdir=LR];\n")
+		b.WriteString("  node [shape=record];\n")
+		b.WriteString("  edge [dir=both, arrowhead=tee];\n")
+
+		for tableName, t := range d.Tables.KeyValues() {
+			header := tableName
+			if t.Title != "" {
+				header += `\n` + t.Title
+			}
+
+			fields := []string{escapeRecord(header)}
+
+			for _, col := range columns(t) {
+				field := col.Name + " : " + col.Type
+
+				switch {
+				case col.Primary:
+					field += " PK"
+				case col.Unique:
+					field += " UK"
+				}
+
+				if col.Of != "" {
+					field += " FK"
+				}
+
+				fields = append(fields, "<"+col.Name+"> "+escapeRecord(field)+`\l`)
+			}
+
+			b.WriteString("\n  " + tableName + " [label=\"{" + strings.Join(fields, "|") + "}\"];\n")
+		}
+
+		first := true
+
+		for r := range d.Relations() {
+			if first {
+				b.WriteString("\n")
+				first = false
+			}
+
+			arrowtail := "crow"
+			if r.OneToOne {
+				arrowtail = "tee"
+			}
+
+			b.WriteString("  " + r.Table + ":" + r.Column + " -> " + r.RefTable + ":" + r.RefColumn + " [arrowtail=" + arrowtail + "];\n")
+		}
+
+		b.WriteString("}\n")
+	})
+}
+
+var recordEscaper = strings.NewReplacer(
+	`{`, `\{`,
+	`}`, `\}`,
+	`|`, `\|`,
+	`<`, `\<`,
+	`>`, `\>`,
+	`"`, `\"`,
+)
+
+func escapeRecord(s string) string {
+	return recordEscaper.Replace(s)
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/octohelm/storage/pkg/er"
+)
+
+// Mermaid 把 ER 数据库结构渲染为 Mermaid erDiagram。
+func Mermaid(w io.Writer, d *er.OrderedDatabase) error {
+	return write(w, func(b *strings.Builder) {
+		b.WriteString("erDiagram\n")
+
+		for name, t := range d.Tables.KeyValues() {
+			b.WriteString("    " + name + " {\n")
+
+			for _, col := range columns(t) {
+				b.WriteString("        " + mermaidType(col.Type) + " " + col.Name)
+
+				keys := make([]string, 0, 3)
+				if col.Primary {
+					keys = append(keys, "PK")
+				} else if col.Unique {
+					keys = append(keys, "UK")
+				}
+				if col.Of != "" {
+					keys = append(keys, "FK")
+				}
+				if len(keys) > 0 {
+					b.WriteString(" " + strings.Join(keys, ", "))
+				}
+
+				if col.Title != "" {
+					b.WriteString(` "` + strings.ReplaceAll(col.Title, `"`, `'`) + `"`)
+				}
+
+				b.WriteString("\n")
+			}
+
+			b.WriteString("    }\n")
+		}
+
+		for r := range d.Relations() {
+			cardinality := "}o--||"
+			if r.OneToOne {
+				cardinality = "|o--||"
+			}
+
+			b.WriteString("    " + r.Table + " " + cardinality + " " + r.RefTable + ` : "` + r.Column + `"` + "\n")
+		}
+	})
+}
+
+// mermaidType 把数据类型改写为 Mermaid 属性类型：以字母开头，只含字母、数字、-、_、() 与 []，
+// 其余字符替换为 _，如 numeric(10,2) 改写为 numeric(10_2)。
+func mermaidType(typ string) string {
+	t := strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_()[]", r)) {
+			return r
+		}
+		return '_'
+	}, typ)
+
+	if t == "" || !unicode.IsLetter(rune(t[0])) {
+		return "type_" + t
+	}
+	return t
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"io"
+	"strings"
+
+	"github.com/octohelm/storage/pkg/er"
+)
+
+// PlantUML 把 ER 数据库结构渲染为 PlantUML 的 IE 实体图。
+func PlantUML(w io.Writer, d *er.OrderedDatabase) error {
+	return write(w, func(b *strings.Builder) {
+		b.WriteString("@startuml\n")
+		b.WriteString("hide circle\n")
+		b.WriteString("skinparam linetype ortho\n")
+
+		for name, t := range d.Tables.KeyValues() {
+			label := name
+			if t.Title != "" {
+				label += `\n` + t.Title
+			}
+
+			b.WriteString("\nentity \"" + strings.ReplaceAll(label, `"`, `'`) + "\" as " + name + " {\n")
+
+			cols := columns(t)
+
+			// primary key columns above the separator
+			for _, col := range cols {
+				if col.Primary {
+					writePlantUMLColumn(b, col)
+				}
+			}
+
+			b.WriteString("  --\n")
+
+			for _, col := range cols {
+				if !col.Primary {
+					writePlantUMLColumn(b, col)
+				}
+			}
+
+			b.WriteString("}\n")
+		}
+
+		first := true
+
+		for r := range d.Relations() {
+			if first {
+				b.WriteString("\n")
+				first = false
+			}
+
+			cardinality := "}o--||"
+			if r.OneToOne {
+				cardinality = "|o--||"
+			}
+
+			b.WriteString(r.Table + " " + cardinality + " " + r.RefTable + " : " + r.Column + "\n")
+		}
+
+		b.WriteString("@enduml\n")
+	})
+}
+
+func writePlantUMLColumn(b *strings.Builder, col *column) {
+	b.WriteString("  ")
+	if col.NotNull {
+		b.WriteString("* ")
+	}
+
+	b.WriteString(col.Name + " : " + col.Type)
+
+	switch {
+	case col.Primary:
+		b.WriteString(" <<PK>>")
+	case col.Unique:
+		b.WriteString(" <<UK>>")
+	}
+
+	if col.Of != "" {
+		b.WriteString(" <<FK>>")
+	}
+
+	if col.Title != "" {
+		b.WriteString(" -- " + col.Title)
+	}
+
+	b.WriteString("\n")
+}
@@ -0,0 +1,99 @@
+// Package render 把 ER 数据库结构渲染为 Mermaid、DBML、PlantUML 与 Graphviz 图。
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/octohelm/storage/pkg/er"
+)
+
+// Format 表示 ER 图的输出格式。
+type Format string
+
+const (
+	FormatMermaid  Format = "mermaid"
+	FormatDBML     Format = "dbml"
+	FormatPlantUML Format = "plantuml"
+	FormatGraphviz Format = "dot"
+)
+
+// Render 按格式把 ER 数据库结构写入 w。
+func Render(w io.Writer, d *er.OrderedDatabase, format Format) error {
+	switch format {
+	case FormatMermaid:
+		return Mermaid(w, d)
+	case FormatDBML:
+		return DBML(w, d)
+	case FormatPlantUML:
+		return PlantUML(w, d)
+	case FormatGraphviz:
+		return Graphviz(w, d)
+	default:
+		return fmt.Errorf("unsupported er format %q", format)
+	}
+}
+
+func write(w io.Writer, render func(b *strings.Builder)) error {
+	b := &strings.Builder{}
+	render(b)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// column 表示渲染用的列摘要。
+type column struct {
+	Name    string
+	Type    string
+	Title   string
+	NotNull bool
+	Primary bool
+	Unique  bool
+	Of      string
+}
+
+func columns(t *er.OrderedTable) []*column {
+	list := make([]*column, 0, t.Columns.Len())
+
+	for name, col := range t.Columns.KeyValues() {
+		dataType, notNull := splitDataType(col.Type)
+
+		list = append(list, &column{
+			Name:    name,
+			Type:    dataType,
+			Title:   col.Title,
+			NotNull: notNull,
+			Primary: t.IsPrimaryColumn(name),
+			Unique:  t.IsUniqueColumn(name),
+			Of:      col.Of,
+		})
+	}
+
+	return list
+}
+
+var dataTypeSuffixes = []string{
+	" NOT NULL",
+	" NULL",
+	" DEFAULT",
+	" PRIMARY KEY",
+	" AUTOINCREMENT",
+	" GENERATED",
+}
+
+// splitDataType 从完整列定义中取出数据类型，如 "BIGINT NOT NULL DEFAULT '0'" 取出 "BIGINT"。
+func splitDataType(typ string) (string, bool) {
+	upper := strings.ToUpper(typ)
+
+	end := len(typ)
+	for _, suffix := range dataTypeSuffixes {
+		if i := strings.Index(upper, suffix); i > 0 && i < end {
+			end = i
+		}
+	}
+
+	notNull := strings.Contains(upper, " NOT NULL") || strings.Contains(upper, " PRIMARY KEY")
+
+	return strings.TrimSpace(typ[0:end]), notNull
+}
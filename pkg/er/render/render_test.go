@@ -0,0 +1,69 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	testingx "github.com/octohelm/x/testing"
+
+	"github.com/octohelm/storage/pkg/er"
+)
+
+func newDatabase() *er.OrderedDatabase {
+	d := &er.OrderedDatabase{
+		Head: er.Head{Name: "demo"},
+	}
+
+	org := &er.OrderedTable{Head: er.Head{Name: "t_org", Title: "组织"}}
+	org.Columns.Set("f_id", &er.OrderedColumn{Type: "INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL"})
+	org.Columns.Set("f_name", &er.OrderedColumn{Head: er.Head{Title: "名称"}, Type: "character varying(255) NOT NULL DEFAULT ''"})
+	org.Columns.Set("f_budget", &er.OrderedColumn{Type: "numeric(10,2) NOT NULL DEFAULT '0'"})
+	org.Constraints.Set("primary", &er.OrderedConstraint{ColumnNames: []er.ConstraintColumnName{{Name: "f_id"}}, Unique: true, Primary: true})
+	org.Constraints.Set("i_name", &er.OrderedConstraint{ColumnNames: []er.ConstraintColumnName{{Name: "f_name"}}, Unique: true})
+	d.Tables.Set("t_org", org)
+
+	user := &er.OrderedTable{Head: er.Head{Name: "t_user"}}
+	user.Columns.Set("f_id", &er.OrderedColumn{Type: "INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL"})
+	user.Columns.Set("f_nickname", &er.OrderedColumn{Type: "TEXT NULL"})
+	user.Constraints.Set("primary", &er.OrderedConstraint{ColumnNames: []er.ConstraintColumnName{{Name: "f_id"}}, Unique: true, Primary: true})
+	d.Tables.Set("t_user", user)
+
+	orgUser := &er.OrderedTable{Head: er.Head{Name: "t_org_user"}}
+	orgUser.Columns.Set("f_id", &er.OrderedColumn{Type: "INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL"})
+	orgUser.Columns.Set("f_org_id", &er.OrderedColumn{Type: "UNSIGNED BIG INT NOT NULL", Of: "t_org.f_id"})
+	orgUser.Columns.Set("f_user_id", &er.OrderedColumn{Type: "UNSIGNED BIG INT NOT NULL", Of: "t_user.f_id"})
+	orgUser.Columns.Set("f_profile_id", &er.OrderedColumn{Type: "BIGINT NOT NULL", Of: "t_profile.f_id"})
+	orgUser.Constraints.Set("primary", &er.OrderedConstraint{ColumnNames: []er.ConstraintColumnName{{Name: "f_id"}}, Unique: true, Primary: true})
+	orgUser.Constraints.Set("i_user", &er.OrderedConstraint{ColumnNames: []er.ConstraintColumnName{{Name: "f_user_id"}}, Unique: true})
+	orgUser.Constraints.Set("i_org_user", &er.OrderedConstraint{ColumnNames: []er.ConstraintColumnName{{Name: "f_org_id"}, {Name: "f_user_id"}}, Method: "HASH"})
+	d.Tables.Set("t_org_user", orgUser)
+
+	return d
+}
+
+func TestRender(t *testing.T) {
+	d := newDatabase()
+
+	s := testingx.NewSnapshot()
+
+	for _, x := range []struct {
+		format   Format
+		filename string
+	}{
+		{FormatMermaid, "er.mmd"},
+		{FormatDBML, "er.dbml"},
+		{FormatPlantUML, "er.puml"},
+		{FormatGraphviz, "er.dot"},
+	} {
+		b := bytes.NewBuffer(nil)
+		testingx.Expect(t, Render(b, d, x.format), testingx.Be[error](nil))
+		s = s.With(x.filename, b.Bytes())
+	}
+
+	testingx.Expect(t, s, testingx.MatchSnapshot("er"))
+
+	t.Run("unsupported format", func(t *testing.T) {
+		err := Render(bytes.NewBuffer(nil), d, Format("svg"))
+		testingx.Expect(t, err == nil, testingx.Be(false))
+	})
+}
@@ -2,8 +2,11 @@ package filter
 
 import (
 	"bytes"
+	"fmt"
 	"go/ast"
+	"iter"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/go-json-experiment/json"
@@ -13,7 +16,8 @@ import (
 	"github.com/octohelm/storage/pkg/filter/internal/directive"
 )
 
-// Compose 根据结构体中的规则表达式组合查询条件。
+// Compose 根据结构体中的规则表达式组合查询条件；字段标签 `ops` 声明了无法识别的操作符时 panic，
+// 避免笔误的操作符被静默忽略而放宽校验。
 func Compose(filters ...any) *Composed {
 	c := &Composed{}
 
@@ -45,10 +49,16 @@ func Compose(filters ...any) *Composed {
 						}
 					}
 
+					ops, err := opsOf(f.Tag.Get("ops"))
+					if err != nil {
+						panic(fmt.Errorf("invalid ops tag of %s.%s: %w", t, f.Name, err))
+					}
+
 					c.register(name, &fieldRuler{
 						name:        name,
 						tpe:         t,
 						ruleExprIdx: i,
+						ops:         ops,
 					})
 
 					if fv.Kind() == reflect.Pointer && fv.IsNil() {
@@ -68,10 +78,28 @@ func Compose(filters ...any) *Composed {
 	return c
 }
 
+// opsOf 解析字段标签 `ops:"eq,in,prefix"` 声明的允许操作符，存在无法识别的操作符时返回错误。
+func opsOf(tag string) ([]Op, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	ops := make([]Op, 0)
+	for name := range strings.SplitSeq(tag, ",") {
+		op := OP_UNKNOWN
+		if err := op.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil || op == OP_UNKNOWN {
+			return nil, fmt.Errorf("unknown op %q", strings.TrimSpace(name))
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
 type fieldRuler struct {
 	tpe         reflect.Type
 	name        string
 	ruleExprIdx int
+	ops         []Op
 }
 
 func (t *fieldRuler) Name() string {
@@ -128,6 +156,11 @@ func (c Composed) IsZero() bool {
 	return len(c.rules) == 0
 }
 
+// Args 返回组合后的规则，各规则之间为 OR 关系。
+func (c Composed) Args() iter.Seq[Arg] {
+	return slices.Values(c.rules)
+}
+
 func (c *Composed) UnmarshalText(data []byte) error {
 	if len(data) == 0 {
 		return nil
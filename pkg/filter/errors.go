@@ -43,3 +43,29 @@ type ErrUnsupportedQLField struct {
 func (e *ErrUnsupportedQLField) Error() string {
 	return fmt.Sprintf("unsupported ql field `%s`", e.FieldName)
 }
+
+// ErrFilterOpNotAllowed 表示字段不允许使用该过滤操作符。
+type ErrFilterOpNotAllowed struct {
+	errBadRequest
+
+	FieldName string
+	Op        string
+}
+
+func (e *ErrFilterOpNotAllowed) Error() string {
+	return fmt.Sprintf("filter op `%s` is not allowed on field `%s`", e.Op, e.FieldName)
+}
+
+// ErrFilterTooComplex 表示过滤表达式超出复杂度限制。
+type ErrFilterTooComplex struct {
+	errBadRequest
+
+	// Limit 为超出的限制项：depth、values 或 rules
+	Limit  string
+	Max    int
+	Actual int
+}
+
+func (e *ErrFilterTooComplex) Error() string {
+	return fmt.Sprintf("filter too complex: %s %d exceeds limit %d", e.Limit, e.Actual, e.Max)
+}
@@ -48,6 +48,9 @@ func (v Filter[T]) Args() iter.Seq[Arg] {
 }
 
 func (f Filter[T]) IsZero() bool {
+	if f.op == OP__ISNULL || f.op == OP__NOTNULL {
+		return false
+	}
 	return f.op == OP_UNKNOWN || len(f.args) == 0
 }
 
@@ -105,6 +108,15 @@ func (f *Filter[T]) UnmarshalDirective(dec *directive.Decoder) error {
 
 		switch k {
 		case directive.KindValue:
+			if isTimeOp(ff.op) {
+				e, err := ParseTimeExpr(string(text))
+				if err != nil {
+					return err
+				}
+				ff.args = append(ff.args, e)
+				continue
+			}
+
 			l := &lit[T]{}
 			if err := l.UnmarshalJSON(text); err != nil {
 				return err
@@ -44,6 +44,18 @@ func TestComposeAndUtil(t *testing.T) {
 		}, Equal(`or(where("nick",eq("alice")),where("Age",gt(18)))`)),
 	)
 
+	names := make([]string, 0)
+	for arg := range c.Args() {
+		if w, ok := arg.(filter.WhereRule); ok {
+			names = append(names, w.Name())
+		}
+	}
+
+	Then(
+		t, "Args 按 OR 顺序返回各字段的 where 规则",
+		Expect(names, Equal([]string{"nick", "Age"})),
+	)
+
 	items := []filter.Arg{
 		filter.Eq(1),
 		filter.Where("age", filter.Gt(2)),
@@ -108,6 +120,10 @@ func TestFilterConstructorsAndText(t *testing.T) {
 		Expect(filter.Contains("x").Op(), Equal(filter.OP__CONTAINS)),
 		Expect(filter.Prefix("x").Op(), Equal(filter.OP__PREFIX)),
 		Expect(filter.Suffix("x").Op(), Equal(filter.OP__SUFFIX)),
+		Expect(filter.IContains("x").Op(), Equal(filter.OP__ICONTAINS)),
+		Expect(filter.IPrefix("x").Op(), Equal(filter.OP__IPREFIX)),
+		Expect(filter.ISuffix("x").Op(), Equal(filter.OP__ISUFFIX)),
+		Expect(filter.IContains("x").String(), Equal(`icontains("x")`)),
 		Expect(filter.In(1, 2).Op(), Equal(filter.OP__IN)),
 		Expect(filter.Notin(1, 2).Op(), Equal(filter.OP__NOTIN)),
 		Expect(filter.And(filter.Eq(1), filter.Eq(2)).Op(), Equal(filter.OP__AND)),
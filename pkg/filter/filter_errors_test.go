@@ -16,5 +16,8 @@ func TestErrors(t *testing.T) {
 		Expect((&filter.ErrInvalidFilterOp{Op: "x"}).Error(), Equal("invalid filter op `x`")),
 		Expect((&filter.ErrInvalidFilter{Filter: "f"}).Error(), Equal("invalid filter `f`")),
 		Expect((&filter.ErrUnsupportedQLField{FieldName: "name"}).Error(), Equal("unsupported ql field `name`")),
+		Expect((&filter.ErrFilterOpNotAllowed{FieldName: "name", Op: "CONTAINS"}).StatusCode(), Equal(http.StatusBadRequest)),
+		Expect((&filter.ErrFilterOpNotAllowed{FieldName: "name", Op: "CONTAINS"}).Error(), Equal("filter op `CONTAINS` is not allowed on field `name`")),
+		Expect((&filter.ErrFilterTooComplex{Limit: "values", Max: 100, Actual: 101}).Error(), Equal("filter too complex: values 101 exceeds limit 100")),
 	)
 }
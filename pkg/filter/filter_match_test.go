@@ -0,0 +1,207 @@
+package filter_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	filter "github.com/octohelm/storage/pkg/filter"
+	"github.com/octohelm/storage/pkg/sqltype/json"
+	nullable "github.com/octohelm/storage/pkg/sqltype/nullable"
+	sqltypetime "github.com/octohelm/storage/pkg/sqltype/time"
+	"github.com/octohelm/storage/testdata/model"
+)
+
+func TestMatch(t *testing.T) {
+	parse := func(text string) *filter.Filter[int] {
+		f := &filter.Filter[int]{}
+		if err := f.UnmarshalText([]byte(text)); err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	Then(
+		t, "比较与集合操作符",
+		Expect(parse(`gte(18)`).Match(18), Equal(true)),
+		Expect(parse(`gt(18)`).Match(18), Equal(false)),
+		Expect(parse(`or(lt(10),and(gte(18),lte(60)))`).Match(30), Equal(true)),
+		Expect(parse(`or(lt(10),and(gte(18),lte(60)))`).Match(61), Equal(false)),
+		Expect(parse(`in(1,2,3)`).Match(2), Equal(true)),
+		Expect(parse(`notin(1,2,3)`).Match(2), Equal(false)),
+		Expect((&filter.Filter[int]{}).Match(1), Equal(true)),
+	)
+
+	Then(
+		t, "文本匹配按字面值比较，通配符不生效",
+		Expect(filter.Prefix("10%").Match("10% off"), Equal(true)),
+		Expect(filter.Contains("a_c").Match("abc"), Equal(false)),
+		Expect(filter.Contains("Bob").Match("bobby"), Equal(false)),
+		Expect(filter.IContains("Bob").Match("bobby"), Equal(true)),
+	)
+
+	Then(
+		t, "NULL 只满足 isnull，与其比较均不成立",
+		Expect(filter.IsNull[*string]().Match(nil), Equal(true)),
+		Expect(filter.NotNull[*string]().Match(nil), Equal(false)),
+		Expect(filter.Neq[*string](new(string)).Match(nil), Equal(false)),
+		Expect(filter.Neq(nullable.Text("x")).Match(nullable.Text("")), Equal(false)),
+		Expect(filter.IsNull[nullable.Text]().Match(nullable.Text("")), Equal(true)),
+		Expect(filter.Eq(ptr("x")).Match(ptr("x")), Equal(true)),
+	)
+
+	Then(
+		t, "枚举与时间按写入数据库的取值比较",
+		Expect(filter.Lt(model.GENDER__FEMALE).Match(model.GENDER__MALE), Equal(true)),
+		Expect(
+			filter.Day[model.Datetime](filter.Relative(-24*time.Hour)).MatchContext(
+				filter.InjectClock(context.Background(), func() time.Time {
+					return time.Date(2026, 10, 19, 10, 30, 0, 0, model.CST)
+				}),
+				model.Datetime(time.Date(2026, 10, 18, 23, 59, 0, 0, model.CST)),
+			),
+			Equal(true),
+		),
+	)
+
+	Then(
+		t, "以 Unix 时间戳写入数据库的时间类型按时间求值",
+		Expect(
+			filter.Since[sqltypetime.Timestamp](filter.Relative(-time.Hour)).MatchContext(
+				filter.InjectClock(context.Background(), func() time.Time {
+					return time.Date(2026, 10, 19, 10, 30, 0, 0, time.UTC)
+				}),
+				sqltypetime.Timestamp(time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)),
+			),
+			Equal(true),
+		),
+		Expect(
+			filter.Since[sqltypetime.Timestamp](filter.Relative(-time.Hour)).MatchContext(
+				filter.InjectClock(context.Background(), func() time.Time {
+					return time.Date(2026, 10, 19, 10, 30, 0, 0, time.UTC)
+				}),
+				sqltypetime.Timestamp(time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)),
+			),
+			Equal(false),
+		),
+		Expect(
+			filter.Between[*sqltypetime.Timestamp](
+				filter.At(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)),
+				filter.At(time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)),
+			).Match(ptr(sqltypetime.Timestamp(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)))),
+			Equal(false),
+		),
+	)
+}
+
+type userView struct {
+	ID   int64  `name:"user~id"`
+	Name string `name:"user~name"`
+	Tags json.Array[string]
+	Org
+}
+
+type Org struct {
+	Name string `name:"org~name"`
+}
+
+type byUserName struct {
+	Name *filter.Filter[string] `name:"user~name"`
+}
+
+type byOrgName struct {
+	Name *filter.Filter[string] `name:"org~name"`
+}
+
+func TestComposedMatch(t *testing.T) {
+	compose := func(text string) *filter.Composed {
+		c := filter.Compose(byUserName{}, byOrgName{}, struct {
+			ID   *filter.Filter[int64] `name:"user~id"`
+			Tags *filter.Filter[string]
+		}{})
+		if err := c.UnmarshalText([]byte(text)); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	v := &userView{ID: 1, Name: "bob", Tags: json.Array[string]{"a", "b"}, Org: Org{Name: "acme"}}
+
+	Then(
+		t, "按 name 标签解析字段，跨字段规则保留 OR 分组",
+		ExpectMustValue(
+			func() (bool, error) {
+				return compose(`or(where("user~name",eq("alice")),where("org~name",prefix("ac")))`).Match(context.Background(), v)
+			},
+			Equal(true),
+		),
+		ExpectMustValue(
+			func() (bool, error) {
+				return compose(`or(where("user~id",in(2,3)))`).Match(context.Background(), v)
+			},
+			Equal(false),
+		),
+	)
+
+	Then(
+		t, "集合字段按包含语义求值",
+		ExpectMustValue(
+			func() (bool, error) {
+				return compose(`or(where("Tags",containsall("a","b")))`).Match(context.Background(), v)
+			},
+			Equal(true),
+		),
+		ExpectMustValue(
+			func() (bool, error) {
+				return compose(`or(where("Tags",notin("b","c")))`).Match(context.Background(), v)
+			},
+			Equal(false),
+		),
+	)
+
+	Then(
+		t, "集合字段为空时 neq、notin 不成立，与 SQL 一致",
+		ExpectMustValue(
+			func() (bool, error) {
+				return compose(`or(where("Tags",neq("a")))`).Match(context.Background(), &userView{})
+			},
+			Equal(false),
+		),
+		ExpectMustValue(
+			func() (bool, error) {
+				return compose(`or(where("Tags",notin("a")))`).Match(context.Background(), &userView{Tags: json.Array[string]{}})
+			},
+			Equal(false),
+		),
+		ExpectMustValue(
+			func() (bool, error) {
+				return compose(`or(where("Tags",neq("c")))`).Match(context.Background(), v)
+			},
+			Equal(true),
+		),
+	)
+
+	Then(
+		t, "结构体中不存在的字段返回错误",
+		ExpectDo(
+			func() error {
+				c := filter.Compose(struct {
+					Age *filter.Filter[int] `name:"user~age"`
+				}{})
+				if err := c.UnmarshalText([]byte(`or(where("user~age",gt(1)))`)); err != nil {
+					return err
+				}
+				_, err := c.Match(context.Background(), v)
+				return err
+			},
+			ErrorMatch(regexp.MustCompile("unsupported ql field `user~age`")),
+		),
+	)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
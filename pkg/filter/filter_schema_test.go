@@ -0,0 +1,87 @@
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/go-json-experiment/json"
+	. "github.com/octohelm/x/testing/v2"
+
+	filter "github.com/octohelm/storage/pkg/filter"
+	"github.com/octohelm/storage/testdata/model"
+	modelfilter "github.com/octohelm/storage/testdata/model/filter"
+)
+
+type byGender struct {
+	Gender *filter.Filter[model.Gender] `name:"user~gender"`
+}
+
+type byCreatedAt struct {
+	CreatedAt *filter.Filter[model.Datetime] `name:"user~createdAt" ops:"since,before"`
+}
+
+type byNickname struct {
+	Nickname *filter.Filter[*string] `name:"user~nickname"`
+}
+
+func TestSchema(t *testing.T) {
+	Then(
+		t, "按取值类型推断操作符与示例",
+		Expect(filter.SchemaOf[int64]("user~age", filter.OP__GTE, filter.OP__IN), Equal(filter.FieldSchema{
+			Name:   "user~age",
+			Type:   "integer",
+			Format: "int64",
+			Ops: []filter.OpSchema{
+				{Name: "gte", Arity: 1, Example: "gte(1)"},
+				{Name: "in", Arity: -1, Example: "in(1,1)"},
+			},
+		})),
+	)
+
+	schemas := filter.Compose(byNickname{}, byGender{}, byCreatedAt{}).Schema()
+
+	Then(
+		t, "组合中的字段按名称排序",
+		Expect(len(schemas), Equal(3)),
+		Expect(schemas[0].Name, Equal("user~createdAt")),
+		Expect(schemas[1].Name, Equal("user~gender")),
+		Expect(schemas[2].Name, Equal("user~nickname")),
+	)
+
+	Then(
+		t, "字段标签声明的操作符优先，时间操作符以时间表达式为参数",
+		Expect(schemas[0].Format, Equal("date-time")),
+		Expect(schemas[0].Ops, Equal([]filter.OpSchema{
+			{Name: "since", Arity: 1, TimeExpr: true, Example: "since(-7d)"},
+			{Name: "before", Arity: 1, TimeExpr: true, Example: "before(now)"},
+		})),
+	)
+
+	Then(
+		t, "枚举字段带出可选值与显示名",
+		Expect(schemas[1].Type, Equal("string")),
+		Expect(schemas[1].Enum[0], Equal(filter.EnumValueSchema{Value: "MALE", Label: "男"})),
+		Expect(schemas[1].Ops[0], Equal(filter.OpSchema{Name: "eq", Arity: 1, Example: `eq("MALE")`})),
+	)
+
+	Then(
+		t, "指针字段可用空值操作符",
+		Expect(schemas[2].Ops[len(schemas[2].Ops)-1], Equal(filter.OpSchema{Name: "notnull", Arity: 0, Example: "notnull()"})),
+	)
+
+	Then(
+		t, "生成的 ops 标签输出为结构化 JSON",
+		ExpectMustValue(func() (string, error) {
+			raw, err := json.Marshal(filter.Compose(modelfilter.UserByAge{}).Schema())
+			return string(raw), err
+		}, Equal(`[{"name":"user~age","type":"integer","format":"int64","ops":[`+
+			`{"name":"eq","arity":1,"example":"eq(1)"},`+
+			`{"name":"neq","arity":1,"example":"neq(1)"},`+
+			`{"name":"in","arity":-1,"example":"in(1,1)"},`+
+			`{"name":"notin","arity":-1,"example":"notin(1,1)"},`+
+			`{"name":"gt","arity":1,"example":"gt(1)"},`+
+			`{"name":"gte","arity":1,"example":"gte(1)"},`+
+			`{"name":"lt","arity":1,"example":"lt(1)"},`+
+			`{"name":"lte","arity":1,"example":"lte(1)"}`+
+			`]}]`)),
+	)
+}
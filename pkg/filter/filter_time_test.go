@@ -0,0 +1,78 @@
+package filter_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	filter "github.com/octohelm/storage/pkg/filter"
+	"github.com/octohelm/storage/testdata/model"
+)
+
+func TestTimeFilter(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 30, 0, 0, model.CST)
+
+	Then(
+		t, "时间操作符可经指令编码往返",
+		ExpectMustValue(
+			func() (string, error) {
+				f := &filter.Filter[model.Datetime]{}
+				if err := f.UnmarshalText([]byte(`or(between(2026-10-01,"2026-10-18T08:00:00"),since(now-7d),before(now),day(-1d))`)); err != nil {
+					return "", err
+				}
+				return f.String(), nil
+			},
+			Equal(`or(between(2026-10-01,2026-10-18T08:00:00),since(-7d),before(now),day(-1d))`),
+		),
+		ExpectMustValue(
+			func() (string, error) {
+				raw, err := filter.Or[model.Datetime](
+					filter.Since[model.Datetime](filter.Relative(-36*time.Hour)),
+					filter.Between[model.Datetime](filter.Date(2026, 10, 1), filter.At(time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC))),
+				).MarshalText()
+				return string(raw), err
+			},
+			Equal(`or(since(-36h),between(2026-10-01,2026-10-18T08:00:00Z))`),
+		),
+	)
+
+	Then(
+		t, "非法的时间表达式返回错误",
+		ExpectDo(
+			func() error {
+				return (&filter.Filter[model.Datetime]{}).UnmarshalText([]byte(`since(7x)`))
+			},
+			ErrorMatch(regexp.MustCompile("invalid time expr `7x`")),
+		),
+	)
+
+	Then(
+		t, "区间按时钟所在时区解析",
+		ExpectMustValue(
+			func() ([]time.Time, error) {
+				from, to, _ := filter.TimeRange(filter.Day[model.Datetime](filter.Relative(-24*time.Hour)), now)
+				return []time.Time{from, to}, nil
+			},
+			Equal([]time.Time{
+				time.Date(2026, 10, 18, 0, 0, 0, 0, model.CST),
+				time.Date(2026, 10, 19, 0, 0, 0, 0, model.CST),
+			}),
+		),
+		ExpectMustValue(
+			func() ([]time.Time, error) {
+				from, to, _ := filter.TimeRange(filter.Since[model.Datetime](filter.Now()), now)
+				return []time.Time{from, to}, nil
+			},
+			Equal([]time.Time{now, {}}),
+		),
+	)
+
+	Then(
+		t, "未注入时钟时使用当前时间",
+		Expect(filter.ClockFromContext(context.Background())().IsZero(), Equal(false)),
+		Expect(filter.ClockFromContext(filter.InjectClock(context.Background(), func() time.Time { return now }))(), Equal(now)),
+	)
+}
@@ -0,0 +1,118 @@
+package filter_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	filter "github.com/octohelm/storage/pkg/filter"
+)
+
+type byName struct {
+	Name *filter.Filter[string] `name:"name" ops:"eq,in,prefix"`
+}
+
+type byTag struct {
+	Tag *filter.Filter[string] `name:"tag"`
+}
+
+func TestValidate(t *testing.T) {
+	Then(
+		t, "未超出限制时通过校验",
+		ExpectDo(func() error {
+			return filter.Validate(
+				filter.And(filter.Eq("a"), filter.Or(filter.In("b", "c"), filter.Prefix("d"))),
+				filter.MaxDepth(3), filter.MaxValues(2), filter.MaxRules(3),
+			)
+		}),
+	)
+
+	Then(
+		t, "嵌套层数超出限制",
+		Expect(
+			errorOf[*filter.ErrFilterTooComplex](filter.Validate(
+				filter.And(filter.Or(filter.Eq("a"), filter.Eq("b"))),
+				filter.MaxDepth(2),
+			)),
+			Equal(&filter.ErrFilterTooComplex{Limit: "depth", Max: 2, Actual: 3}),
+		),
+	)
+
+	Then(
+		t, "in 取值个数超出限制",
+		Expect(
+			errorOf[*filter.ErrFilterTooComplex](filter.Validate(filter.In(1, 2, 3), filter.MaxValues(2))),
+			Equal(&filter.ErrFilterTooComplex{Limit: "values", Max: 2, Actual: 3}),
+		),
+	)
+
+	Then(
+		t, "规则总数超出限制",
+		Expect(
+			errorOf[*filter.ErrFilterTooComplex](filter.Validate(
+				filter.Or(filter.Eq(1), filter.Eq(2), filter.Eq(3)),
+				filter.MaxRules(2),
+			)),
+			Equal(&filter.ErrFilterTooComplex{Limit: "rules", Max: 2, Actual: 3}),
+		),
+	)
+
+	Then(
+		t, "where 按字段名匹配允许的操作符",
+		Expect(
+			errorOf[*filter.ErrFilterOpNotAllowed](filter.Validate(
+				filter.Where("tag", filter.Contains("x")),
+				filter.AllowOps("tag", filter.OP__EQ, filter.OP__IN),
+			)),
+			Equal(&filter.ErrFilterOpNotAllowed{FieldName: "tag", Op: "CONTAINS"}),
+		),
+	)
+}
+
+func TestComposedValidate(t *testing.T) {
+	c := filter.Compose(byName{}, byTag{})
+
+	Then(
+		t, "字段标签声明的操作符之外返回错误",
+		ExpectDo(func() error {
+			return c.UnmarshalText([]byte(`or(where("name",contains("a")),where("tag",contains("b")))`))
+		}),
+		Expect(
+			errorOf[*filter.ErrFilterOpNotAllowed](c.Validate()),
+			Equal(&filter.ErrFilterOpNotAllowed{FieldName: "name", Op: "CONTAINS"}),
+		),
+	)
+
+	Then(
+		t, "AllowOps 可覆盖字段标签，规则总数跨字段累计",
+		ExpectDo(func() error {
+			return c.Validate(filter.AllowOps("name", filter.OP__CONTAINS))
+		}),
+		Expect(
+			errorOf[*filter.ErrFilterTooComplex](c.Validate(filter.AllowOps("name", filter.OP__CONTAINS), filter.MaxRules(1))),
+			Equal(&filter.ErrFilterTooComplex{Limit: "rules", Max: 1, Actual: 2}),
+		),
+	)
+}
+
+func TestComposeWithUnknownOps(t *testing.T) {
+	type byNickname struct {
+		Nickname *filter.Filter[string] `name:"nickname" ops:"eq,startswith"`
+	}
+
+	Then(
+		t, "字段标签声明了无法识别的操作符时 Compose panic",
+		ExpectMustValue(func() (panicked bool, err error) {
+			defer func() { panicked = recover() != nil }()
+			_ = filter.Compose(byNickname{})
+			return panicked, nil
+		}, Equal(true)),
+	)
+}
+
+func errorOf[E error](err error) E {
+	var e E
+	errors.As(err, &e)
+	return e
+}
@@ -41,7 +41,8 @@ type Decoder struct {
 	kind Kind
 	text []byte
 
-	tmp bytes.Buffer
+	tmp   bytes.Buffer
+	value bytes.Buffer
 }
 
 // Newer 定义生成 Unmarshaler 的工厂函数。
@@ -72,7 +73,10 @@ func (d *Decoder) Unmarshaler(name string) (Unmarshaler, error) {
 
 func (d *Decoder) Reset(r io.Reader) {
 	d.s.Init(r)
+	// 裸值如 2026-10-08 会被当作非法数字字面量报错，其原文仍按 token 拼接，无需输出错误
+	d.s.Error = func(s *scanner.Scanner, msg string) {}
 	d.tmp.Reset()
+	d.value.Reset()
 }
 
 func (d *Decoder) DirectiveName() (string, error) {
@@ -98,23 +102,37 @@ func (d *Decoder) Next() (Kind, []byte) {
 		return EOF, nil
 	case ')':
 		return KindFuncEnd, d.textToken()
-	case ',':
+	case ',', '(':
 		return d.Next()
 	default:
-		tokenText := d.s.TokenText()
+		// 相邻 token 拼接为同一个值，以支持 -7d、2026-10-18 这类裸值
+		d.value.WriteString(d.s.TokenText())
+		d.skipWhitespace()
 
 		switch d.s.Peek() {
 		case '(':
-			d.directiveName = tokenText
+			d.directiveName = d.valueToken()
 
-			return KindFuncStart, []byte(tokenText)
+			return KindFuncStart, []byte(d.directiveName)
 		case ',', ')':
-			return KindValue, []byte(tokenText)
+			return KindValue, []byte(d.valueToken())
 		}
 	}
 	return d.Next()
 }
 
+func (d *Decoder) valueToken() string {
+	v := d.value.String()
+	d.value.Reset()
+	return v
+}
+
+func (d *Decoder) skipWhitespace() {
+	for ch := d.s.Peek(); ch >= 0 && ch < 64 && d.s.Whitespace&(1<<uint(ch)) != 0; ch = d.s.Peek() {
+		d.s.Next()
+	}
+}
+
 // Kind 表示 directive token 类型。
 type Kind int
 
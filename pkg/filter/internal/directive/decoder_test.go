@@ -20,3 +20,17 @@ func TestNewDecoder(t *testing.T) {
 	v, _ := fn.MarshalDirective()
 	testingx.Expect(t, string(v), testingx.Be(`fn("1",eq(1))`))
 }
+
+func TestDecoderBareValue(t *testing.T) {
+	d := NewDecoder(bytes.NewBufferString(`between(2026-10-08, -7d,now)`))
+	d.RegisterDirectiveNewer(DefaultDirectiveNewer, func() Unmarshaler {
+		return &Directive{}
+	})
+
+	fn := &Directive{}
+	err := fn.UnmarshalDirective(d)
+	testingx.Expect(t, err, testingx.BeNil[error]())
+
+	v, _ := fn.MarshalDirective()
+	testingx.Expect(t, string(v), testingx.Be(`between(2026-10-08,-7d,now)`))
+}
@@ -0,0 +1,514 @@
+package filter
+
+import (
+	"cmp"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"go/ast"
+	"iter"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Match 判断值是否满足过滤规则，语义与 sqlpipe/filter 翻译出的 SQL 条件一致；时间操作符以 time.Now 为当前时间。
+//
+// NULL（nil 指针或 driver.Valuer 返回 nil）只满足 isnull()，与其比较的其他规则（含 neq、notin）均不成立；
+// 集合值为 nil 或空时同样不满足 neq、notin，与 SQL 中集合列为 NULL 或空数组时的取反条件一致。
+// 文本匹配按字面值比较且区分大小写，与 PostgreSQL 的 LIKE 一致，i 前缀的操作符不区分大小写；
+// SQLite 的 LIKE 默认对 ASCII 字母不区分大小写，此时 prefix、suffix、contains、notcontains 的结果可能与数据库不一致。
+func (f Filter[T]) Match(v T) bool {
+	return f.MatchContext(context.Background(), v)
+}
+
+// MatchContext 同 Match，时间操作符以 InjectClock 注入的时钟为当前时间。
+func (f Filter[T]) MatchContext(ctx context.Context, v T) bool {
+	return matchRule(&f, v, ClockFromContext(ctx)())
+}
+
+func (f Filter[T]) values() []any {
+	values := make([]any, 0, len(f.args))
+	for _, arg := range f.args {
+		if x, ok := arg.(Value[T]); ok {
+			values = append(values, x.Value())
+		}
+	}
+	return values
+}
+
+// Match 判断结构体值是否满足组合后的规则，规则中的字段名按结构体字段的 `name` 标签（缺省为字段名）解析，
+// 匿名嵌入结构体的字段一并参与解析；规则引用结构体中不存在的字段时返回 ErrUnsupportedQLField。
+func (c *Composed) Match(ctx context.Context, v any) (bool, error) {
+	if c == nil || c.IsZero() {
+		return true, nil
+	}
+
+	fields := map[string]any{}
+	collectFieldValues(fields, reflect.ValueOf(v))
+
+	return matchRules(fields, OP__OR, c.Args(), ClockFromContext(ctx)())
+}
+
+func collectFieldValues(fields map[string]any, rv reflect.Value) {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+
+	embedded := make([]reflect.Value, 0)
+
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+
+		tagName, hasTag := f.Tag.Lookup("name")
+
+		if f.Anonymous && !hasTag {
+			embedded = append(embedded, rv.Field(i))
+			continue
+		}
+
+		if !ast.IsExported(f.Name) || !rv.Field(i).CanInterface() {
+			continue
+		}
+
+		name := f.Name
+		if n := strings.SplitN(tagName, ",", 2)[0]; n != "" {
+			name = n
+		}
+
+		fields[name] = rv.Field(i).Interface()
+	}
+
+	// 外层字段优先于嵌入结构体中的同名字段
+	for _, e := range embedded {
+		sub := map[string]any{}
+		collectFieldValues(sub, e)
+		for name, v := range sub {
+			if _, ok := fields[name]; !ok {
+				fields[name] = v
+			}
+		}
+	}
+}
+
+func matchRules(fields map[string]any, op Op, args iter.Seq[Arg], now time.Time) (bool, error) {
+	applied := false
+
+	for arg := range args {
+		matched, err := matchArg(fields, arg, now)
+		if err != nil {
+			return false, err
+		}
+
+		applied = true
+
+		if op == OP__AND && !matched {
+			return false, nil
+		}
+		if op == OP__OR && matched {
+			return true, nil
+		}
+	}
+
+	return op == OP__AND || !applied, nil
+}
+
+func matchArg(fields map[string]any, arg Arg, now time.Time) (bool, error) {
+	switch x := arg.(type) {
+	case WhereRule:
+		v, ok := fields[x.Name()]
+		if !ok {
+			return false, &ErrUnsupportedQLField{FieldName: x.Name()}
+		}
+		return matchRule(x, v, now), nil
+	case Rule:
+		switch op := x.Op(); op {
+		case OP__AND, OP__OR:
+			return matchRules(fields, op, x.Args(), now)
+		default:
+			return false, &ErrInvalidFilterOp{Op: op.String()}
+		}
+	default:
+		return false, &ErrInvalidFilter{Filter: fmt.Sprintf("%T", arg)}
+	}
+}
+
+// matchRule 按规则树求值；空规则不产生 SQL 条件，视为满足。
+func matchRule(rule Rule, v any, now time.Time) bool {
+	if rule.IsZero() {
+		return true
+	}
+
+	switch op := rule.Op(); op {
+	case OP__AND, OP__INTERSECTION, OP__WHERE:
+		for sub := range subRulesOf(rule) {
+			if !matchRule(sub, v, now) {
+				return false
+			}
+		}
+		return true
+	case OP__OR:
+		applied := false
+		for sub := range subRulesOf(rule) {
+			if matchRule(sub, v, now) {
+				return true
+			}
+			applied = true
+		}
+		return !applied
+	default:
+		var values []any
+		if x, ok := rule.(interface{ values() []any }); ok {
+			values = x.values()
+		}
+
+		if elems, ok := elementsOf(v); ok {
+			return matchElements(op, elems, values)
+		}
+
+		return matchValue(rule, v, values, now)
+	}
+}
+
+func subRulesOf(rule Rule) iter.Seq[Rule] {
+	return func(yield func(Rule) bool) {
+		for arg := range rule.Args() {
+			if sub, ok := arg.(Rule); ok && !sub.IsZero() {
+				if !yield(sub) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func matchValue(rule Rule, v any, values []any, now time.Time) bool {
+	op := rule.Op()
+
+	value, notNull := sqlValueOf(v)
+
+	switch op {
+	case OP__ISNULL:
+		return !notNull
+	case OP__NOTNULL:
+		return notNull
+	default:
+	}
+
+	if !notNull {
+		return false
+	}
+
+	switch op {
+	case OP__EQ:
+		return len(values) > 0 && equal(value, values[0])
+	case OP__NEQ:
+		return len(values) > 0 && isNotNull(values[0]) && !equal(value, values[0])
+	case OP__GT, OP__GTE, OP__LT, OP__LTE:
+		if len(values) == 0 {
+			return true
+		}
+		b, _ := sqlValueOf(values[0])
+		c, ok := compare(value, b)
+		if !ok {
+			return false
+		}
+		switch op {
+		case OP__GT:
+			return c > 0
+		case OP__GTE:
+			return c >= 0
+		case OP__LT:
+			return c < 0
+		default:
+			return c <= 0
+		}
+	case OP__IN, OP__CONTAINSANY:
+		for _, x := range values {
+			if equal(value, x) {
+				return true
+			}
+		}
+		return false
+	case OP__NOTIN:
+		for _, x := range values {
+			if !isNotNull(x) || equal(value, x) {
+				return false
+			}
+		}
+		return true
+	case OP__CONTAINSALL:
+		for _, x := range values {
+			if !equal(value, x) {
+				return false
+			}
+		}
+		return true
+	case OP__PREFIX, OP__SUFFIX, OP__CONTAINS, OP__NOTCONTAINS,
+		OP__IPREFIX, OP__ISUFFIX, OP__ICONTAINS, OP__NOTICONTAINS:
+		if len(values) == 0 || !isNotNull(values[0]) {
+			return false
+		}
+		return like(op, textOf(value), textOf(values[0]))
+	case OP__BETWEEN, OP__SINCE, OP__BEFORE, OP__DAY:
+		t, ok := timeOf(v)
+		if !ok {
+			// 非时间取值不产生 SQL 条件
+			return true
+		}
+		from, to, ok := TimeRange(rule, now)
+		if !ok {
+			return true
+		}
+		return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+	default:
+		return true
+	}
+}
+
+// matchElements 按集合列语义求值：eq/containsall 要求包含全部取值，in/containsany 要求包含任一取值，
+// neq/notin 取反且要求集合非空；其他操作符不产生 SQL 条件，视为满足。
+func matchElements(op Op, elems []any, values []any) bool {
+	contains := func(x any) bool {
+		for _, e := range elems {
+			if equal(e, x) {
+				return true
+			}
+		}
+		return false
+	}
+
+	all := func() bool {
+		for _, x := range values {
+			if !contains(x) {
+				return false
+			}
+		}
+		return true
+	}
+
+	anyOf := func() bool {
+		for _, x := range values {
+			if contains(x) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch op {
+	case OP__EQ, OP__CONTAINSALL:
+		return all()
+	case OP__IN, OP__CONTAINSANY:
+		return anyOf()
+	case OP__NEQ:
+		return len(elems) > 0 && !all()
+	case OP__NOTIN:
+		return len(elems) > 0 && !anyOf()
+	default:
+		return true
+	}
+}
+
+func elementsOf(v any) ([]any, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+
+	elems := make([]any, rv.Len())
+	for i := range elems {
+		elems[i] = rv.Index(i).Interface()
+	}
+	return elems, true
+}
+
+func like(op Op, text string, pattern string) bool {
+	switch op {
+	case OP__IPREFIX, OP__ISUFFIX, OP__ICONTAINS, OP__NOTICONTAINS:
+		text, pattern = strings.ToLower(text), strings.ToLower(pattern)
+	default:
+	}
+
+	switch op {
+	case OP__PREFIX, OP__IPREFIX:
+		return strings.HasPrefix(text, pattern)
+	case OP__SUFFIX, OP__ISUFFIX:
+		return strings.HasSuffix(text, pattern)
+	case OP__NOTCONTAINS, OP__NOTICONTAINS:
+		return !strings.Contains(text, pattern)
+	default:
+		return strings.Contains(text, pattern)
+	}
+}
+
+func isNotNull(v any) bool {
+	_, notNull := sqlValueOf(v)
+	return notNull
+}
+
+func equal(a any, b any) bool {
+	a, aNotNull := sqlValueOf(a)
+	b, bNotNull := sqlValueOf(b)
+	if !aNotNull || !bNotNull {
+		return false
+	}
+	if c, ok := compare(a, b); ok {
+		return c == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func compare(a any, b any) (int, bool) {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return cmp.Compare(x, y), true
+		case float64:
+			return cmp.Compare(float64(x), y), true
+		}
+	case float64:
+		switch y := b.(type) {
+		case int64:
+			return cmp.Compare(x, float64(y)), true
+		case float64:
+			return cmp.Compare(x, y), true
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return cmp.Compare(x, y), true
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0, true
+			case y:
+				return -1, true
+			default:
+				return 1, true
+			}
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), true
+		}
+	}
+	return 0, false
+}
+
+// sqlValueOf 把值归一为写入数据库时的取值（driver.Valuer 优先），并统一数值、文本与时间的类型；
+// nil 指针或 driver.Valuer 返回 nil 时视为 NULL，返回 false。
+func sqlValueOf(v any) (any, bool) {
+	switch v.(type) {
+	case int64, float64, string, bool, time.Time:
+		return v, true
+	}
+
+	rv := reflect.ValueOf(v)
+
+	for {
+		if !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+			return nil, false
+		}
+
+		if valuer, ok := rv.Interface().(driver.Valuer); ok {
+			dv, err := valuer.Value()
+			if err != nil || dv == nil {
+				return nil, false
+			}
+			rv = reflect.ValueOf(dv)
+			break
+		}
+
+		if rv.Kind() != reflect.Pointer {
+			break
+		}
+
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.String:
+		return rv.String(), true
+	case reflect.Bool:
+		return rv.Bool(), true
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(rv.Bytes()), true
+		}
+	case reflect.Struct:
+		if rv.Type().ConvertibleTo(typeTime) {
+			return rv.Convert(typeTime).Interface(), true
+		}
+	default:
+	}
+
+	return rv.Interface(), true
+}
+
+// timeOf 从原始取值解析时间，在 driver.Valuer 归一之前进行，避免以 Unix 时间戳写入数据库的时间类型（如 sqltype/time.Timestamp）被当作数值；
+// 支持可转换为 time.Time 的类型与实现 Unix() 的类型。
+func timeOf(v any) (time.Time, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return time.Time{}, false
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() {
+		return time.Time{}, false
+	}
+
+	if rv.Kind() == reflect.Struct && rv.Type().ConvertibleTo(typeTime) {
+		return rv.Convert(typeTime).Interface().(time.Time), true
+	}
+
+	if x, ok := rv.Interface().(interface{ Unix() int64 }); ok {
+		return time.Unix(x.Unix(), 0), true
+	}
+
+	t, ok := v.(time.Time)
+	return t, ok
+}
+
+func textOf(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return ""
+	}
+	return fmt.Sprintf("%v", rv.Interface())
+}
@@ -36,6 +36,22 @@ const (
 	OP__OR
 
 	OP__INTERSECTION
+
+	OP__CONTAINSALL
+	OP__CONTAINSANY
+
+	OP__NOTICONTAINS
+	OP__ICONTAINS
+	OP__IPREFIX
+	OP__ISUFFIX
+
+	OP__BETWEEN
+	OP__SINCE
+	OP__BEFORE
+	OP__DAY
+
+	OP__ISNULL
+	OP__NOTNULL
 )
 
 // Eq 构造等于指定值的过滤条件。
@@ -108,6 +124,46 @@ func Suffix[T comparable](v T) *Filter[T] {
 	}
 }
 
+// IContains 构造不区分大小写包含指定值的过滤条件。
+func IContains[T comparable](v T) *Filter[T] {
+	return &Filter[T]{
+		op: OP__ICONTAINS,
+		args: []Arg{
+			Lit(v),
+		},
+	}
+}
+
+// IPrefix 构造不区分大小写前缀匹配的过滤条件。
+func IPrefix[T comparable](v T) *Filter[T] {
+	return &Filter[T]{
+		op: OP__IPREFIX,
+		args: []Arg{
+			Lit(v),
+		},
+	}
+}
+
+// ISuffix 构造不区分大小写后缀匹配的过滤条件。
+func ISuffix[T comparable](v T) *Filter[T] {
+	return &Filter[T]{
+		op: OP__ISUFFIX,
+		args: []Arg{
+			Lit(v),
+		},
+	}
+}
+
+// NotIContains 构造不区分大小写不包含指定值的过滤条件。
+func NotIContains[T comparable](v T) *Filter[T] {
+	return &Filter[T]{
+		op: OP__NOTICONTAINS,
+		args: []Arg{
+			Lit(v),
+		},
+	}
+}
+
 // In 构造值属于给定集合的过滤条件。
 func In[T comparable](values ...T) *Filter[T] {
 	return &Filter[T]{
@@ -188,7 +244,27 @@ func Or[T comparable](filters ...TypedRule[T]) *Filter[T] {
 	}
 }
 
-// Intersection 构造多个条件的交集组合。
+// ContainsAll 构造集合列包含全部给定值的过滤条件。
+func ContainsAll[T comparable](values ...T) *Filter[T] {
+	return &Filter[T]{
+		op: OP__CONTAINSALL,
+		args: slicesx.Map(values, func(e T) Arg {
+			return Lit(e)
+		}),
+	}
+}
+
+// ContainsAny 构造集合列包含任一给定值的过滤条件。
+func ContainsAny[T comparable](values ...T) *Filter[T] {
+	return &Filter[T]{
+		op: OP__CONTAINSANY,
+		args: slicesx.Map(values, func(e T) Arg {
+			return Lit(e)
+		}),
+	}
+}
+
+// Intersection 构造多个条件的交集组合，即同时满足全部子条件。
 func Intersection[T comparable](filters ...TypedRule[T]) *Filter[T] {
 	return &Filter[T]{
 		op: OP__INTERSECTION,
@@ -198,6 +274,52 @@ func Intersection[T comparable](filters ...TypedRule[T]) *Filter[T] {
 	}
 }
 
+// Between 构造时间落在 [from, to) 区间的过滤条件，日期形式的上界包含当天。
+func Between[T comparable](from, to TimeExpr) *Filter[T] {
+	return &Filter[T]{
+		op:   OP__BETWEEN,
+		args: []Arg{from, to},
+	}
+}
+
+// Since 构造时间不早于指定时刻的过滤条件。
+func Since[T comparable](v TimeExpr) *Filter[T] {
+	return &Filter[T]{
+		op:   OP__SINCE,
+		args: []Arg{v},
+	}
+}
+
+// Before 构造时间早于指定时刻的过滤条件。
+func Before[T comparable](v TimeExpr) *Filter[T] {
+	return &Filter[T]{
+		op:   OP__BEFORE,
+		args: []Arg{v},
+	}
+}
+
+// Day 构造时间落在指定时刻所在自然日的过滤条件。
+func Day[T comparable](v TimeExpr) *Filter[T] {
+	return &Filter[T]{
+		op:   OP__DAY,
+		args: []Arg{v},
+	}
+}
+
+// IsNull 构造值为 NULL 的过滤条件。
+func IsNull[T comparable]() *Filter[T] {
+	return &Filter[T]{
+		op: OP__ISNULL,
+	}
+}
+
+// NotNull 构造值不为 NULL 的过滤条件。
+func NotNull[T comparable]() *Filter[T] {
+	return &Filter[T]{
+		op: OP__NOTNULL,
+	}
+}
+
 // OrRules 用 Rule 构造或组合过滤条件。
 func OrRules(rules ...Rule) Rule {
 	return &Filter[any]{
@@ -36,6 +36,13 @@ type Rule interface {
 	directive.Unmarshaler
 }
 
+// WhereRule 表示约束在指定字段上的 where 规则。
+type WhereRule interface {
+	Rule
+
+	Name() string
+}
+
 // RuleExpr 表示可按字段名展开为 Rule 的规则表达式。
 type RuleExpr interface {
 	IsZero() bool
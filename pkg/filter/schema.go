@@ -0,0 +1,239 @@
+package filter
+
+import (
+	"cmp"
+	"encoding"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+// FieldSchema 描述过滤字段的取值类型、可用操作符与示例，供接口文档与客户端构造过滤 UI。
+//
+// and/or/intersection 组合对所有字段可用，不在 Ops 中列出。
+type FieldSchema struct {
+	// Name 为 where 规则中使用的字段名
+	Name string `json:"name"`
+	// Type 为取值的 JSON Schema 类型：string、integer、number 或 boolean
+	Type string `json:"type"`
+	// Format 为取值格式，如 date-time、int64
+	Format string `json:"format,omitzero"`
+	// Enum 为枚举字段的可选值
+	Enum []EnumValueSchema `json:"enum,omitzero"`
+	// Ops 为字段允许的操作符
+	Ops []OpSchema `json:"ops"`
+}
+
+// EnumValueSchema 描述枚举取值及其显示名。
+type EnumValueSchema struct {
+	Value any    `json:"value"`
+	Label string `json:"label,omitzero"`
+}
+
+// OpSchema 描述操作符的指令名、参数与示例。
+type OpSchema struct {
+	// Name 为指令名，如 eq、between
+	Name string `json:"name"`
+	// Arity 为参数个数，-1 表示一个及以上
+	Arity int `json:"arity"`
+	// TimeExpr 表示参数为时间表达式（now、-7d、2026-10-18 或 RFC3339 时间）而非字段取值
+	TimeExpr bool `json:"timeExpr,omitzero"`
+	// Example 为示例指令
+	Example string `json:"example"`
+}
+
+// SchemaOf 描述取值类型为 T 的过滤字段；未指定 ops 时按类型推断可用操作符。
+func SchemaOf[T comparable](name string, ops ...Op) FieldSchema {
+	return schemaOf(reflect.TypeFor[T](), name, ops)
+}
+
+// Schema 描述组合中注册的全部过滤字段，按字段名排序；字段标签 `ops:"..."`（由 filterop 生成）声明的操作符优先于类型推断。
+func (c *Composed) Schema() []FieldSchema {
+	schemas := make([]FieldSchema, 0, len(c.fieldRulers))
+
+	for name, fr := range c.fieldRulers {
+		if s, ok := fr.New().Rule().(interface {
+			schema(name string, ops []Op) FieldSchema
+		}); ok {
+			schemas = append(schemas, s.schema(name, fr.ops))
+		}
+	}
+
+	slices.SortFunc(schemas, func(a, b FieldSchema) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return schemas
+}
+
+func (Filter[T]) schema(name string, ops []Op) FieldSchema {
+	return SchemaOf[T](name, ops...)
+}
+
+var (
+	typeTime          = reflect.TypeFor[time.Time]()
+	typeTextMarshaler = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+type canEnumValues interface {
+	EnumValues() []any
+}
+
+type canOpenAPISchemaFormat interface {
+	OpenAPISchemaFormat() string
+}
+
+func schemaOf(typ reflect.Type, name string, ops []Op) FieldSchema {
+	nullable := false
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+		nullable = true
+	}
+
+	s := FieldSchema{Name: name}
+
+	v := reflect.New(typ).Elem().Interface()
+
+	isTime := typeTime.ConvertibleTo(typ) && typ.Kind() == reflect.Struct
+	isEnum := false
+
+	switch {
+	case isTime:
+		s.Type, s.Format = "string", "date-time"
+	case reflect.PointerTo(typ).Implements(typeTextMarshaler) || typ.Kind() == reflect.String:
+		s.Type = "string"
+	case typ.Kind() == reflect.Bool:
+		s.Type = "boolean"
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Uint64:
+		s.Type = "integer"
+		if typ.Kind() == reflect.Int64 || typ.Kind() == reflect.Uint64 {
+			s.Format = "int64"
+		}
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		s.Type = "number"
+	default:
+		s.Type = "string"
+	}
+
+	if x, ok := v.(canOpenAPISchemaFormat); ok {
+		s.Format = x.OpenAPISchemaFormat()
+	}
+
+	if x, ok := v.(canEnumValues); ok {
+		isEnum = true
+		for _, e := range x.EnumValues() {
+			ev := EnumValueSchema{Value: e}
+			if raw, err := json.Marshal(e); err == nil {
+				var value any
+				if err := json.Unmarshal(raw, &value); err == nil {
+					ev.Value = value
+				}
+			}
+			if l, ok := e.(interface{ Label() string }); ok {
+				ev.Label = l.Label()
+			}
+			s.Enum = append(s.Enum, ev)
+		}
+		if len(s.Enum) > 0 {
+			if _, ok := s.Enum[0].Value.(string); ok {
+				s.Type, s.Format = "string", ""
+			}
+		}
+	}
+
+	if len(ops) == 0 {
+		ops = defaultOpsOf(s.Type, isTime, isEnum, nullable)
+	}
+
+	example := exampleOf(s)
+
+	for _, op := range ops {
+		s.Ops = append(s.Ops, opSchemaOf(op, example))
+	}
+
+	return s
+}
+
+func defaultOpsOf(typ string, isTime bool, isEnum bool, nullable bool) []Op {
+	ops := []Op{OP__EQ, OP__NEQ, OP__IN, OP__NOTIN}
+
+	switch {
+	case isTime:
+		ops = append(ops, OP__GT, OP__GTE, OP__LT, OP__LTE, OP__BETWEEN, OP__SINCE, OP__BEFORE, OP__DAY)
+	case isEnum || typ == "boolean":
+	case typ == "string":
+		ops = append(ops, OP__PREFIX, OP__SUFFIX, OP__CONTAINS, OP__NOTCONTAINS, OP__IPREFIX, OP__ISUFFIX, OP__ICONTAINS, OP__NOTICONTAINS)
+	default:
+		ops = append(ops, OP__GT, OP__GTE, OP__LT, OP__LTE)
+	}
+
+	if nullable {
+		ops = append(ops, OP__ISNULL, OP__NOTNULL)
+	}
+
+	return ops
+}
+
+func exampleOf(s FieldSchema) string {
+	var v any
+
+	switch {
+	case len(s.Enum) > 0:
+		v = s.Enum[0].Value
+	case s.Format == "date-time":
+		v = "2026-10-18T00:00:00Z"
+	case s.Type == "boolean":
+		v = true
+	case s.Type == "integer":
+		v = 1
+	case s.Type == "number":
+		v = 1.5
+	default:
+		v = "text"
+	}
+
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
+
+func opSchemaOf(op Op, example string) OpSchema {
+	o := OpSchema{Name: strings.ToLower(op.String()), Arity: 1}
+
+	switch op {
+	case OP__ISNULL, OP__NOTNULL:
+		o.Arity = 0
+	case OP__IN, OP__NOTIN, OP__CONTAINSALL, OP__CONTAINSANY:
+		o.Arity = -1
+		o.Example = fmt.Sprintf("%s(%s,%s)", o.Name, example, example)
+		return o
+	case OP__BETWEEN:
+		o.Arity, o.TimeExpr = 2, true
+		o.Example = "between(2026-10-01,2026-10-18)"
+		return o
+	case OP__SINCE:
+		o.TimeExpr = true
+		o.Example = "since(-7d)"
+		return o
+	case OP__BEFORE:
+		o.TimeExpr = true
+		o.Example = "before(now)"
+		return o
+	case OP__DAY:
+		o.TimeExpr = true
+		o.Example = "day(2026-10-18)"
+		return o
+	default:
+	}
+
+	if o.Arity == 0 {
+		o.Example = o.Name + "()"
+	} else {
+		o.Example = fmt.Sprintf("%s(%s)", o.Name, example)
+	}
+
+	return o
+}
@@ -0,0 +1,249 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	contextx "github.com/octohelm/x/context"
+)
+
+// Clock 返回当前时间，用于解析 now 与相对时间表达式。
+type Clock func() time.Time
+
+var clockContext = contextx.New[Clock]()
+
+// InjectClock 把时钟注入上下文，便于在测试中固定 now。
+func InjectClock(ctx context.Context, clock Clock) context.Context {
+	return clockContext.Inject(ctx, clock)
+}
+
+// ClockFromContext 返回上下文中的时钟，未注入时使用 time.Now。
+func ClockFromContext(ctx context.Context) Clock {
+	if clock, ok := clockContext.MayFrom(ctx); ok && clock != nil {
+		return clock
+	}
+	return time.Now
+}
+
+// Now 返回表示当前时刻的时间表达式。
+func Now() TimeExpr {
+	return TimeExpr{kind: timeExprRelative}
+}
+
+// Relative 返回相对当前时刻的时间表达式，整天的偏移按自然日计算。
+func Relative(d time.Duration) TimeExpr {
+	if d%(24*time.Hour) == 0 {
+		return TimeExpr{kind: timeExprRelative, days: int(d / (24 * time.Hour))}
+	}
+	return TimeExpr{kind: timeExprRelative, offset: d}
+}
+
+// Date 返回日期精度的时间表达式，按时钟所在时区取当天零点。
+func Date(year int, month time.Month, day int) TimeExpr {
+	return TimeExpr{kind: timeExprDate, at: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// At 返回固定时刻的时间表达式。
+func At(t time.Time) TimeExpr {
+	return TimeExpr{kind: timeExprAt, at: t}
+}
+
+type timeExprKind uint8
+
+const (
+	timeExprRelative timeExprKind = iota
+	timeExprDate
+	timeExprLocal
+	timeExprAt
+)
+
+const (
+	dateLayout  = "2006-01-02"
+	localLayout = "2006-01-02T15:04:05"
+)
+
+// TimeExpr 表示时间操作符的参数，可为 now、相对时长（如 -7d、+1h）、日期（2026-10-18）或 RFC3339 时间。
+type TimeExpr struct {
+	kind   timeExprKind
+	days   int
+	offset time.Duration
+	at     time.Time
+}
+
+var reRelative = regexp.MustCompile(`^(now)?(([+-])(\d+)([smhdw]))?$`)
+
+// ParseTimeExpr 解析时间表达式，未带时区的日期与时间按时钟所在时区解析。
+func ParseTimeExpr(s string) (TimeExpr, error) {
+	if raw, err := strconv.Unquote(s); err == nil {
+		s = raw
+	}
+	s = strings.TrimSpace(s)
+
+	if s != "" {
+		if m := reRelative.FindStringSubmatch(s); m != nil && (m[1] != "" || m[2] != "") {
+			if m[2] == "" {
+				return Now(), nil
+			}
+
+			n, err := strconv.Atoi(m[4])
+			if err != nil {
+				return TimeExpr{}, fmt.Errorf("invalid time expr `%s`: %w", s, err)
+			}
+			if m[3] == "-" {
+				n = -n
+			}
+
+			switch m[5] {
+			case "w":
+				return TimeExpr{kind: timeExprRelative, days: n * 7}, nil
+			case "d":
+				return TimeExpr{kind: timeExprRelative, days: n}, nil
+			case "h":
+				return Relative(time.Duration(n) * time.Hour), nil
+			case "m":
+				return Relative(time.Duration(n) * time.Minute), nil
+			default:
+				return Relative(time.Duration(n) * time.Second), nil
+			}
+		}
+
+		if t, err := time.Parse(dateLayout, s); err == nil {
+			return TimeExpr{kind: timeExprDate, at: t}, nil
+		}
+		if t, err := time.Parse(localLayout, s); err == nil {
+			return TimeExpr{kind: timeExprLocal, at: t}, nil
+		}
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return At(t), nil
+		}
+	}
+
+	return TimeExpr{}, fmt.Errorf("invalid time expr `%s`", s)
+}
+
+// IsDate 判断是否为日期精度，日期作为区间上界时包含当天。
+func (e TimeExpr) IsDate() bool {
+	return e.kind == timeExprDate
+}
+
+// Resolve 以给定的当前时间解析出具体时刻。
+func (e TimeExpr) Resolve(now time.Time) time.Time {
+	switch e.kind {
+	case timeExprDate, timeExprLocal:
+		y, m, d := e.at.Date()
+		return time.Date(y, m, d, e.at.Hour(), e.at.Minute(), e.at.Second(), e.at.Nanosecond(), now.Location())
+	case timeExprAt:
+		return e.at
+	default:
+		return now.AddDate(0, 0, e.days).Add(e.offset)
+	}
+}
+
+func (e TimeExpr) String() string {
+	switch e.kind {
+	case timeExprDate:
+		return e.at.Format(dateLayout)
+	case timeExprLocal:
+		return e.at.Format(localLayout)
+	case timeExprAt:
+		return e.at.Format(time.RFC3339)
+	default:
+		n, unit := e.days, "d"
+		if e.offset != 0 {
+			switch {
+			case e.offset%time.Hour == 0:
+				n, unit = int(e.offset/time.Hour), "h"
+			case e.offset%time.Minute == 0:
+				n, unit = int(e.offset/time.Minute), "m"
+			default:
+				n, unit = int(e.offset/time.Second), "s"
+			}
+		}
+		if n == 0 {
+			return "now"
+		}
+		if n > 0 {
+			return fmt.Sprintf("+%d%s", n, unit)
+		}
+		return fmt.Sprintf("%d%s", n, unit)
+	}
+}
+
+func (e TimeExpr) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+func (e TimeExpr) MarshalDirective() ([]byte, error) {
+	return e.MarshalText()
+}
+
+func (e *TimeExpr) UnmarshalText(text []byte) error {
+	v, err := ParseTimeExpr(string(text))
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+
+func isTimeOp(op Op) bool {
+	switch op {
+	case OP__BETWEEN, OP__SINCE, OP__BEFORE, OP__DAY:
+		return true
+	default:
+		return false
+	}
+}
+
+// TimeRange 以给定的当前时间解析时间操作符的左闭右开区间 [from, to)，零值表示该侧不限。
+//
+// between 的日期上界包含当天；day 按当前时间所在时区取参数所在的自然日。
+func TimeRange(rule Rule, now time.Time) (from time.Time, to time.Time, ok bool) {
+	exprs := timeExprs(rule.Args())
+
+	switch rule.Op() {
+	case OP__SINCE:
+		if len(exprs) == 1 {
+			return exprs[0].Resolve(now), time.Time{}, true
+		}
+	case OP__BEFORE:
+		if len(exprs) == 1 {
+			return time.Time{}, exprs[0].Resolve(now), true
+		}
+	case OP__BETWEEN:
+		if len(exprs) == 2 {
+			from, to = exprs[0].Resolve(now), exprs[1].Resolve(now)
+			if exprs[1].IsDate() {
+				to = to.AddDate(0, 0, 1)
+			}
+			return from, to, true
+		}
+	case OP__DAY:
+		if len(exprs) == 1 {
+			y, m, d := exprs[0].Resolve(now).In(now.Location()).Date()
+			from = time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+			return from, from.AddDate(0, 0, 1), true
+		}
+	default:
+	}
+
+	return time.Time{}, time.Time{}, false
+}
+
+func timeExprs(args iter.Seq[Arg]) []TimeExpr {
+	exprs := make([]TimeExpr, 0, 2)
+	for arg := range args {
+		switch x := arg.(type) {
+		case TimeExpr:
+			exprs = append(exprs, x)
+		case *TimeExpr:
+			exprs = append(exprs, *x)
+		}
+	}
+	return exprs
+}
@@ -0,0 +1,132 @@
+package filter
+
+import (
+	"slices"
+)
+
+// ValidateOptionFunc 表示过滤表达式校验选项。
+type ValidateOptionFunc func(v *validator)
+
+// MaxDepth 限制 and/or/intersection 的嵌套层数，单条规则为 1 层。
+func MaxDepth(n int) ValidateOptionFunc {
+	return func(v *validator) {
+		v.maxDepth = n
+	}
+}
+
+// MaxValues 限制单条规则的取值个数，如 in、notin、containsany 的参数个数。
+func MaxValues(n int) ValidateOptionFunc {
+	return func(v *validator) {
+		v.maxValues = n
+	}
+}
+
+// MaxRules 限制表达式中非组合规则的总数。
+func MaxRules(n int) ValidateOptionFunc {
+	return func(v *validator) {
+		v.maxRules = n
+	}
+}
+
+// AllowOps 指定字段允许的操作符，and/or/intersection 组合不受限；未指定的字段不限制操作符。
+func AllowOps(fieldName string, ops ...Op) ValidateOptionFunc {
+	return func(v *validator) {
+		if v.allowedOps == nil {
+			v.allowedOps = map[string][]Op{}
+		}
+		v.allowedOps[fieldName] = ops
+	}
+}
+
+// Validate 校验规则的操作符与复杂度；where 规则以其字段名匹配 AllowOps，单独的 Filter 以空字段名匹配。
+func Validate(rule Rule, optFns ...ValidateOptionFunc) error {
+	v := &validator{}
+	for _, optFn := range optFns {
+		optFn(v)
+	}
+	return v.validate(rule)
+}
+
+// Validate 校验组合后的全部规则，字段标签 `ops:"eq,in,prefix"` 声明的允许操作符可被 AllowOps 覆盖。
+func (c *Composed) Validate(optFns ...ValidateOptionFunc) error {
+	v := &validator{}
+
+	for name, fr := range c.fieldRulers {
+		if fr.ops != nil {
+			AllowOps(name, fr.ops...)(v)
+		}
+	}
+
+	for _, optFn := range optFns {
+		optFn(v)
+	}
+
+	for _, arg := range c.rules {
+		if rule, ok := arg.(Rule); ok {
+			if err := v.validate(rule); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type validator struct {
+	maxDepth   int
+	maxValues  int
+	maxRules   int
+	allowedOps map[string][]Op
+
+	rules int
+}
+
+func (v *validator) validate(rule Rule) error {
+	if w, ok := rule.(WhereRule); ok {
+		return v.walkArgs(w.Name(), w, 1)
+	}
+	return v.walk("", rule, 1)
+}
+
+func (v *validator) walkArgs(fieldName string, rule Rule, depth int) error {
+	for arg := range rule.Args() {
+		if sub, ok := arg.(Rule); ok {
+			if err := v.walk(fieldName, sub, depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *validator) walk(fieldName string, rule Rule, depth int) error {
+	if v.maxDepth > 0 && depth > v.maxDepth {
+		return &ErrFilterTooComplex{Limit: "depth", Max: v.maxDepth, Actual: depth}
+	}
+
+	switch op := rule.Op(); op {
+	case OP__AND, OP__OR, OP__INTERSECTION:
+		return v.walkArgs(fieldName, rule, depth+1)
+	default:
+		if ops, ok := v.allowedOps[fieldName]; ok && !slices.Contains(ops, op) {
+			return &ErrFilterOpNotAllowed{FieldName: fieldName, Op: op.String()}
+		}
+
+		v.rules++
+		if v.maxRules > 0 && v.rules > v.maxRules {
+			return &ErrFilterTooComplex{Limit: "rules", Max: v.maxRules, Actual: v.rules}
+		}
+
+		if v.maxValues > 0 {
+			n := 0
+			for range rule.Args() {
+				n++
+			}
+			if n > v.maxValues {
+				return &ErrFilterTooComplex{Limit: "values", Max: v.maxValues, Actual: n}
+			}
+		}
+	}
+
+	return nil
+}
@@ -27,6 +27,10 @@ type where[T comparable] struct {
 	args []Arg
 }
 
+func (w where[T]) Name() string {
+	return w.name
+}
+
 func (w where[T]) Args() iter.Seq[Arg] {
 	return slices.Values(w.args)
 }
@@ -15,7 +15,7 @@ var InvalidOp = errors.New("invalid Op")
 
 func (Op) EnumValues() []any {
 	return []any{
-		OP__EQ, OP__CONTAINS, OP__PREFIX, OP__SUFFIX, OP__WHERE, OP__AND, OP__OR, OP__INTERSECTION, OP__NEQ, OP__IN, OP__NOTIN, OP__GTE, OP__GT, OP__LTE, OP__LT, OP__NOTCONTAINS,
+		OP__EQ, OP__CONTAINS, OP__PREFIX, OP__SUFFIX, OP__WHERE, OP__AND, OP__OR, OP__INTERSECTION, OP__NEQ, OP__IN, OP__NOTIN, OP__GTE, OP__GT, OP__LTE, OP__LT, OP__NOTCONTAINS, OP__CONTAINSALL, OP__CONTAINSANY, OP__NOTICONTAINS, OP__ICONTAINS, OP__IPREFIX, OP__ISUFFIX, OP__BETWEEN, OP__SINCE, OP__BEFORE, OP__DAY, OP__ISNULL, OP__NOTNULL,
 	}
 }
 
@@ -66,6 +66,30 @@ func ParseOpFromString(s string) (Op, error) {
 		return OP__LT, nil
 	case "NOTCONTAINS":
 		return OP__NOTCONTAINS, nil
+	case "CONTAINSALL":
+		return OP__CONTAINSALL, nil
+	case "CONTAINSANY":
+		return OP__CONTAINSANY, nil
+	case "NOTICONTAINS":
+		return OP__NOTICONTAINS, nil
+	case "ICONTAINS":
+		return OP__ICONTAINS, nil
+	case "IPREFIX":
+		return OP__IPREFIX, nil
+	case "ISUFFIX":
+		return OP__ISUFFIX, nil
+	case "BETWEEN":
+		return OP__BETWEEN, nil
+	case "SINCE":
+		return OP__SINCE, nil
+	case "BEFORE":
+		return OP__BEFORE, nil
+	case "DAY":
+		return OP__DAY, nil
+	case "ISNULL":
+		return OP__ISNULL, nil
+	case "NOTNULL":
+		return OP__NOTNULL, nil
 
 	default:
 		var i Op
@@ -115,6 +139,30 @@ func (v Op) String() string {
 		return "LT"
 	case OP__NOTCONTAINS:
 		return "NOTCONTAINS"
+	case OP__CONTAINSALL:
+		return "CONTAINSALL"
+	case OP__CONTAINSANY:
+		return "CONTAINSANY"
+	case OP__NOTICONTAINS:
+		return "NOTICONTAINS"
+	case OP__ICONTAINS:
+		return "ICONTAINS"
+	case OP__IPREFIX:
+		return "IPREFIX"
+	case OP__ISUFFIX:
+		return "ISUFFIX"
+	case OP__BETWEEN:
+		return "BETWEEN"
+	case OP__SINCE:
+		return "SINCE"
+	case OP__BEFORE:
+		return "BEFORE"
+	case OP__DAY:
+		return "DAY"
+	case OP__ISNULL:
+		return "ISNULL"
+	case OP__NOTNULL:
+		return "NOTNULL"
 	case OP_UNKNOWN:
 		return "UNKNOWN"
 
@@ -157,6 +205,30 @@ func ParseOpLabelString(label string) (Op, error) {
 		return OP__LT, nil
 	case "NOTCONTAINS":
 		return OP__NOTCONTAINS, nil
+	case "CONTAINSALL":
+		return OP__CONTAINSALL, nil
+	case "CONTAINSANY":
+		return OP__CONTAINSANY, nil
+	case "NOTICONTAINS":
+		return OP__NOTICONTAINS, nil
+	case "ICONTAINS":
+		return OP__ICONTAINS, nil
+	case "IPREFIX":
+		return OP__IPREFIX, nil
+	case "ISUFFIX":
+		return OP__ISUFFIX, nil
+	case "BETWEEN":
+		return OP__BETWEEN, nil
+	case "SINCE":
+		return OP__SINCE, nil
+	case "BEFORE":
+		return OP__BEFORE, nil
+	case "DAY":
+		return OP__DAY, nil
+	case "ISNULL":
+		return OP__ISNULL, nil
+	case "NOTNULL":
+		return OP__NOTNULL, nil
 
 	default:
 		return OP_UNKNOWN, InvalidOp
@@ -197,6 +269,30 @@ func (v Op) Label() string {
 		return "LT"
 	case OP__NOTCONTAINS:
 		return "NOTCONTAINS"
+	case OP__CONTAINSALL:
+		return "CONTAINSALL"
+	case OP__CONTAINSANY:
+		return "CONTAINSANY"
+	case OP__NOTICONTAINS:
+		return "NOTICONTAINS"
+	case OP__ICONTAINS:
+		return "ICONTAINS"
+	case OP__IPREFIX:
+		return "IPREFIX"
+	case OP__ISUFFIX:
+		return "ISUFFIX"
+	case OP__BETWEEN:
+		return "BETWEEN"
+	case OP__SINCE:
+		return "SINCE"
+	case OP__BEFORE:
+		return "BEFORE"
+	case OP__DAY:
+		return "DAY"
+	case OP__ISNULL:
+		return "ISNULL"
+	case OP__NOTNULL:
+		return "NOTNULL"
 
 	default:
 		return fmt.Sprint(v)
@@ -0,0 +1,143 @@
+package fixture
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// KeyName 为行内声明引用名的键
+	KeyName = "$name"
+	// KeyRef 为列值中引用其他行的键，值为 `<name>` 或 `<name>.<column>`
+	KeyRef = "$ref"
+)
+
+// Document 表示一份 fixture，键为表名或模型 kind，值为按顺序写入的行。
+type Document map[string][]Row
+
+// Row 表示一行数据，键为列名或字段名，值为 JSON 编码的列值。
+type Row map[string]jsontext.Value
+
+// Name 返回行通过 `$name` 声明的引用名。
+func (r Row) Name() string {
+	raw, ok := r[KeyName]
+	if !ok {
+		return ""
+	}
+	name := ""
+	if err := json.Unmarshal(raw, &name); err != nil {
+		return ""
+	}
+	return name
+}
+
+// ParseJSON 解析 JSON 格式的 fixture。
+func ParseJSON(data []byte) (Document, error) {
+	doc := Document{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse fixture failed: %w", err)
+	}
+	return doc, nil
+}
+
+// ParseYAML 解析 YAML 格式的 fixture。
+func ParseYAML(data []byte) (Document, error) {
+	values := map[string][]map[string]any{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parse fixture failed: %w", err)
+	}
+
+	doc := make(Document, len(values))
+
+	for name, rows := range values {
+		list := make([]Row, 0, len(rows))
+
+		for _, values := range rows {
+			row := make(Row, len(values))
+			for k, v := range values {
+				raw, err := json.Marshal(v)
+				if err != nil {
+					return nil, fmt.Errorf("parse fixture failed: %s.%s: %w", name, k, err)
+				}
+				row[k] = raw
+			}
+			list = append(list, row)
+		}
+
+		doc[name] = list
+	}
+
+	return doc, nil
+}
+
+// ReadFS 按 glob 模式读取 fixture 文件，按 `.json`、`.yaml` 或 `.yml` 扩展名选择解析方式，同一模式下按文件名排序。
+func ReadFS(fsys fs.FS, patterns ...string) ([]Document, error) {
+	docs := make([]Document, 0)
+
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		slices.Sort(matches)
+
+		for _, filename := range matches {
+			data, err := fs.ReadFile(fsys, filename)
+			if err != nil {
+				return nil, err
+			}
+
+			var doc Document
+
+			switch strings.ToLower(path.Ext(filename)) {
+			case ".json":
+				doc, err = ParseJSON(data)
+			case ".yaml", ".yml":
+				doc, err = ParseYAML(data)
+			default:
+				return nil, fmt.Errorf("unsupported fixture file %s", filename)
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", filename, err)
+			}
+
+			docs = append(docs, doc)
+		}
+	}
+
+	return docs, nil
+}
+
+type ref struct {
+	name   string
+	column string
+}
+
+// refOf 判断列值是否为 `{"$ref": "<name>[.<column>]"}` 形式的引用。
+func refOf(raw jsontext.Value) (*ref, bool) {
+	if raw.Kind() != '{' {
+		return nil, false
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, false
+	}
+
+	target, ok := values[KeyRef]
+	if !ok || len(values) != 1 {
+		return nil, false
+	}
+
+	name, column, _ := strings.Cut(target, ".")
+	return &ref{name: name, column: column}, true
+}
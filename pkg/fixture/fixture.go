@@ -0,0 +1,377 @@
+// Package fixture 提供按 catalog 关联关系加载种子数据的能力。
+//
+// fixture 按表名或模型 kind 分组列出行，行内以 `$name` 声明引用名，
+// 列值写作 `{"$ref": "<name>"}` 或 `{"$ref": "<name>.<column>"}` 时取被引用行写入后的值。
+// 表的写入顺序由列关联、主键 ID 类型与行间引用推导，全部写入在同一事务内完成，因此一次加载的表须属于同一会话。
+package fixture
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-json-experiment/json/jsontext"
+
+	"github.com/octohelm/storage/pkg/session"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+)
+
+// OptionFunc 表示加载器选项函数。
+type OptionFunc func(l *Loader)
+
+// Upsert 让写入在唯一索引冲突时更新已有记录，使重复加载保持幂等。
+func Upsert() OptionFunc {
+	return func(l *Loader) {
+		l.upsert = true
+	}
+}
+
+// NewLoader 创建 fixture 加载器，需通过 Include 注册可加载的模型。
+func NewLoader(optFns ...OptionFunc) *Loader {
+	l := &Loader{
+		models: map[string]model{},
+	}
+
+	for _, optFn := range optFns {
+		optFn(l)
+	}
+
+	return l
+}
+
+// Loader 负责把 fixture 写入数据库。
+type Loader struct {
+	// ordered 为注册顺序，作为无依赖关系时的写入顺序
+	ordered []model
+	// models 按表名与 kind 索引
+	models map[string]model
+	upsert bool
+}
+
+func (l *Loader) include(m model) {
+	l.ordered = append(l.ordered, m)
+	l.models[m.table().TableName()] = m
+	if kind := m.kind(); kind != "" {
+		l.models[kind] = m
+	}
+}
+
+// LoadFS 读取匹配的 fixture 文件并写入数据库。
+func (l *Loader) LoadFS(ctx context.Context, fsys fs.FS, patterns ...string) error {
+	docs, err := ReadFS(fsys, patterns...)
+	if err != nil {
+		return err
+	}
+	return l.Load(ctx, docs...)
+}
+
+// Load 把 fixture 写入数据库，引用名在全部文档间共享。
+func (l *Loader) Load(ctx context.Context, docs ...Document) error {
+	rows := map[model][]Row{}
+	named := map[string]model{}
+
+	for _, doc := range docs {
+		for _, name := range slices.Sorted(maps.Keys(doc)) {
+			m, ok := l.models[name]
+			if !ok {
+				return fmt.Errorf("unknown fixture table or kind %s", name)
+			}
+
+			for _, row := range doc[name] {
+				if n := row.Name(); n != "" {
+					if _, ok := named[n]; ok {
+						return fmt.Errorf("duplicated fixture name %s", n)
+					}
+					named[n] = m
+				}
+				rows[m] = append(rows[m], row)
+			}
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	ordered, err := l.sort(rows, named)
+	if err != nil {
+		return err
+	}
+
+	s, err := sessionOf(ctx, ordered)
+	if err != nil {
+		return err
+	}
+
+	return s.Tx(ctx, func(ctx context.Context) error {
+		r := &resolver{loader: l, named: named, inserted: map[string]reflect.Value{}}
+
+		for _, m := range ordered {
+			for i, row := range rows[m] {
+				if err := r.insert(ctx, m, row); err != nil {
+					return fmt.Errorf("load fixture %s[%d] failed: %w", m.table().TableName(), i, err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// sessionOf 返回待写入模型共用的会话；跨会话的 fixture 无法在同一事务内写入，返回错误。
+func sessionOf(ctx context.Context, models []model) (session.Session, error) {
+	var s session.Session
+
+	for _, m := range models {
+		ms := m.session(ctx)
+		if ms == nil {
+			return nil, fmt.Errorf("no database session for %s", m.table().TableName())
+		}
+
+		if s == nil {
+			s = ms
+			continue
+		}
+
+		if ms.Name() != s.Name() {
+			return nil, fmt.Errorf("fixture tables %s and %s belong to different sessions %s and %s, load them separately", models[0].table().TableName(), m.table().TableName(), s.Name(), ms.Name())
+		}
+	}
+
+	return s, nil
+}
+
+// sort 按依赖关系对待写入的模型拓扑排序，同层按注册顺序。
+func (l *Loader) sort(rows map[model][]Row, named map[string]model) ([]model, error) {
+	deps := map[model]map[model]bool{}
+
+	for m := range rows {
+		deps[m] = map[model]bool{}
+
+		for dep := range l.dependencies(m) {
+			if _, ok := rows[dep]; ok && dep != m {
+				deps[m][dep] = true
+			}
+		}
+
+		for _, row := range rows[m] {
+			for _, raw := range row {
+				if r, ok := refOf(raw); ok {
+					if dep, ok := named[r.name]; ok && dep != m {
+						deps[m][dep] = true
+					}
+				}
+			}
+		}
+	}
+
+	ordered := make([]model, 0, len(rows))
+	done := map[model]bool{}
+
+	for len(ordered) < len(rows) {
+		progressed := false
+
+		for _, m := range l.ordered {
+			if _, ok := rows[m]; !ok || done[m] {
+				continue
+			}
+
+			ready := true
+			for dep := range deps[m] {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				done[m] = true
+				ordered = append(ordered, m)
+				progressed = true
+			}
+		}
+
+		if !progressed {
+			pending := make([]string, 0)
+			for m := range rows {
+				if !done[m] {
+					pending = append(pending, m.table().TableName())
+				}
+			}
+			slices.Sort(pending)
+			return nil, fmt.Errorf("circular fixture dependencies between %v", pending)
+		}
+	}
+
+	return ordered, nil
+}
+
+// dependencies 返回模型依赖的其他已注册模型，来自列关联 `rel:"Model.Field"` 或与其他表单列主键相同的 ID 类型。
+func (l *Loader) dependencies(m model) map[model]bool {
+	deps := map[model]bool{}
+
+	for col := range m.table().Cols() {
+		if target, _ := l.relationOf(col); target != nil {
+			deps[target] = true
+			continue
+		}
+
+		if target, _ := l.idTypeOf(col); target != nil && target != m {
+			deps[target] = true
+		}
+	}
+
+	return deps
+}
+
+// relationOf 解析列声明的关联 {Model}.{Field}。
+func (l *Loader) relationOf(col sqlbuilder.Column) (model, sqlbuilder.Column) {
+	rel := sqlbuilder.GetColumnDef(col).Relation
+	if len(rel) < 2 {
+		return nil, nil
+	}
+
+	for _, m := range l.ordered {
+		if m.typeName() == rel[len(rel)-2] {
+			if refCol := m.table().F(rel[len(rel)-1]); refCol != nil {
+				return m, refCol
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// idTypeOf 查找以列的具名 Go 类型（如 OrgID）作为单列主键类型的模型。
+func (l *Loader) idTypeOf(col sqlbuilder.Column) (model, sqlbuilder.Column) {
+	typ := sqlbuilder.GetColumnDef(col).Type
+	if typ == nil || !strings.Contains(typ.String(), ".") {
+		return nil, nil
+	}
+
+	for _, m := range l.ordered {
+		if pk := primaryColumn(m.table()); pk != nil && sqlbuilder.GetColumnDef(pk).Type.String() == typ.String() {
+			return m, pk
+		}
+	}
+
+	return nil, nil
+}
+
+func primaryColumn(t sqlbuilder.Table) sqlbuilder.Column {
+	for key := range t.Keys() {
+		if key.IsPrimary() {
+			cols := slices.Collect(key.Cols())
+			if len(cols) == 1 {
+				return cols[0]
+			}
+		}
+	}
+	return nil
+}
+
+type resolver struct {
+	loader   *Loader
+	named    map[string]model
+	inserted map[string]reflect.Value
+}
+
+func (r *resolver) insert(ctx context.Context, m model, row Row) error {
+	t := m.table()
+
+	pending := &pendingRow{
+		values: map[string]any{},
+		raws:   map[string]jsontext.Value{},
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(row)) {
+		if key == KeyName {
+			continue
+		}
+
+		col := t.F(key)
+		if col == nil {
+			return fmt.Errorf("unknown column %s", key)
+		}
+
+		raw := row[key]
+
+		if ref, ok := refOf(raw); ok {
+			value, err := r.resolve(col, ref)
+			if err != nil {
+				return err
+			}
+			pending.values[col.Name()] = value
+		} else {
+			pending.raws[col.Name()] = raw
+		}
+
+		pending.cols = append(pending.cols, col)
+	}
+
+	// keep column order of table
+	slices.SortStableFunc(pending.cols, func(a sqlbuilder.Column, b sqlbuilder.Column) int {
+		return cmp.Compare(columnIndex(t, a), columnIndex(t, b))
+	})
+
+	inserted, err := m.insert(ctx, pending, r.loader.upsert)
+	if err != nil {
+		return err
+	}
+
+	if name := row.Name(); name != "" {
+		r.inserted[name] = inserted
+	}
+
+	return nil
+}
+
+// resolve 返回被引用行的列值；未指定列时取关联列，其次取被引用表的单列主键。
+func (r *resolver) resolve(col sqlbuilder.Column, ref *ref) (any, error) {
+	target, ok := r.inserted[ref.name]
+	if !ok {
+		return nil, fmt.Errorf("unresolved fixture reference %s", ref.name)
+	}
+
+	targetTable := r.named[ref.name].table()
+
+	var refCol sqlbuilder.Column
+
+	switch {
+	case ref.column != "":
+		refCol = targetTable.F(ref.column)
+	default:
+		if m, relCol := r.loader.relationOf(col); m != nil && m.table() == targetTable {
+			refCol = relCol
+		} else {
+			refCol = primaryColumn(targetTable)
+		}
+	}
+
+	if refCol == nil {
+		return nil, fmt.Errorf("unknown referenced column of %s", ref.name)
+	}
+
+	f := target.FieldByName(refCol.FieldName())
+	if !f.IsValid() {
+		return nil, fmt.Errorf("unknown referenced column %s of %s", refCol.Name(), ref.name)
+	}
+
+	return f.Interface(), nil
+}
+
+func columnIndex(t sqlbuilder.Table, col sqlbuilder.Column) int {
+	i := 0
+	for c := range t.Cols() {
+		if c.Name() == col.Name() {
+			return i
+		}
+		i++
+	}
+	return i
+}
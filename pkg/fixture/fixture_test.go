@@ -0,0 +1,208 @@
+package fixture_test
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/storage/internal/testutil"
+	"github.com/octohelm/storage/pkg/fixture"
+	"github.com/octohelm/storage/pkg/session"
+	sessiondb "github.com/octohelm/storage/pkg/session/db"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/pkg/sqlpipe/ex"
+	"github.com/octohelm/storage/testdata/model"
+)
+
+func TestLoader(t *testing.T) {
+	ctx := contextWithDatabase(t, "fixture_load")
+
+	loader := fixture.NewLoader(
+		// registered before its dependencies on purpose
+		fixture.Include[model.OrgUser](),
+		fixture.Include[model.User](),
+		fixture.Include[model.Org](),
+		fixture.Upsert(),
+	)
+
+	Then(
+		t, "按引用与关联顺序写入",
+		ExpectDo(func() error {
+			return loader.LoadFS(ctx, os.DirFS("testdata"), "*.yaml", "*.json")
+		}),
+		ExpectMustValue(
+			func() (int, error) {
+				list, err := ex.FromSource(sqlpipe.From[model.OrgUser]()).List(ctx)
+				return len(list), err
+			},
+			Equal(2),
+		),
+		ExpectMustValue(
+			func() (model.Gender, error) {
+				u, err := ex.FromSource(sqlpipe.From[model.User]()).PipeE(
+					sqlpipe.Where(model.UserT.Name, sqlbuilder.Eq("alice")),
+				).FindOne(ctx)
+				if err != nil || u == nil {
+					return 0, err
+				}
+				return u.Gender, nil
+			},
+			Equal(model.GENDER__FEMALE),
+		),
+	)
+
+	Then(
+		t, "开启 Upsert 后重复加载保持幂等",
+		ExpectDo(func() error {
+			return loader.LoadFS(ctx, os.DirFS("testdata"), "*.yaml", "*.json")
+		}),
+		ExpectMustValue(
+			func() (int, error) {
+				list, err := ex.FromSource(sqlpipe.From[model.User]()).List(ctx)
+				return len(list), err
+			},
+			Equal(2),
+		),
+		ExpectMustValue(
+			func() (int, error) {
+				list, err := ex.FromSource(sqlpipe.From[model.OrgUser]()).List(ctx)
+				return len(list), err
+			},
+			Equal(2),
+		),
+	)
+
+	Then(
+		t, "开启 Upsert 时行未覆盖任何唯一索引返回错误",
+		ExpectDo(
+			func() error {
+				doc, err := fixture.ParseJSON([]byte(`{"t_org_user":[{"UserID":1}]}`))
+				if err != nil {
+					return err
+				}
+				return loader.Load(ctx, doc)
+			},
+			ErrorMatch(regexp.MustCompile(`no unique key of t_org_user covered by given columns`)),
+		),
+	)
+}
+
+func TestLoaderErrors(t *testing.T) {
+	loader := fixture.NewLoader(
+		fixture.Include[model.OrgUser](),
+		fixture.Include[model.User](),
+	)
+
+	Then(
+		t, "未注册的表",
+		ExpectDo(
+			func() error {
+				return loader.Load(context.Background(), fixture.Document{"t_unknown": {{}}})
+			},
+			ErrorMatch(regexp.MustCompile(`unknown fixture table or kind t_unknown`)),
+		),
+	)
+
+	Then(
+		t, "引用名重复",
+		ExpectDo(
+			func() error {
+				users, err := fixture.ParseYAML([]byte("User:\n  - $name: alice\n"))
+				if err != nil {
+					return err
+				}
+				orgUsers, err := fixture.ParseJSON([]byte(`{"OrgUser":[{"$name":"alice"}]}`))
+				if err != nil {
+					return err
+				}
+				return loader.Load(context.Background(), users, orgUsers)
+			},
+			ErrorMatch(regexp.MustCompile(`duplicated fixture name alice`)),
+		),
+	)
+
+	Then(
+		t, "跨会话的表无法在同一事务内写入",
+		ExpectDo(
+			func() error {
+				primary := &sqlbuilder.Tables{}
+				primary.Add(sqlbuilder.TableFromModel(&primaryItem{}))
+				session.RegisterCatalog("fixture_primary", primary)
+
+				audit := &sqlbuilder.Tables{}
+				audit.Add(sqlbuilder.TableFromModel(&auditItem{}))
+				session.RegisterCatalog("fixture_audit", audit)
+
+				ctx := session.InjectContext(context.Background(), session.New(nil, "fixture_primary"))
+				ctx = session.InjectContext(ctx, session.New(nil, "fixture_audit"))
+
+				return fixture.NewLoader(
+					fixture.Include[primaryItem](),
+					fixture.Include[auditItem](),
+				).Load(ctx, fixture.Document{
+					"t_fixture_primary_item": {{}},
+					"t_fixture_audit_item":   {{}},
+				})
+			},
+			ErrorMatch(regexp.MustCompile(`fixture tables t_fixture_primary_item and t_fixture_audit_item belong to different sessions fixture_primary and fixture_audit`)),
+		),
+	)
+}
+
+type primaryItem struct {
+	ID uint64 `db:"f_id,autoincrement"`
+}
+
+func (primaryItem) TableName() string {
+	return "t_fixture_primary_item"
+}
+
+type auditItem struct {
+	ID uint64 `db:"f_id,autoincrement"`
+}
+
+func (auditItem) TableName() string {
+	return "t_fixture_audit_item"
+}
+
+func contextWithDatabase(t testing.TB, name string) context.Context {
+	t.Helper()
+	ctx := testutil.NewContext(t)
+
+	cat := &sqlbuilder.Tables{}
+	cat.Add(model.UserT)
+	cat.Add(model.OrgT)
+	cat.Add(model.OrgUserT)
+
+	db := &sessiondb.Database{
+		EnableMigrate: true,
+	}
+
+	db.ApplyCatalog(name, cat)
+	db.SetDefaults()
+	err := db.Init(ctx)
+	testutil.Expect(t, err, testutil.Be[error](nil))
+
+	ctx = db.InjectContext(ctx)
+
+	err = db.Run(ctx)
+	testutil.Expect(t, err, testutil.Be[error](nil))
+
+	t.Cleanup(func() {
+		a := session.For(ctx, name).Adapter()
+
+		for table := range cat.Tables() {
+			_, e := a.Exec(ctx, a.Dialect().DropTable(table))
+			testutil.Expect(t, e, testutil.Be[error](nil))
+		}
+
+		err := a.Close()
+		testutil.Expect(t, err, testutil.Be[error](nil))
+	})
+
+	return ctx
+}
@@ -0,0 +1,229 @@
+package fixture
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+
+	"github.com/octohelm/storage/pkg/session"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/pkg/sqlpipe/ex"
+)
+
+// ModelOptionFunc 表示单个模型的加载选项。
+type ModelOptionFunc func(o *modelOption)
+
+// ConflictKey 指定开启 Upsert 时使用的唯一索引，未指定时按行内已给出的列自动选择。
+func ConflictKey(name string) ModelOptionFunc {
+	return func(o *modelOption) {
+		o.conflictKey = name
+	}
+}
+
+type modelOption struct {
+	conflictKey string
+}
+
+// Include 注册可加载的模型，fixture 中可用其表名或 GetKind 返回的 kind 引用。
+func Include[M sqlpipe.Model](optFns ...ModelOptionFunc) OptionFunc {
+	return func(l *Loader) {
+		m := &modelOf[M]{
+			t: sqlbuilder.TableFromModel(new(M)),
+		}
+
+		for _, optFn := range optFns {
+			optFn(&m.option)
+		}
+
+		l.include(m)
+	}
+}
+
+type model interface {
+	table() sqlbuilder.Table
+	kind() string
+	typeName() string
+	session(ctx context.Context) session.Session
+	conflictKey(row *pendingRow) (sqlbuilder.Key, error)
+	insert(ctx context.Context, row *pendingRow, upsert bool) (reflect.Value, error)
+}
+
+// pendingRow 表示引用已解析、待写入的一行，values 的键为列名。
+type pendingRow struct {
+	cols   []sqlbuilder.Column
+	values map[string]any
+	raws   map[string]jsontext.Value
+}
+
+func (r *pendingRow) has(col sqlbuilder.Column) bool {
+	return slices.ContainsFunc(r.cols, func(c sqlbuilder.Column) bool { return c.Name() == col.Name() })
+}
+
+type modelOf[M sqlpipe.Model] struct {
+	t      sqlbuilder.Table
+	option modelOption
+}
+
+func (m *modelOf[M]) table() sqlbuilder.Table {
+	return m.t
+}
+
+func (m *modelOf[M]) kind() string {
+	if k, ok := any(new(M)).(interface{ GetKind() string }); ok {
+		return k.GetKind()
+	}
+	return ""
+}
+
+func (m *modelOf[M]) typeName() string {
+	return reflect.TypeFor[M]().Name()
+}
+
+func (m *modelOf[M]) session(ctx context.Context) session.Session {
+	return session.For(ctx, new(M))
+}
+
+// conflictKey 返回配置的唯一索引；未配置时选取首个列均已给出或带默认值的唯一索引，非主键优先；
+// 没有可用的唯一索引时返回错误，避免重复加载时静默插入重复行。
+func (m *modelOf[M]) conflictKey(row *pendingRow) (sqlbuilder.Key, error) {
+	if m.option.conflictKey != "" {
+		key := m.t.K(m.option.conflictKey)
+		if key == nil || !key.IsUnique() {
+			return nil, fmt.Errorf("conflict key %s is not an unique key of %s", m.option.conflictKey, m.t.TableName())
+		}
+		return key, nil
+	}
+
+	keys := slices.SortedFunc(m.t.Keys(), func(a sqlbuilder.Key, b sqlbuilder.Key) int {
+		if a.IsPrimary() != b.IsPrimary() {
+			if a.IsPrimary() {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(a.Name(), b.Name())
+	})
+
+	for _, key := range keys {
+		if !key.IsUnique() {
+			continue
+		}
+
+		given := 0
+		covered := true
+
+		for col := range key.Cols() {
+			if row.has(col) {
+				given++
+				continue
+			}
+
+			if def := sqlbuilder.GetColumnDef(col); def.Default == nil || def.AutoIncrement {
+				covered = false
+				break
+			}
+		}
+
+		if covered && given > 0 {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no unique key of %s covered by given columns, give all columns of one or declare ConflictKey", m.t.TableName())
+}
+
+func (m *modelOf[M]) insert(ctx context.Context, row *pendingRow, upsert bool) (reflect.Value, error) {
+	v := new(M)
+	rv := reflect.ValueOf(v).Elem()
+
+	cols := make([]modelscoped.Column[M], 0, len(row.cols))
+
+	for _, col := range row.cols {
+		f := rv.FieldByName(col.FieldName())
+		if !f.IsValid() {
+			return reflect.Value{}, fmt.Errorf("field %s of column %s not found", col.FieldName(), col.Name())
+		}
+
+		if value, ok := row.values[col.Name()]; ok {
+			if err := assign(f, value); err != nil {
+				return reflect.Value{}, fmt.Errorf("invalid value of %s: %w", col.Name(), err)
+			}
+		} else if err := json.Unmarshal(row.raws[col.Name()], f.Addr().Interface()); err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid value of %s: %w", col.Name(), err)
+		}
+
+		cols = append(cols, modelscoped.CastColumn[M](col))
+	}
+
+	operators := make([]sqlpipe.SourceOperator[M], 0, 1)
+
+	if upsert {
+		key, err := m.conflictKey(row)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		toUpdates := make([]modelscoped.Column[M], 0, len(cols))
+		for _, col := range cols {
+			if !slices.ContainsFunc(slices.Collect(key.Cols()), func(c sqlbuilder.Column) bool { return c.Name() == col.Name() }) {
+				toUpdates = append(toUpdates, col)
+			}
+		}
+
+		keyCols := modelscoped.CastKey[M](key)
+		if len(toUpdates) == 0 {
+			// always update to return the existing row
+			toUpdates = slices.Collect(keyCols.MCols())
+		}
+
+		operators = append(operators, sqlpipe.OnConflictDoUpdateSet[M](keyCols, toUpdates...))
+	}
+
+	list, err := ex.FromSource(sqlpipe.Values([]*M{v}, cols...)).PipeE(operators...).List(ctx)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if len(list) == 0 {
+		return rv, nil
+	}
+
+	return reflect.ValueOf(list[0]).Elem(), nil
+}
+
+func assign(f reflect.Value, value any) error {
+	rv := reflect.ValueOf(value)
+
+	switch {
+	case rv.Type().AssignableTo(f.Type()):
+		f.Set(rv)
+	case convertible(rv.Type(), f.Type()):
+		f.Set(rv.Convert(f.Type()))
+	case f.Kind() == reflect.Pointer && convertible(rv.Type(), f.Type().Elem()):
+		p := reflect.New(f.Type().Elem())
+		p.Elem().Set(rv.Convert(f.Type().Elem()))
+		f.Set(p)
+	default:
+		return fmt.Errorf("%s could not assign to %s", rv.Type(), f.Type())
+	}
+
+	return nil
+}
+
+// convertible 仅允许同类值之间转换，避免整数被转换为字符。
+func convertible(from reflect.Type, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+	if (from.Kind() == reflect.String) != (to.Kind() == reflect.String) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,166 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/octohelm/x/logr"
+
+	"github.com/octohelm/storage/internal/sql/adapter"
+	"github.com/octohelm/storage/internal/sql/scanner"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+)
+
+type invalidIndex struct {
+	TableName string `db:"table_name"`
+	IndexName string `db:"index_name"`
+}
+
+type indexBuildProgress struct {
+	Phase       string `db:"phase"`
+	BlocksDone  int64  `db:"blocks_done"`
+	BlocksTotal int64  `db:"blocks_total"`
+}
+
+func queryInvalidIndexes(ctx context.Context, a adapter.Adapter, d adapter.ConcurrentIndexDialect, tableNames ...string) ([]invalidIndex, error) {
+	if len(tableNames) == 0 {
+		return nil, nil
+	}
+
+	rows, err := a.Query(ctx, d.InvalidIndexes(tableNames...))
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]invalidIndex, 0)
+	if err := scanner.Scan(ctx, rows, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// dropInvalidIndexes 清理目标表上由失败的并发建索引遗留的无效索引，使其在本次迁移中重建。
+func dropInvalidIndexes(ctx context.Context, a adapter.Adapter, d adapter.ConcurrentIndexDialect, toCatalog sqlbuilder.Catalog) error {
+	list, err := queryInvalidIndexes(ctx, a, d, slices.Sorted(sqlbuilder.TableNames(toCatalog))...)
+	if err != nil {
+		return fmt.Errorf("query invalid indexes failed: %w", err)
+	}
+
+	for _, idx := range list {
+		key := sqlbuilder.Index(idx.IndexName, nil).Of(toCatalog.Table(idx.TableName))
+
+		logr.FromContext(ctx).WithValues("index", fmt.Sprint(key)).Warn(fmt.Errorf("drop invalid index left by failed build"))
+
+		if _, err := a.Exec(ctx, d.DropIndexConcurrently(key)); err != nil {
+			return fmt.Errorf("drop invalid index %s failed: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// createIndexConcurrently 在事务外创建索引，构建完成后校验索引有效性，无效时删除并返回错误。
+func createIndexConcurrently(ctx context.Context, a adapter.Adapter, d adapter.ConcurrentIndexDialect, key sqlbuilder.Key, progressInterval time.Duration) error {
+	t := sqlbuilder.GetKeyTable(key)
+
+	if progressInterval > 0 {
+		done := make(chan struct{})
+		defer close(done)
+
+		go watchIndexBuildProgress(ctx, a, d, key, progressInterval, done)
+	}
+
+	if _, err := a.Exec(ctx, d.AddIndexConcurrently(key)); err != nil {
+		// failed concurrent build leaves an invalid index
+		if _, dropErr := a.Exec(ctx, d.DropIndexConcurrently(key)); dropErr != nil {
+			return fmt.Errorf("%w, and drop invalid index failed: %w", err, dropErr)
+		}
+		return err
+	}
+
+	list, err := queryInvalidIndexes(ctx, a, d, t.TableName())
+	if err != nil {
+		return fmt.Errorf("query invalid indexes failed: %w", err)
+	}
+
+	if slices.ContainsFunc(list, func(idx invalidIndex) bool { return idx.IndexName == key.Name() }) {
+		if _, err := a.Exec(ctx, d.DropIndexConcurrently(key)); err != nil {
+			return fmt.Errorf("index is invalid after build, and drop failed: %w", err)
+		}
+		return fmt.Errorf("index is invalid after build")
+	}
+
+	return nil
+}
+
+// replaceIndexConcurrently 以临时名在事务外建出变更后的索引，再在短事务内删除旧索引并把新索引改名，替换期间旧索引一直可用。
+func replaceIndexConcurrently(ctx context.Context, a adapter.Adapter, d adapter.ConcurrentIndexDialect, key sqlbuilder.Key, progressInterval time.Duration) error {
+	next := nextKeyOf(key)
+
+	if err := createIndexConcurrently(ctx, a, d, next, progressInterval); err != nil {
+		return err
+	}
+
+	return a.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := a.Exec(ctx, a.Dialect().DropIndex(key)); err != nil {
+			return err
+		}
+		if _, err := a.Exec(ctx, d.RenameIndex(next, key)); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// nextKeyOf 返回与 key 定义相同、以临时名命名的索引。
+func nextKeyOf(key sqlbuilder.Key) sqlbuilder.Key {
+	optFns := []sqlbuilder.IndexOptionFunc{sqlbuilder.IndexUnique(key.IsUnique())}
+
+	if keyDef := sqlbuilder.GetKeyDef(key); keyDef != nil {
+		optFns = append(optFns,
+			sqlbuilder.IndexUsing(keyDef.Method()),
+			sqlbuilder.IndexWhere(keyDef.Where()),
+			sqlbuilder.IndexFieldNameAndOptions(keyDef.FieldNameAndOptions()...),
+		)
+	}
+
+	return sqlbuilder.Index(adapter.NextKeyName(key.Name()), nil, optFns...).Of(sqlbuilder.GetKeyTable(key))
+}
+
+func watchIndexBuildProgress(ctx context.Context, a adapter.Adapter, d adapter.ConcurrentIndexDialect, key sqlbuilder.Key, interval time.Duration, done <-chan struct{}) {
+	logger := logr.FromContext(ctx).WithValues("index", fmt.Sprint(key))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rows, err := a.Query(ctx, d.IndexBuildProgress(sqlbuilder.GetKeyTable(key)))
+			if err != nil {
+				logger.Warn(fmt.Errorf("query index build progress failed: %w", err))
+				continue
+			}
+
+			list := make([]indexBuildProgress, 0)
+			if err := scanner.Scan(ctx, rows, &list); err != nil {
+				logger.Warn(fmt.Errorf("scan index build progress failed: %w", err))
+				continue
+			}
+
+			for _, p := range list {
+				logger.WithValues(
+					"phase", p.Phase,
+					"blocks_done", p.BlocksDone,
+					"blocks_total", p.BlocksTotal,
+				).Info("building index concurrently")
+			}
+		}
+	}
+}
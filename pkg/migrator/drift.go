@@ -0,0 +1,315 @@
+package migrator
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/octohelm/storage/internal/sql/adapter"
+	"github.com/octohelm/storage/pkg/migrator/internal"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+)
+
+// DriftKind 表示结构偏差的类型。
+type DriftKind string
+
+const (
+	// DriftTableMissing 目标表在数据库中不存在
+	DriftTableMissing DriftKind = "table_missing"
+	// DriftTableExtra 数据库中存在未声明的表
+	DriftTableExtra DriftKind = "table_extra"
+	// DriftColumnMissing 目标列在数据库中不存在
+	DriftColumnMissing DriftKind = "column_missing"
+	// DriftColumnExtra 数据库中存在未声明的列，迁移不会删除
+	DriftColumnExtra DriftKind = "column_extra"
+	// DriftColumnType 列类型不一致
+	DriftColumnType DriftKind = "column_type"
+	// DriftColumnNull 列可空性不一致
+	DriftColumnNull DriftKind = "column_null"
+	// DriftColumnDefault 列默认值不一致
+	DriftColumnDefault DriftKind = "column_default"
+	// DriftColumnEnum 数据库中的枚举取值缺少目标取值
+	DriftColumnEnum DriftKind = "column_enum"
+	// DriftIndexMissing 目标索引在数据库中不存在
+	DriftIndexMissing DriftKind = "index_missing"
+	// DriftIndexExtra 数据库中存在未声明的索引
+	DriftIndexExtra DriftKind = "index_extra"
+	// DriftIndexChanged 索引列或唯一性不一致
+	DriftIndexChanged DriftKind = "index_changed"
+	// DriftCheckMissing 目标 CHECK 约束在数据库中不存在
+	DriftCheckMissing DriftKind = "check_missing"
+	// DriftCheckExtra 数据库中存在未声明的 CHECK 约束
+	DriftCheckExtra DriftKind = "check_extra"
+	// DriftCheckChanged CHECK 约束表达式不一致
+	DriftCheckChanged DriftKind = "check_changed"
+)
+
+// Drift 表示一项结构偏差，Expected 为代码声明，Actual 为数据库当前值。
+type Drift struct {
+	Kind     DriftKind `json:"kind"`
+	Table    string    `json:"table"`
+	Column   string    `json:"column,omitzero"`
+	Index    string    `json:"index,omitzero"`
+	Check    string    `json:"check,omitzero"`
+	Expected string    `json:"expected,omitzero"`
+	Actual   string    `json:"actual,omitzero"`
+}
+
+func (d Drift) String() string {
+	b := &strings.Builder{}
+
+	b.WriteString(string(d.Kind))
+	b.WriteString(" ")
+	b.WriteString(d.Table)
+
+	if d.Column != "" {
+		b.WriteString(".")
+		b.WriteString(d.Column)
+	}
+
+	if d.Index != "" {
+		b.WriteString(" index ")
+		b.WriteString(d.Index)
+	}
+
+	if d.Check != "" {
+		b.WriteString(" check ")
+		b.WriteString(d.Check)
+	}
+
+	if d.Expected != "" || d.Actual != "" {
+		_, _ = fmt.Fprintf(b, ": expected %q, actual %q", d.Expected, d.Actual)
+	}
+
+	return b.String()
+}
+
+// DriftReport 表示目标 catalog 与数据库当前结构之间的偏差报告。
+type DriftReport struct {
+	Drifts []Drift `json:"drifts"`
+}
+
+// HasDrift 判断是否存在偏差。
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Drifts) > 0
+}
+
+func (r *DriftReport) String() string {
+	b := &strings.Builder{}
+	for _, d := range r.Drifts {
+		b.WriteString(d.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (r *DriftReport) add(d Drift) {
+	r.Drifts = append(r.Drifts, d)
+}
+
+// DetectDrift 比较目标 catalog 与数据库当前结构，仅读取 catalog，不执行迁移或任何 DDL，可用于只读角色与热备库。
+// 会改写表达式存储形式的数据库（如 PostgreSQL）按方言的文本规则改写两侧表达式后再比较。
+func DetectDrift(ctx context.Context, a adapter.Adapter, toCatalog sqlbuilder.Catalog) (*DriftReport, error) {
+	fromTables, err := a.Catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return CompareCatalog(a.Dialect(), fromTables, toCatalog), nil
+}
+
+// CompareCatalog 比较当前 catalog 与目标 catalog，并按表、类型与名称排序返回偏差。
+func CompareCatalog(dialect adapter.Dialect, current sqlbuilder.Catalog, next sqlbuilder.Catalog) *DriftReport {
+	r := &DriftReport{
+		Drifts: make([]Drift, 0),
+	}
+
+	for _, name := range slices.Sorted(sqlbuilder.TableNames(next)) {
+		currentTable := current.Table(name)
+		if currentTable == nil {
+			r.add(Drift{Kind: DriftTableMissing, Table: name})
+			continue
+		}
+
+		c := &tableComparer{dialect: dialect, report: r}
+		c.compare(currentTable, next.Table(name))
+	}
+
+	for _, name := range slices.Sorted(sqlbuilder.TableNames(current)) {
+		if next.Table(name) == nil {
+			r.add(Drift{Kind: DriftTableExtra, Table: name})
+		}
+	}
+
+	slices.SortStableFunc(r.Drifts, func(a, b Drift) int {
+		return cmp.Or(
+			cmp.Compare(a.Table, b.Table),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Column, b.Column),
+			cmp.Compare(a.Index, b.Index),
+			cmp.Compare(a.Check, b.Check),
+		)
+	})
+
+	return r
+}
+
+type tableComparer struct {
+	dialect adapter.Dialect
+	report  *DriftReport
+}
+
+// compare 以迁移的比较结果（internal.Changes）判断偏差，再补充迁移有意忽略的多余列，并渲染期望值与实际值。
+func (c *tableComparer) compare(currentTable sqlbuilder.Table, nextTable sqlbuilder.Table) {
+	tableName := nextTable.TableName()
+
+	// collect before diff, which adds renamed columns into current table
+	for currentCol := range currentTable.Cols() {
+		if nextCol := nextTable.F(currentCol.Name()); nextCol == nil || sqlbuilder.GetColumnDef(nextCol).DeprecatedActions != nil {
+			c.report.add(Drift{Kind: DriftColumnExtra, Table: tableName, Column: currentCol.Name()})
+		}
+	}
+
+	// some catalog (like sqlite) not expose primary key
+	currentHasPrimary := hasPrimary(currentTable)
+	nextHasPrimary := hasPrimary(nextTable)
+
+	for _, change := range internal.Changes(c.dialect, currentTable, nextTable) {
+		switch change.Type {
+		case internal.ChangeColumnAdded:
+			c.report.add(Drift{Kind: DriftColumnMissing, Table: tableName, Column: change.Name})
+		case internal.ChangeColumnModified:
+			c.compareColumn(tableName, change.Name, sqlbuilder.GetColumnDef(nextTable.F(change.Name)), sqlbuilder.GetColumnDef(currentTable.F(change.Name)))
+		case internal.ChangeColumnEnumAdded:
+			nextDef, currentDef := sqlbuilder.GetColumnDef(nextTable.F(change.Name)), sqlbuilder.GetColumnDef(currentTable.F(change.Name))
+			c.report.add(Drift{Kind: DriftColumnEnum, Table: tableName, Column: change.Name, Expected: strings.Join(nextDef.Enum.Values, ","), Actual: strings.Join(currentDef.Enum.Values, ",")})
+		case internal.ChangeIndexAdded:
+			if key := nextTable.K(change.Name); !key.IsPrimary() || currentHasPrimary {
+				c.report.add(Drift{Kind: DriftIndexMissing, Table: tableName, Index: change.Name, Expected: c.indexDefOf(key)})
+			}
+		case internal.ChangeIndexChanged:
+			c.report.add(Drift{Kind: DriftIndexChanged, Table: tableName, Index: change.Name, Expected: c.indexDefOf(nextTable.K(change.Name)), Actual: c.indexDefOf(currentTable.K(change.Name))})
+		case internal.ChangeIndexDropped:
+			// postgres exposes primary key both as constraint and as {table}_pkey index
+			if key := currentTable.K(change.Name); !key.IsPrimary() || !nextHasPrimary {
+				c.report.add(Drift{Kind: DriftIndexExtra, Table: tableName, Index: change.Name, Actual: c.indexDefOf(key)})
+			}
+		case internal.ChangeCheckAdded:
+			c.report.add(Drift{Kind: DriftCheckMissing, Table: tableName, Check: change.Name, Expected: checkExprOf(nextTable, change.Name)})
+		case internal.ChangeCheckChanged:
+			c.report.add(Drift{Kind: DriftCheckChanged, Table: tableName, Check: change.Name, Expected: checkExprOf(nextTable, change.Name), Actual: checkExprOf(currentTable, change.Name)})
+		case internal.ChangeCheckDropped:
+			c.report.add(Drift{Kind: DriftCheckExtra, Table: tableName, Check: change.Name, Actual: checkExprOf(currentTable, change.Name)})
+		}
+	}
+}
+
+// compareColumn 把迁移判定需修改的列拆分为类型、可空性与默认值偏差。
+func (c *tableComparer) compareColumn(tableName string, columnName string, nextDef sqlbuilder.ColumnDef, currentDef sqlbuilder.ColumnDef) {
+	nextDef, currentDef = internal.CanonicalColumnDef(c.dialect, nextDef), internal.CanonicalColumnDef(c.dialect, currentDef)
+
+	if expected, actual := c.typeOf(nextDef), c.typeOf(currentDef); !strings.EqualFold(expected, actual) {
+		c.report.add(Drift{Kind: DriftColumnType, Table: tableName, Column: columnName, Expected: expected, Actual: actual})
+	}
+
+	// generated column nullability follows its expression
+	if nextDef.Null != currentDef.Null && nextDef.Generated == nil {
+		c.report.add(Drift{Kind: DriftColumnNull, Table: tableName, Column: columnName, Expected: nullability(nextDef), Actual: nullability(currentDef)})
+	}
+
+	// render current default with next column type, to ignore type casting differences
+	withCurrentDefault := nextDef
+	withCurrentDefault.Default = currentDef.Default
+
+	if expected, actual := c.defaultOf(nextDef), c.defaultOf(withCurrentDefault); !strings.EqualFold(expected, actual) {
+		c.report.add(Drift{Kind: DriftColumnDefault, Table: tableName, Column: columnName, Expected: expected, Actual: actual})
+	}
+}
+
+func (c *tableComparer) typeOf(def sqlbuilder.ColumnDef) string {
+	def.Null = true
+	def.Default = nil
+	return c.render(def)
+}
+
+func (c *tableComparer) defaultOf(def sqlbuilder.ColumnDef) string {
+	def.Null = true
+	if _, dv, ok := strings.Cut(c.render(def), " DEFAULT "); ok {
+		return dv
+	}
+	return ""
+}
+
+func (c *tableComparer) render(def sqlbuilder.ColumnDef) string {
+	s, _ := sqlfrag.Collect(context.Background(), c.dialect.DataType(def))
+	return strings.TrimSpace(s)
+}
+
+func (c *tableComparer) indexDefOf(key sqlbuilder.Key) string {
+	keyDef := sqlbuilder.GetKeyDef(key)
+	if keyDef == nil {
+		return c.indexColumnsOf(key)
+	}
+
+	parts := []string{c.columnNamesOf(key)}
+	for _, fo := range keyDef.FieldNameAndOptions() {
+		if fo.IsExpr() {
+			parts = append(parts, fo.Name())
+		}
+	}
+
+	def := "(" + strings.Join(slices.DeleteFunc(parts, func(s string) bool { return s == "" }), ",") + ")"
+	if key.IsUnique() {
+		def = "UNIQUE " + def
+	}
+
+	if where := keyDef.Where(); where != "" {
+		def += " WHERE " + where
+	}
+
+	return def
+}
+
+func (c *tableComparer) indexColumnsOf(key sqlbuilder.Key) string {
+	if key.IsUnique() {
+		return "UNIQUE (" + c.columnNamesOf(key) + ")"
+	}
+	return "(" + c.columnNamesOf(key) + ")"
+}
+
+func (c *tableComparer) columnNamesOf(key sqlbuilder.Key) string {
+	cols := slices.SortedFunc(key.Cols(), func(a sqlbuilder.Column, b sqlbuilder.Column) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+
+	def, _ := sqlfrag.Collect(context.Background(), sqlbuilder.ColumnCollect(slices.Values(cols)))
+	return def
+}
+
+func checkExprOf(t sqlbuilder.Table, name string) string {
+	for check := range sqlbuilder.GetTableChecks(t) {
+		if check.Name() == name {
+			return check.Expr()
+		}
+	}
+	return ""
+}
+
+func hasPrimary(t sqlbuilder.Table) bool {
+	for key := range t.Keys() {
+		if key.IsPrimary() {
+			return true
+		}
+	}
+	return false
+}
+
+func nullability(def sqlbuilder.ColumnDef) string {
+	if def.Null {
+		return "NULL"
+	}
+	return "NOT NULL"
+}
@@ -0,0 +1,212 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/octohelm/x/ptr"
+	. "github.com/octohelm/x/testing/v2"
+	typex "github.com/octohelm/x/types"
+
+	internaladapter "github.com/octohelm/storage/internal/sql/adapter"
+	"github.com/octohelm/storage/internal/sql/adapter/sqlite"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/testdata/model"
+)
+
+func TestDetectDrift(t *testing.T) {
+	ctx := context.Background()
+
+	u, _ := url.Parse(fmt.Sprintf("sqlite://%s", filepath.Join(t.TempDir(), "sqlite.db")))
+
+	a := MustValue(t, func() (internaladapter.Adapter, error) {
+		return sqlite.Open(ctx, u)
+	})
+	t.Cleanup(func() {
+		_ = a.Close()
+	})
+
+	target := &sqlbuilder.Tables{}
+	target.Add(sqlbuilder.TableFromModel(&model.User{}))
+
+	Then(
+		t, "按目标 catalog 建表后没有偏差",
+		ExpectDo(func() error {
+			return CreateTables(ctx, a, target)
+		}),
+		ExpectMustValue(
+			func() (bool, error) {
+				r, err := DetectDrift(ctx, a, target)
+				if err != nil {
+					return false, err
+				}
+				return r.HasDrift(), nil
+			},
+			Equal(false),
+		),
+	)
+
+	for _, stmt := range []string{
+		"ALTER TABLE t_user ADD COLUMN f_hotfix TEXT",
+		"CREATE INDEX t_user_i_hotfix ON t_user (f_hotfix)",
+		"DROP INDEX t_user_i_nickname",
+		"CREATE TABLE t_org (f_id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL, f_name TEXT, f_created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP, f_updated_at INTEGER NOT NULL DEFAULT '0', f_deleted_at BIGINT NOT NULL DEFAULT '1')",
+		"CREATE INDEX t_org_i_name ON t_org (f_name)",
+		"CREATE TABLE t_legacy (f_id INTEGER)",
+	} {
+		_ = MustValue(t, func() (any, error) {
+			return a.Exec(ctx, sqlfrag.Pair(stmt))
+		})
+	}
+
+	target.Add(sqlbuilder.TableFromModel(&model.Org{}))
+
+	r := MustValue(t, func() (*DriftReport, error) {
+		return DetectDrift(ctx, a, target)
+	})
+
+	Then(
+		t, "报告数据库中手工变更导致的偏差，且不执行迁移",
+		Expect(r.String(), Equal(`column_default t_org.f_deleted_at: expected "'0'", actual "'1'"
+column_default t_org.f_name: expected "''", actual ""
+column_null t_org.f_name: expected "NOT NULL", actual "NULL"
+column_type t_org.f_updated_at: expected "BIGINT", actual "INTEGER"
+index_changed t_org index i_name: expected "UNIQUE (f_name)", actual "(f_name)"
+index_missing t_org index i_created_at: expected "(f_created_at)", actual ""
+table_extra t_legacy
+column_extra t_user.f_hotfix
+index_extra t_user index i_hotfix: expected "", actual "(f_hotfix)"
+index_missing t_user index i_nickname: expected "(f_nickname)", actual ""
+`)),
+	)
+}
+
+func TestDetectDriftWithCheckAndGenerated(t *testing.T) {
+	ctx := context.Background()
+
+	u, _ := url.Parse(fmt.Sprintf("sqlite://%s", filepath.Join(t.TempDir(), "sqlite.db")))
+
+	a := MustValue(t, func() (internaladapter.Adapter, error) {
+		return sqlite.Open(ctx, u)
+	})
+	t.Cleanup(func() {
+		_ = a.Close()
+	})
+
+	orderTable := func(defs ...sqlfrag.Fragment) sqlbuilder.Table {
+		return sqlbuilder.T("t_order", append([]sqlfrag.Fragment{
+			sqlbuilder.Col("f_price", sqlbuilder.ColTypeOf(int64(0), "")),
+			sqlbuilder.Col("f_total", sqlbuilder.ColDef(sqlbuilder.ColumnDef{
+				Type:      typex.FromRType(reflect.TypeFor[int64]()),
+				Generated: &sqlbuilder.ColumnGenerated{Expr: "f_price * 2", Stored: true},
+			})),
+			sqlbuilder.Index("i_price_expr", nil, sqlbuilder.IndexFieldNameAndOptions("(f_price + 1),desc")),
+		}, defs...)...)
+	}
+
+	target := &sqlbuilder.Tables{}
+	target.Add(orderTable(
+		sqlbuilder.UniqueIndex("i_price", sqlbuilder.Cols("f_price"), sqlbuilder.IndexWhere("f_price > 0")),
+		sqlbuilder.CheckConstraint("ck_price", "f_price>=0"),
+		sqlbuilder.CheckConstraint("ck_total", "f_total IN (0, 2)"),
+	))
+
+	Then(
+		t, "建表后 CHECK 约束、生成列、部分索引与表达式索引可从 catalog 还原，没有偏差",
+		ExpectDo(func() error {
+			return CreateTables(ctx, a, target)
+		}),
+		ExpectMustValue(
+			func() (string, error) {
+				r, err := DetectDrift(ctx, a, target)
+				if err != nil {
+					return "", err
+				}
+				return r.String(), nil
+			},
+			Equal(""),
+		),
+	)
+
+	next := &sqlbuilder.Tables{}
+	next.Add(orderTable(
+		sqlbuilder.UniqueIndex("i_price", sqlbuilder.Cols("f_price"), sqlbuilder.IndexWhere("f_price > 1")),
+		sqlbuilder.CheckConstraint("ck_price", "f_price > 0"),
+		sqlbuilder.CheckConstraint("ck_positive", "f_total >= 0"),
+	))
+
+	r := MustValue(t, func() (*DriftReport, error) {
+		return DetectDrift(ctx, a, next)
+	})
+
+	Then(
+		t, "报告 CHECK 约束的缺失、多余与变更，以及部分索引谓词变更",
+		Expect(r.String(), Equal(`check_changed t_order check ck_price: expected "f_price > 0", actual "f_price >= 0"
+check_extra t_order check ck_total: expected "", actual "f_total IN ( 0 , 2 )"
+check_missing t_order check ck_positive: expected "f_total >= 0", actual ""
+index_changed t_order index i_price: expected "UNIQUE (f_price) WHERE f_price > 1", actual "UNIQUE (f_price) WHERE f_price > 0"
+`)),
+	)
+}
+
+func TestDetectDriftWithEnum(t *testing.T) {
+	ctx := context.Background()
+
+	u, _ := url.Parse(fmt.Sprintf("sqlite://%s", filepath.Join(t.TempDir(), "sqlite.db")))
+
+	a := MustValue(t, func() (internaladapter.Adapter, error) {
+		return sqlite.Open(ctx, u)
+	})
+	t.Cleanup(func() {
+		_ = a.Close()
+	})
+
+	userTable := func(values ...string) *sqlbuilder.Tables {
+		tables := &sqlbuilder.Tables{}
+		tables.Add(sqlbuilder.T("t_user",
+			sqlbuilder.Col("f_id", sqlbuilder.ColTypeOf(int64(0), "")),
+			sqlbuilder.Col("f_gender", sqlbuilder.ColDef(sqlbuilder.ColumnDef{
+				Type:    typex.FromRType(reflect.TypeFor[int64]()),
+				Default: ptr.Ptr("'0'"),
+				Enum:    &sqlbuilder.ColumnEnum{Values: values},
+			})),
+		))
+		return tables
+	}
+
+	Then(
+		t, "枚举取值增加时报告偏差，迁移重建列后没有偏差",
+		ExpectDo(func() error {
+			return CreateTables(ctx, a, userTable("0", "1"))
+		}),
+		ExpectMustValue(
+			func() (string, error) {
+				r, err := DetectDrift(ctx, a, userTable("0", "1", "2"))
+				if err != nil {
+					return "", err
+				}
+				return r.String(), nil
+			},
+			Equal(`column_enum t_user.f_gender: expected "0,1,2", actual "0,1"
+`),
+		),
+		ExpectDo(func() error {
+			return Migrate(ctx, a, userTable("0", "1", "2"))
+		}),
+		ExpectMustValue(
+			func() (string, error) {
+				r, err := DetectDrift(ctx, a, userTable("0", "1", "2"))
+				if err != nil {
+					return "", err
+				}
+				return r.String(), nil
+			},
+			Equal(""),
+		),
+	)
+}
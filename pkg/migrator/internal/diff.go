@@ -3,6 +3,7 @@ package internal
 import (
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"slices"
@@ -16,16 +17,49 @@ import (
 type actionType int
 
 const (
-	dropTableIndex actionType = iota
+	dropTableCheck actionType = iota
+	dropTableIndex
 	dropTableColumn
 	keepTableColumn
 	renameTableColumn
+	addTableEnumValue
 	modifyTableColumn
 	addTableColumn
 	addTableIndex
+	addTableCheck
 	createTable
 )
 
+// ChangeType 表示比较当前表与目标表得出的结构变更类型。
+type ChangeType int
+
+const (
+	// ChangeColumnAdded 目标列在当前表中不存在
+	ChangeColumnAdded ChangeType = iota
+	// ChangeColumnModified 列类型、可空性、默认值或生成表达式不一致
+	ChangeColumnModified
+	// ChangeColumnEnumAdded 目标枚举取值多于当前枚举
+	ChangeColumnEnumAdded
+	// ChangeIndexAdded 目标索引在当前表中不存在
+	ChangeIndexAdded
+	// ChangeIndexChanged 索引列、表达式或部分索引谓词不一致
+	ChangeIndexChanged
+	// ChangeIndexDropped 当前表中存在未声明的索引
+	ChangeIndexDropped
+	// ChangeCheckAdded 目标 CHECK 约束在当前表中不存在
+	ChangeCheckAdded
+	// ChangeCheckChanged CHECK 约束表达式不一致
+	ChangeCheckChanged
+	// ChangeCheckDropped 当前表中存在未声明的 CHECK 约束
+	ChangeCheckDropped
+)
+
+// Change 表示一项结构变更，Name 为列、索引或 CHECK 约束名；方言无法生成迁移片段的变更（如 sqlite 的 CHECK 约束变更）同样记录。
+type Change struct {
+	Type ChangeType
+	Name string
+}
+
 var _ sqlfrag.Fragment = &Action{}
 
 // Action 表示一条结构迁移动作。
@@ -49,6 +83,20 @@ type diff struct {
 
 	indexes map[string]bool
 	columns map[string]actionType
+
+	// normalized 为数据库改写后的目标表，存在时以其中的表达式与当前表比较
+	normalized sqlbuilder.Table
+	// canonical 把两侧表达式改写为可比较的形式，仅偏差检测使用
+	canonical func(expr string) string
+
+	concurrently   func(key sqlbuilder.Key) bool
+	concurrentKeys []sqlbuilder.Key
+	replacedKeys   []sqlbuilder.Key
+	onlineColumns  []sqlbuilder.Column
+
+	changes []Change
+
+	errs []error
 }
 
 func (d *diff) IsNil() bool {
@@ -67,10 +115,14 @@ func (d *diff) Frag(ctx context.Context) iter.Seq2[string, []any] {
 	return sqlfrag.Join("", sqlfrag.NonNil(slices.Values(actions))).Frag(ctx)
 }
 
+func (d *diff) change(typ ChangeType, name string) {
+	d.changes = append(d.changes, Change{Type: typ, Name: name})
+}
+
 func (d *diff) migrate(typ actionType, name string, fragments ...sqlfrag.Fragment) {
 	if len(fragments) > 0 {
 		switch typ {
-		case dropTableIndex, addTableIndex:
+		case dropTableIndex, addTableIndex, dropTableCheck, addTableCheck:
 			// record once to avoid duplicated action
 			changed := fmt.Sprintf("%d/%s", typ, name)
 			if _, ok := d.indexes[changed]; ok {
@@ -90,18 +142,315 @@ func (d *diff) migrate(typ actionType, name string, fragments ...sqlfrag.Fragmen
 	}
 }
 
-// Diff 比较当前表与目标表，并返回迁移片段。
+// addIndex 把需并发创建的索引留给事务外阶段，其余随事务执行。
+func (d *diff) addIndex(key sqlbuilder.Key) {
+	if !key.IsPrimary() && d.concurrently != nil && d.concurrently(key) {
+		if !slices.ContainsFunc(d.concurrentKeys, func(k sqlbuilder.Key) bool { return k.Name() == key.Name() }) {
+			d.concurrentKeys = append(d.concurrentKeys, key)
+		}
+		return
+	}
+
+	d.migrate(addTableIndex, key.Name(), d.dialect.AddIndex(key))
+}
+
+// replaceIndex 把需并发创建的已变更索引留给事务外阶段，以临时名建出新索引后再替换旧索引，避免迁移期间缺失索引；
+// 旧索引的列被删除时旧索引随事务删除，不做替换。
+func (d *diff) replaceIndex(key sqlbuilder.Key, prevKey sqlbuilder.Key) bool {
+	if d.concurrently == nil || !d.concurrently(key) {
+		return false
+	}
+
+	for col := range prevKey.Cols() {
+		if tpe, ok := d.columns[col.Name()]; ok && tpe == dropTableColumn {
+			return false
+		}
+	}
+
+	if !slices.ContainsFunc(d.replacedKeys, func(k sqlbuilder.Key) bool { return k.Name() == key.Name() }) {
+		d.replacedKeys = append(d.replacedKeys, key)
+	}
+
+	return true
+}
+
+// isOnlineChange 判断列类型变更是否走影子列在线回填，仅比较类型本身，可空性与默认值变更仍直接修改；目标表须有主键。
+func (d *diff) isOnlineChange(nextCol sqlbuilder.Column, currentCol sqlbuilder.Column) bool {
+	if _, ok := d.dialect.(adapter.OnlineColumnDialect); !ok {
+		return false
+	}
+
+	nextDef := sqlbuilder.GetColumnDef(nextCol)
+	currentDef := sqlbuilder.GetColumnDef(currentCol)
+
+	if !nextDef.Online || nextDef.AutoIncrement || nextDef.Generated != nil || currentDef.Generated != nil {
+		return false
+	}
+
+	// backfill pages by primary key
+	if !hasPrimaryKey(sqlbuilder.GetColumnTable(nextCol)) {
+		return false
+	}
+
+	typeOf := func(def sqlbuilder.ColumnDef) string {
+		def.Null = true
+		def.Default = nil
+		s, _ := sqlfrag.Collect(context.Background(), d.dialect.DataType(def))
+		return s
+	}
+
+	return !strings.EqualFold(typeOf(nextDef), typeOf(currentDef))
+}
+
+func hasPrimaryKey(t sqlbuilder.Table) bool {
+	for key := range t.Keys() {
+		if key.IsPrimary() {
+			return true
+		}
+	}
+	return false
+}
+
+// addEnumValues 在目标枚举取值多于当前枚举类型时追加新增取值，取值减少不做处理；
+// 方言不支持原生枚举时（如 sqlite 以列级 CHECK 约束限定取值）重建该列，返回是否已重建。
+func (d *diff) addEnumValues(nextCol sqlbuilder.Column, currentCol sqlbuilder.Column) bool {
+	nextEnum := sqlbuilder.GetColumnDef(nextCol).Enum
+	currentEnum := sqlbuilder.GetColumnDef(currentCol).Enum
+
+	if nextEnum == nil || currentEnum == nil {
+		return false
+	}
+
+	added := slices.DeleteFunc(slices.Clone(nextEnum.Values), func(v string) bool {
+		return slices.Contains(currentEnum.Values, v)
+	})
+
+	if len(added) == 0 {
+		return false
+	}
+
+	d.change(ChangeColumnEnumAdded, nextCol.Name())
+
+	enumDialect, ok := d.dialect.(adapter.EnumDialect)
+	if !ok {
+		// column check could not be altered, only to recreate column
+		if !d.addable(nextCol) {
+			return false
+		}
+		d.columns[nextCol.Name()] = modifyTableColumn
+		d.migrate(modifyTableColumn, nextCol.Name(), d.dialect.ModifyColumn(nextCol, currentCol))
+		return true
+	}
+
+	if nextEnum.Name == currentEnum.Name {
+		d.migrateNonNil(addTableEnumValue, nextCol.Name(), enumDialect.AddEnumValues(nextCol, added))
+	}
+
+	return false
+}
+
+// normalizedKey 返回数据库改写后的同名索引，未规范化时返回 key 本身。
+func (d *diff) normalizedKey(key sqlbuilder.Key) sqlbuilder.Key {
+	if d.normalized != nil {
+		if k := d.normalized.K(key.Name()); k != nil {
+			return k
+		}
+	}
+	return key
+}
+
+// normalizedCheck 返回数据库改写后的同名 CHECK 约束，未规范化时返回 check 本身。
+func (d *diff) normalizedCheck(check sqlbuilder.Check) sqlbuilder.Check {
+	if d.normalized != nil {
+		for c := range sqlbuilder.GetTableChecks(d.normalized) {
+			if c.Name() == check.Name() {
+				return c
+			}
+		}
+	}
+	return check
+}
+
+func (d *diff) normalizedColumnDef(col sqlbuilder.Column) sqlbuilder.ColumnDef {
+	def := sqlbuilder.GetColumnDef(col)
+
+	if d.normalized != nil && def.Generated != nil {
+		if c := d.normalized.F(col.Name()); c != nil && sqlbuilder.GetColumnDef(c).Generated != nil {
+			return d.canonicalColumnDef(sqlbuilder.GetColumnDef(c))
+		}
+	}
+
+	return d.canonicalColumnDef(def)
+}
+
+func (d *diff) canonicalColumnDef(def sqlbuilder.ColumnDef) sqlbuilder.ColumnDef {
+	if d.canonical != nil && def.Generated != nil {
+		generated := *def.Generated
+		generated.Expr = d.canonical(generated.Expr)
+		def.Generated = &generated
+	}
+	return def
+}
+
+// CanonicalColumnDef 以方言的 adapter.ExprCanonicalizer 改写生成列表达式，方言未实现时原样返回。
+func CanonicalColumnDef(dialect adapter.Dialect, def sqlbuilder.ColumnDef) sqlbuilder.ColumnDef {
+	return (&diff{canonical: canonicalOf(dialect)}).canonicalColumnDef(def)
+}
+
+func canonicalOf(dialect adapter.Dialect) func(expr string) string {
+	if c, ok := dialect.(adapter.ExprCanonicalizer); ok {
+		return c.CanonicalExpr
+	}
+	return nil
+}
+
+func (d *diff) exprEqual(a string, b string) bool {
+	if d.canonical != nil {
+		return adapter.ExprEqual(d.canonical(a), d.canonical(b))
+	}
+	return adapter.ExprEqual(a, b)
+}
+
+func (d *diff) keyExprEqual(a sqlbuilder.Key, b sqlbuilder.Key) bool {
+	exprsA, whereA := keyExprsAndWhere(a)
+	exprsB, whereB := keyExprsAndWhere(b)
+
+	if !d.exprEqual(whereA, whereB) {
+		return false
+	}
+
+	return slices.EqualFunc(exprsA, exprsB, d.exprEqual)
+}
+
+// addable 判断方言能否为已有表添加该列，不能时（如 sqlite 的 STORED 生成列）记录错误。
+func (d *diff) addable(col sqlbuilder.Column) bool {
+	if sqlbuilder.GetColumnDef(col).Generated == nil || !sqlfrag.IsNil(d.dialect.AddColumn(col)) {
+		return true
+	}
+
+	d.errs = append(d.errs, fmt.Errorf("generated column %s.%s could not be added to exists table, table rebuild required", sqlbuilder.GetColumnTable(col).TableName(), col.Name()))
+
+	return false
+}
+
+// KeyExprEqual 比较两个索引的表达式项与部分索引谓词，忽略空白与外层括号。
+// 会改写表达式的数据库（如 PostgreSQL）应先以 adapter.ExprNormalizer 规范化声明的一侧。
+func KeyExprEqual(a sqlbuilder.Key, b sqlbuilder.Key) bool {
+	return (&diff{}).keyExprEqual(a, b)
+}
+
+// HasExpr 判断表中是否有表达式索引项、部分索引、CHECK 约束或生成列。
+func HasExpr(t sqlbuilder.Table) bool {
+	for key := range t.Keys() {
+		if exprs, where := keyExprsAndWhere(key); len(exprs) > 0 || where != "" {
+			return true
+		}
+	}
+
+	for range sqlbuilder.GetTableChecks(t) {
+		return true
+	}
+
+	for col := range t.Cols() {
+		if sqlbuilder.GetColumnDef(col).Generated != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func keyExprsAndWhere(key sqlbuilder.Key) (exprs []string, where string) {
+	keyDef := sqlbuilder.GetKeyDef(key)
+	if keyDef == nil {
+		return nil, ""
+	}
+
+	for _, fo := range keyDef.FieldNameAndOptions() {
+		if fo.IsExpr() {
+			exprs = append(exprs, string(fo))
+		}
+	}
+
+	return exprs, keyDef.Where()
+}
+
+// migrateNonNil 忽略方言不支持而返回 nil 的片段，如 sqlite 的 CHECK 约束变更。
+func (d *diff) migrateNonNil(typ actionType, name string, fragments ...sqlfrag.Fragment) {
+	d.migrate(typ, name, slices.DeleteFunc(fragments, sqlfrag.IsNil)...)
+}
+
+// Plan 表示单张表的迁移计划。
+type Plan struct {
+	// Migration 为随迁移事务执行的片段
+	Migration sqlfrag.Fragment
+	// ConcurrentKeys 为需在事务外并发创建的索引
+	ConcurrentKeys []sqlbuilder.Key
+	// ReplacedKeys 为需在事务外以临时名并发重建后替换旧索引的已变更索引
+	ReplacedKeys []sqlbuilder.Key
+	// OnlineColumns 为需以影子列在线变更类型的目标列
+	OnlineColumns []sqlbuilder.Column
+}
+
+// Diff 比较当前表与目标表，并返回迁移片段；无法迁移时 panic。
 func Diff(dialect adapter.Dialect, currentTable sqlbuilder.Table, nextTable sqlbuilder.Table) sqlfrag.Fragment {
+	plan, err := DiffPlan(dialect, currentTable, nextTable, nil, nil)
+	if err != nil {
+		panic(err)
+	}
+	return plan.Migration
+}
+
+// DiffPlan 比较当前表与目标表并返回迁移计划。
+// concurrently 命中的已有表新增索引不进入迁移片段；方言支持在线变更时，声明 online 的列类型变更也不进入迁移片段。
+// normalized 为经 adapter.ExprNormalizer 改写后的目标表，可为 nil。
+// 方言无法原地完成的变更（如 sqlite 为已有表添加 STORED 生成列）返回错误。
+func DiffPlan(dialect adapter.Dialect, currentTable sqlbuilder.Table, nextTable sqlbuilder.Table, concurrently func(key sqlbuilder.Key) bool, normalized sqlbuilder.Table) (*Plan, error) {
+	d := &diff{
+		dialect:      dialect,
+		indexes:      make(map[string]bool),
+		columns:      make(map[string]actionType),
+		normalized:   normalized,
+		concurrently: concurrently,
+	}
+
+	d.diff(currentTable, nextTable)
+
+	if len(d.errs) > 0 {
+		return nil, errors.Join(d.errs...)
+	}
+
+	return &Plan{
+		Migration:      d,
+		ConcurrentKeys: d.concurrentKeys,
+		ReplacedKeys:   d.replacedKeys,
+		OnlineColumns:  d.onlineColumns,
+	}, nil
+}
+
+// Changes 比较当前表与目标表并返回结构变更，判断与 DiffPlan 一致，供只读的偏差检测使用；
+// 不生成迁移计划，方言无法迁移的变更同样返回。比较会把重命名的目标列加入 currentTable。
+// 不访问数据库，方言实现 adapter.ExprCanonicalizer 时以其改写两侧表达式后再比较。
+func Changes(dialect adapter.Dialect, currentTable sqlbuilder.Table, nextTable sqlbuilder.Table) []Change {
 	d := &diff{
-		dialect: dialect,
-		indexes: make(map[string]bool),
-		columns: make(map[string]actionType),
+		dialect:   dialect,
+		indexes:   make(map[string]bool),
+		columns:   make(map[string]actionType),
+		canonical: canonicalOf(dialect),
 	}
 
+	d.diff(currentTable, nextTable)
+
+	return d.changes
+}
+
+func (d *diff) diff(currentTable sqlbuilder.Table, nextTable sqlbuilder.Table) {
+	dialect := d.dialect
+
 	// create nextTable
 	if currentTable == nil {
 		d.migrate(createTable, nextTable.TableName(), dialect.CreateTableIsNotExists(nextTable)...)
-		return d
+		return
 	}
 
 	// diff columns
@@ -121,6 +470,7 @@ func Diff(dialect adapter.Dialect, currentTable sqlbuilder.Table, nextTable sqlb
 							panic(fmt.Errorf("col `%s` is not declared", renameTo))
 						}
 						d.columns[renameTo] = renameTableColumn
+						d.change(ChangeColumnAdded, renameTo)
 						d.migrate(renameTableColumn, nextCol.Name(), dialect.RenameColumn(nextCol, targetCol))
 						currentTable.(sqlbuilder.ColumnCollectionManger).AddCol(targetCol)
 						continue
@@ -131,12 +481,22 @@ func Diff(dialect adapter.Dialect, currentTable sqlbuilder.Table, nextTable sqlb
 					continue
 				}
 
-				prevColType, _ := sqlfrag.Collect(context.Background(), dialect.DataType(sqlbuilder.GetColumnDef(currentCol)))
-				currentColType, _ := sqlfrag.Collect(context.Background(), dialect.DataType(sqlbuilder.GetColumnDef(nextCol)))
+				rebuilt := d.addEnumValues(nextCol, currentCol)
+
+				prevColType, _ := sqlfrag.Collect(context.Background(), dialect.DataType(d.canonicalColumnDef(sqlbuilder.GetColumnDef(currentCol))))
+				currentColType, _ := sqlfrag.Collect(context.Background(), dialect.DataType(d.normalizedColumnDef(nextCol)))
+
+				if !rebuilt && !strings.EqualFold(prevColType, currentColType) {
+					d.change(ChangeColumnModified, nextCol.Name())
 
-				if !strings.EqualFold(prevColType, currentColType) {
-					d.columns[nextCol.Name()] = modifyTableColumn
-					d.migrate(modifyTableColumn, nextCol.Name(), dialect.ModifyColumn(nextCol, currentCol))
+					if d.isOnlineChange(nextCol, currentCol) {
+						// keep shadow column during backfill
+						d.columns[adapter.ShadowColumnName(nextCol.Name())] = keepTableColumn
+						d.onlineColumns = append(d.onlineColumns, nextCol)
+					} else if d.addable(nextCol) {
+						d.columns[nextCol.Name()] = modifyTableColumn
+						d.migrate(modifyTableColumn, nextCol.Name(), dialect.ModifyColumn(nextCol, currentCol))
+					}
 				}
 
 				d.columns[nextCol.Name()] = keepTableColumn
@@ -153,8 +513,19 @@ func Diff(dialect adapter.Dialect, currentTable sqlbuilder.Table, nextTable sqlb
 		// only drop tmp col
 		// when need to drop real data col, must declare deprecated for migrate
 		if strings.HasPrefix(col.Name(), "__") && nextTable.F(col.Name()) == nil {
+			if _, ok := d.columns[col.Name()]; ok {
+				continue
+			}
+
 			// drop column
 			d.columns[col.Name()] = dropTableColumn
+
+			if onlineDialect, ok := dialect.(adapter.OnlineColumnDialect); ok && adapter.IsShadowColumnName(col.Name()) {
+				// online change abandoned, sync trigger should be dropped together
+				d.migrate(dropTableColumn, col.Name(), onlineDialect.DropShadowColumn(col))
+				continue
+			}
+
 			d.migrate(dropTableColumn, col.Name(), dialect.DropColumn(col))
 		}
 	}
@@ -163,7 +534,11 @@ func Diff(dialect adapter.Dialect, currentTable sqlbuilder.Table, nextTable sqlb
 	for nextCol := range nextTable.Cols() {
 		if sqlbuilder.GetColumnDef(nextCol).DeprecatedActions == nil {
 			if _, changed := d.columns[nextCol.Name()]; !changed {
-				d.migrate(addTableColumn, nextCol.Name(), dialect.AddColumn(nextCol))
+				d.change(ChangeColumnAdded, nextCol.Name())
+
+				if d.addable(nextCol) {
+					d.migrate(addTableColumn, nextCol.Name(), dialect.AddColumn(nextCol))
+				}
 			}
 		}
 	}
@@ -174,23 +549,28 @@ func Diff(dialect adapter.Dialect, currentTable sqlbuilder.Table, nextTable sqlb
 		if key.IsPrimary() {
 			if prevKey := currentTable.K(name); prevKey == nil {
 				// prev key not exists, could create
+				d.change(ChangeIndexAdded, name)
 				d.migrate(addTableIndex, key.Name(), dialect.AddIndex(key))
 			}
 			// pkey could not change
 			continue
 		}
 
+		reindexed := false
+
 		for col := range key.Cols() {
 			if tpe, ok := d.columns[col.Name()]; ok && tpe == modifyTableColumn {
 				// always re index when col type modified
 				d.migrate(dropTableIndex, key.Name(), dialect.DropIndex(key))
-				d.migrate(addTableIndex, key.Name(), dialect.AddIndex(key))
+				d.addIndex(key)
+				reindexed = true
 			}
 		}
 
 		prevKey := currentTable.K(name)
 		if prevKey == nil {
-			d.migrate(addTableIndex, key.Name(), dialect.AddIndex(key))
+			d.change(ChangeIndexAdded, name)
+			d.addIndex(key)
 		} else {
 			if !key.IsPrimary() {
 				currentIndexDef, _ := sqlfrag.Collect(context.Background(), sqlbuilder.ColumnCollect(
@@ -202,9 +582,13 @@ func Diff(dialect adapter.Dialect, currentTable sqlbuilder.Table, nextTable sqlb
 					return cmp.Compare(column1.Name(), column2.Name())
 				}))))
 
-				if !strings.EqualFold(currentIndexDef, prevIndexDef) {
-					d.migrate(dropTableIndex, key.Name(), dialect.DropIndex(key))
-					d.migrate(addTableIndex, key.Name(), dialect.AddIndex(key))
+				if !strings.EqualFold(currentIndexDef, prevIndexDef) || key.IsUnique() != prevKey.IsUnique() || !d.keyExprEqual(d.normalizedKey(key), prevKey) {
+					d.change(ChangeIndexChanged, name)
+
+					if reindexed || !d.replaceIndex(key, prevKey) {
+						d.migrate(dropTableIndex, key.Name(), dialect.DropIndex(key))
+						d.addIndex(key)
+					}
 				}
 			}
 		}
@@ -212,6 +596,10 @@ func Diff(dialect adapter.Dialect, currentTable sqlbuilder.Table, nextTable sqlb
 	}
 
 	for key := range currentTable.Keys() {
+		if nextTable.K(key.Name()) == nil {
+			d.change(ChangeIndexDropped, key.Name())
+		}
+
 		colDropped := false
 
 		for col := range key.Cols() {
@@ -236,5 +624,30 @@ func Diff(dialect adapter.Dialect, currentTable sqlbuilder.Table, nextTable sqlb
 		}
 	}
 
-	return d
+	currentChecks := map[string]sqlbuilder.Check{}
+	for check := range sqlbuilder.GetTableChecks(currentTable) {
+		currentChecks[check.Name()] = check
+	}
+
+	for check := range sqlbuilder.GetTableChecks(nextTable) {
+		prevCheck, ok := currentChecks[check.Name()]
+		if !ok {
+			d.change(ChangeCheckAdded, check.Name())
+			d.migrateNonNil(addTableCheck, check.Name(), dialect.AddCheck(nextTable, check))
+			continue
+		}
+
+		delete(currentChecks, check.Name())
+
+		if !d.exprEqual(d.normalizedCheck(check).Expr(), prevCheck.Expr()) {
+			d.change(ChangeCheckChanged, check.Name())
+			d.migrateNonNil(dropTableCheck, check.Name(), dialect.DropCheck(nextTable, prevCheck))
+			d.migrateNonNil(addTableCheck, check.Name(), dialect.AddCheck(nextTable, check))
+		}
+	}
+
+	for _, check := range currentChecks {
+		d.change(ChangeCheckDropped, check.Name())
+		d.migrateNonNil(dropTableCheck, check.Name(), dialect.DropCheck(nextTable, check))
+	}
 }
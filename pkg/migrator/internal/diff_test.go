@@ -6,14 +6,19 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
 	testingx "github.com/octohelm/x/testing"
+	typex "github.com/octohelm/x/types"
 
 	"github.com/octohelm/storage/internal/sql/adapter"
 	"github.com/octohelm/storage/internal/sql/adapter/sqlite"
 	"github.com/octohelm/storage/pkg/migrator/internal"
 	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlfrag"
 	"github.com/octohelm/storage/pkg/sqlfrag/testutil"
 	"github.com/octohelm/storage/testdata/model"
 )
@@ -104,4 +109,131 @@ CREATE UNIQUE INDEX t_user_i_age ON t_user (f_age);
 CREATE UNIQUE INDEX t_user_i_name ON t_user (f_real_name);
 `))
 	})
+
+	t.Run("migrate from v1 to v2 with concurrent indexes", func(t *testing.T) {
+		userv1 := sqlbuilder.TableFromModel(&model.User{})
+		userv2 := sqlbuilder.TableFromModel(&model.UserV2{})
+
+		plan, err := internal.DiffPlan(d.Dialect(), userv1, userv2, func(key sqlbuilder.Key) bool {
+			return true
+		}, nil)
+		testingx.Expect(t, err, testingx.Be[error](nil))
+
+		testingx.Expect(t, plan.Migration, testutil.BeFragment(`
+DROP INDEX IF EXISTS t_user_i_age;
+DROP INDEX IF EXISTS t_user_i_created_at;
+ALTER TABLE t_user DROP COLUMN f_username;
+ALTER TABLE t_user RENAME COLUMN f_name TO f_real_name;
+ALTER TABLE t_user RENAME COLUMN f_age TO __f_age;
+ALTER TABLE t_user ADD COLUMN f_age INTEGER NOT NULL DEFAULT '0';
+UPDATE t_user SET f_age = __f_age;
+ALTER TABLE t_user DROP COLUMN __f_age;
+`))
+
+		names := make([]string, 0, len(plan.ConcurrentKeys))
+		for _, key := range plan.ConcurrentKeys {
+			names = append(names, key.Name())
+		}
+		slices.Sort(names)
+
+		testingx.Expect(t, names, testingx.Equal([]string{"i_age"}))
+
+		replaced := make([]string, 0, len(plan.ReplacedKeys))
+		for _, key := range plan.ReplacedKeys {
+			replaced = append(replaced, key.Name())
+		}
+
+		testingx.Expect(t, replaced, testingx.Equal([]string{"i_name"}))
+	})
+	t.Run("compare expressions with normalized table", func(t *testing.T) {
+		userT := func(expr string, check string) sqlbuilder.Table {
+			return sqlbuilder.T("t_user",
+				sqlbuilder.Col("f_name", sqlbuilder.ColTypeOf("", "")),
+				sqlbuilder.Index("i_lower_name", nil, sqlbuilder.IndexFieldNameAndOptions(sqlbuilder.FieldNameAndOption(expr)), sqlbuilder.IndexWhere("f_name <> ''")),
+				sqlbuilder.CheckConstraint("ck_name", check),
+			)
+		}
+
+		current := userT("(lower((f_name)::text))", "(f_name)::text = ANY (ARRAY['a'::text, 'b'::text])")
+		next := userT("(lower(f_name))", "f_name IN ('a', 'b')")
+
+		isNil := func(normalized sqlbuilder.Table) bool {
+			plan, err := internal.DiffPlan(d.Dialect(), current, next, nil, normalized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return sqlfrag.IsNil(plan.Migration)
+		}
+
+		testingx.Expect(t, isNil(nil), testingx.Be(false))
+		testingx.Expect(t, isNil(current), testingx.Be(true))
+	})
+
+	t.Run("compare canonical expressions without normalized table", func(t *testing.T) {
+		userT := func(expr string, check string) sqlbuilder.Table {
+			return sqlbuilder.T("t_user",
+				sqlbuilder.Col("f_name", sqlbuilder.ColTypeOf("", "")),
+				sqlbuilder.Index("i_lower_name", nil, sqlbuilder.IndexFieldNameAndOptions(sqlbuilder.FieldNameAndOption(expr))),
+				sqlbuilder.CheckConstraint("ck_name", check),
+			)
+		}
+
+		changesOf := func(dialect adapter.Dialect) []internal.Change {
+			return internal.Changes(dialect, userT("(lower((f_name)::text))", "((f_name)::text <> ''::text)"), userT("(lower(f_name))", "f_name <> ''"))
+		}
+
+		testingx.Expect(t, len(changesOf(d.Dialect())), testingx.Be(2))
+		testingx.Expect(t, len(changesOf(&castTrimDialect{Dialect: d.Dialect()})), testingx.Be(0))
+	})
+
+	t.Run("reject adding stored generated column to exists table", func(t *testing.T) {
+		orderT := func(defs ...sqlfrag.Fragment) sqlbuilder.Table {
+			return sqlbuilder.T("t_order", append([]sqlfrag.Fragment{
+				sqlbuilder.Col("f_price", sqlbuilder.ColTypeOf(int64(0), "")),
+			}, defs...)...)
+		}
+
+		_, err := internal.DiffPlan(d.Dialect(), orderT(), orderT(
+			sqlbuilder.Col("f_total", sqlbuilder.ColDef(sqlbuilder.ColumnDef{
+				Type:      typex.FromRType(reflect.TypeFor[int64]()),
+				Generated: &sqlbuilder.ColumnGenerated{Expr: "f_price * 2", Stored: true},
+			})),
+		), nil, nil)
+
+		testingx.Expect(t, err != nil, testingx.Be(true))
+	})
+
+	t.Run("list changes even dialect could not migrate", func(t *testing.T) {
+		orderT := func(defs ...sqlfrag.Fragment) sqlbuilder.Table {
+			return sqlbuilder.T("t_order", append([]sqlfrag.Fragment{
+				sqlbuilder.Col("f_price", sqlbuilder.ColTypeOf(int64(0), "")),
+			}, defs...)...)
+		}
+
+		changes := internal.Changes(d.Dialect(), orderT(
+			sqlbuilder.Index("i_price", sqlbuilder.Cols("f_price")),
+			sqlbuilder.CheckConstraint("ck_price", "f_price >= 0"),
+		), orderT(
+			sqlbuilder.Col("f_total", sqlbuilder.ColDef(sqlbuilder.ColumnDef{
+				Type:      typex.FromRType(reflect.TypeFor[int64]()),
+				Generated: &sqlbuilder.ColumnGenerated{Expr: "f_price * 2", Stored: true},
+			})),
+			sqlbuilder.UniqueIndex("i_price", sqlbuilder.Cols("f_price")),
+			sqlbuilder.CheckConstraint("ck_price", "f_price > 0"),
+		))
+
+		testingx.Expect(t, changes, testingx.Equal([]internal.Change{
+			{Type: internal.ChangeColumnAdded, Name: "f_total"},
+			{Type: internal.ChangeIndexChanged, Name: "i_price"},
+			{Type: internal.ChangeCheckChanged, Name: "ck_price"},
+		}))
+	})
+}
+
+type castTrimDialect struct {
+	adapter.Dialect
+}
+
+func (castTrimDialect) CanonicalExpr(expr string) string {
+	return strings.NewReplacer("::text", "", "(f_name)", "f_name").Replace(expr)
 }
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"time"
 
 	"github.com/octohelm/storage/internal/sql/adapter"
 	"github.com/octohelm/storage/pkg/migrator/internal"
@@ -12,36 +13,179 @@ import (
 	"github.com/octohelm/storage/pkg/sqlfrag"
 )
 
+// OptionFunc 表示迁移选项函数。
+type OptionFunc func(o *option)
+
+// ConcurrentIndexes 让已有表上的全部新增索引在事务外并发创建；未启用时仅对 IndexConcurrently 声明的索引生效。
+// 方言不支持并发建索引时忽略该选项。
+func ConcurrentIndexes() OptionFunc {
+	return func(o *option) {
+		o.concurrentIndexes = true
+	}
+}
+
+// IndexBuildProgressInterval 设置并发建索引时记录进度的间隔，小于等于 0 时不记录。
+func IndexBuildProgressInterval(interval time.Duration) OptionFunc {
+	return func(o *option) {
+		o.progressInterval = interval
+	}
+}
+
+// OnlineColumnBackfill 设置在线列变更每批回填的行数，以及单次迁移的回填时长上限；
+// 超过时长上限时保留影子列与同步触发器，下次迁移继续回填，budget 小于等于 0 时不限时长。
+func OnlineColumnBackfill(batchSize int, budget time.Duration) OptionFunc {
+	return func(o *option) {
+		if batchSize > 0 {
+			o.backfillBatchSize = batchSize
+		}
+		o.backfillBudget = budget
+	}
+}
+
+// DeferOnlineColumnCutover 让在线列变更回填完成后暂不切换，留待不带该选项的迁移执行切换。
+func DeferOnlineColumnCutover() OptionFunc {
+	return func(o *option) {
+		o.deferCutover = true
+	}
+}
+
+type option struct {
+	concurrentIndexes bool
+	progressInterval  time.Duration
+	backfillBatchSize int
+	backfillBudget    time.Duration
+	deferCutover      bool
+}
+
 // Migrate 按目标 catalog 执行数据库迁移。
-func Migrate(ctx context.Context, a adapter.Adapter, toCatalog sqlbuilder.Catalog) error {
-	fromTables, err := a.Catalog(ctx)
+// 方言支持时，需并发创建的索引会在迁移事务提交后逐个在事务外创建，并先清理此前失败遗留的无效索引；
+// 其中已变更的索引先以临时名建出，再在短事务内删除旧索引并改名替换；
+// 声明 online 的列类型变更以影子列按主键分批回填，回填完成后在影子列上预建涉及该列的索引，再在短事务内切换。
+func Migrate(ctx context.Context, a adapter.Adapter, toCatalog sqlbuilder.Catalog, optFns ...OptionFunc) error {
+	o := &option{
+		progressInterval:  10 * time.Second,
+		backfillBatchSize: 1000,
+	}
+	for _, optFn := range optFns {
+		optFn(o)
+	}
+
+	cutover, err := migrate(ctx, a, toCatalog, o)
 	if err != nil {
 		return err
 	}
 
+	if cutover {
+		// indexes of replaced columns not prebuilt on shadow columns are dropped together, migrate again to recreate them
+		if _, err := migrate(ctx, a, toCatalog, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func migrate(ctx context.Context, a adapter.Adapter, toCatalog sqlbuilder.Catalog, o *option) (cutover bool, err error) {
+	concurrentDialect, supportConcurrent := a.Dialect().(adapter.ConcurrentIndexDialect)
+
+	concurrently := func(key sqlbuilder.Key) bool {
+		if o.concurrentIndexes {
+			return true
+		}
+		if keyDef := sqlbuilder.GetKeyDef(key); keyDef != nil {
+			return keyDef.Concurrently()
+		}
+		return false
+	}
+
+	if supportConcurrent {
+		if err := dropInvalidIndexes(ctx, a, concurrentDialect, toCatalog); err != nil {
+			return false, err
+		}
+	} else {
+		concurrently = nil
+	}
+
+	fromTables, err := a.Catalog(ctx)
+	if err != nil {
+		return false, err
+	}
+
 	migrations := make([]sqlfrag.Fragment, 0)
+	concurrentKeys := make([]sqlbuilder.Key, 0)
+	replacedKeys := make([]sqlbuilder.Key, 0)
+	onlineColumns := make([]sqlbuilder.Column, 0)
 
 	for _, name := range slices.Sorted(sqlbuilder.TableNames(toCatalog)) {
-		d := internal.Diff(a.Dialect(), fromTables.Table(name), toCatalog.Table(name))
-		if sqlfrag.IsNil(d) {
+		plan, err := diffPlan(ctx, a, fromTables.Table(name), toCatalog.Table(name), concurrently)
+		if err != nil {
+			return false, err
+		}
+
+		concurrentKeys = append(concurrentKeys, plan.ConcurrentKeys...)
+		replacedKeys = append(replacedKeys, plan.ReplacedKeys...)
+		onlineColumns = append(onlineColumns, plan.OnlineColumns...)
+
+		if sqlfrag.IsNil(plan.Migration) {
 			continue
 		}
 
-		migrations = append(migrations, d)
+		migrations = append(migrations, plan.Migration)
 	}
 
-	if len(migrations) == 0 {
-		return nil
+	if len(migrations) > 0 {
+		if err := a.Transaction(ctx, func(ctx context.Context) error {
+			for _, m := range migrations {
+				if _, err := a.Exec(ctx, m); err != nil {
+					return fmt.Errorf("migrate failed: %w", err)
+				}
+			}
+			return nil
+		}); err != nil {
+			return false, err
+		}
 	}
 
-	return a.Transaction(ctx, func(ctx context.Context) error {
-		for _, m := range migrations {
-			if _, err := a.Exec(ctx, m); err != nil {
-				return fmt.Errorf("migrate failed: %w", err)
+	if len(onlineColumns) > 0 {
+		onlineDialect := a.Dialect().(adapter.OnlineColumnDialect)
+
+		for _, col := range onlineColumns {
+			done, err := changeColumnOnline(ctx, a, onlineDialect, col, o)
+			if err != nil {
+				return false, fmt.Errorf("change column %s.%s online failed: %w", sqlbuilder.GetColumnTable(col).TableName(), col.Name(), err)
 			}
+			cutover = cutover || done
 		}
-		return nil
-	})
+	}
+
+	for _, key := range concurrentKeys {
+		if err := createIndexConcurrently(ctx, a, concurrentDialect, key, o.progressInterval); err != nil {
+			return false, fmt.Errorf("create index %s concurrently failed: %w", key, err)
+		}
+	}
+
+	for _, key := range replacedKeys {
+		if err := replaceIndexConcurrently(ctx, a, concurrentDialect, key, o.progressInterval); err != nil {
+			return false, fmt.Errorf("replace index %s concurrently failed: %w", key, err)
+		}
+	}
+
+	return cutover, nil
+}
+
+// diffPlan 比较当前表与目标表，适配器会改写表达式时先经数据库规范化目标表，避免同一表达式在每次迁移时重建。
+func diffPlan(ctx context.Context, a adapter.Adapter, currentTable sqlbuilder.Table, nextTable sqlbuilder.Table, concurrently func(key sqlbuilder.Key) bool) (*internal.Plan, error) {
+	var normalized sqlbuilder.Table
+
+	if n, ok := a.(adapter.ExprNormalizer); ok && currentTable != nil && internal.HasExpr(nextTable) {
+		t, err := n.NormalizeTable(ctx, nextTable)
+		if err != nil {
+			return nil, fmt.Errorf("normalize table %s failed: %w", nextTable.TableName(), err)
+		}
+		normalized = t
+	}
+
+	return internal.DiffPlan(a.Dialect(), currentTable, nextTable, concurrently, normalized)
 }
 
 // CreateTables 仅按目标 catalog 创建缺失表结构。
@@ -3,11 +3,19 @@ package migrator
 import (
 	"context"
 	"database/sql"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/octohelm/x/testing/v2"
+	typex "github.com/octohelm/x/types"
 
 	internaladapter "github.com/octohelm/storage/internal/sql/adapter"
+	"github.com/octohelm/storage/internal/sql/adapter/postgres"
 	"github.com/octohelm/storage/pkg/sqlbuilder"
 	"github.com/octohelm/storage/pkg/sqlfrag"
 	"github.com/octohelm/storage/testdata/model"
@@ -51,26 +59,110 @@ func (migratorDialect) DropIndex(key sqlbuilder.Key) sqlfrag.Fragment {
 	return sqlfrag.Const("DROP INDEX " + key.Name())
 }
 
+func (migratorDialect) AddCheck(t sqlbuilder.Table, check sqlbuilder.Check) sqlfrag.Fragment {
+	return sqlfrag.Const("ADD CHECK " + check.Name())
+}
+
+func (migratorDialect) DropCheck(t sqlbuilder.Table, check sqlbuilder.Check) sqlfrag.Fragment {
+	return sqlfrag.Const("DROP CHECK " + check.Name())
+}
+
 func (migratorDialect) DataType(columnDef sqlbuilder.ColumnDef) sqlfrag.Fragment {
 	return sqlfrag.Const(columnDef.DataType)
 }
 
+type concurrentMigratorDialect struct {
+	migratorDialect
+}
+
+func (concurrentMigratorDialect) AddIndexConcurrently(key sqlbuilder.Key) sqlfrag.Fragment {
+	return sqlfrag.Const("ADD INDEX CONCURRENTLY " + key.Name())
+}
+
+func (concurrentMigratorDialect) DropIndexConcurrently(key sqlbuilder.Key) sqlfrag.Fragment {
+	return sqlfrag.Const("DROP INDEX CONCURRENTLY " + key.Name())
+}
+
+func (concurrentMigratorDialect) RenameIndex(key sqlbuilder.Key, target sqlbuilder.Key) sqlfrag.Fragment {
+	return sqlfrag.Const("RENAME INDEX " + key.Name() + " TO " + target.Name())
+}
+
+func (concurrentMigratorDialect) InvalidIndexes(tableNames ...string) sqlfrag.Fragment {
+	return sqlfrag.Const("SELECT INVALID INDEXES")
+}
+
+func (concurrentMigratorDialect) IndexBuildProgress(t sqlbuilder.Table) sqlfrag.Fragment {
+	return sqlfrag.Const("SELECT INDEX BUILD PROGRESS")
+}
+
+func (concurrentMigratorDialect) ExpandColumn(col sqlbuilder.Column) sqlfrag.Fragment {
+	return sqlfrag.Const("EXPAND " + col.Name())
+}
+
+func (concurrentMigratorDialect) BackfillColumn(col sqlbuilder.Column, batchSize int, after []any) sqlfrag.Fragment {
+	return sqlfrag.Const("BACKFILL " + col.Name())
+}
+
+func (concurrentMigratorDialect) CountColumnBackfill(col sqlbuilder.Column) sqlfrag.Fragment {
+	return sqlfrag.Const("COUNT BACKFILL " + col.Name())
+}
+
+func (concurrentMigratorDialect) PrepareCutover(col sqlbuilder.Column) []sqlfrag.Fragment {
+	return []sqlfrag.Fragment{
+		sqlfrag.Const("ADD NOT NULL CHECK " + col.Name()),
+		sqlfrag.Const("VALIDATE NOT NULL CHECK " + col.Name()),
+	}
+}
+
+func (concurrentMigratorDialect) CutoverColumn(col sqlbuilder.Column, keys ...sqlbuilder.Key) sqlfrag.Fragment {
+	q := "CUTOVER " + col.Name()
+	for _, key := range keys {
+		q += " " + key.Name()
+	}
+	return sqlfrag.Const(q)
+}
+
+func (concurrentMigratorDialect) DropShadowColumn(shadow sqlbuilder.Column) sqlfrag.Fragment {
+	return sqlfrag.Const("DROP SHADOW " + shadow.Name())
+}
+
+func (concurrentMigratorDialect) AddEnumValues(col sqlbuilder.Column, values []string) sqlfrag.Fragment {
+	return sqlfrag.Const("ADD ENUM VALUE " + strings.Join(values, ","))
+}
+
 type migratorAdapter struct {
-	catalog *sqlbuilder.Tables
-	execed  int
+	catalog    *sqlbuilder.Tables
+	execed     int
+	execs      []string
+	queries    []string
+	concurrent bool
+	// onExec 模拟执行后 catalog 的变化
+	onExec func(a *migratorAdapter, q string)
 }
 
 func (a *migratorAdapter) Exec(ctx context.Context, expr sqlfrag.Fragment) (sql.Result, error) {
 	a.execed++
+	q, _ := sqlfrag.Collect(ctx, expr)
+	a.execs = append(a.execs, q)
+	if a.onExec != nil {
+		a.onExec(a, q)
+	}
 	return nil, nil
 }
 
 func (a *migratorAdapter) Query(ctx context.Context, expr sqlfrag.Fragment) (*sql.Rows, error) {
+	q, _ := sqlfrag.Collect(ctx, expr)
+	a.queries = append(a.queries, q)
 	return nil, nil
 }
-func (a *migratorAdapter) Close() error                     { return nil }
-func (a *migratorAdapter) DriverName() string               { return "sqlite" }
-func (a *migratorAdapter) Dialect() internaladapter.Dialect { return migratorDialect{} }
+func (a *migratorAdapter) Close() error       { return nil }
+func (a *migratorAdapter) DriverName() string { return "sqlite" }
+func (a *migratorAdapter) Dialect() internaladapter.Dialect {
+	if a.concurrent {
+		return concurrentMigratorDialect{}
+	}
+	return migratorDialect{}
+}
 func (a *migratorAdapter) Catalog(ctx context.Context) (*sqlbuilder.Tables, error) {
 	return a.catalog, nil
 }
@@ -107,3 +199,302 @@ func TestMigrateAndCreateTables(t *testing.T) {
 		Expect(a.execed > 0, Equal(true)),
 	)
 }
+
+func TestMigrateWithConcurrentIndexes(t *testing.T) {
+	current := &sqlbuilder.Tables{}
+	current.Add(sqlbuilder.T("t_order",
+		sqlbuilder.Col("f_id"),
+		sqlbuilder.Col("f_name"),
+	))
+
+	target := &sqlbuilder.Tables{}
+	target.Add(sqlbuilder.T("t_order",
+		sqlbuilder.Col("f_id"),
+		sqlbuilder.Col("f_name"),
+		sqlbuilder.Index("i_id", sqlbuilder.Cols("f_id")),
+		sqlbuilder.Index("i_name", sqlbuilder.Cols("f_name"), sqlbuilder.IndexConcurrently()),
+	))
+
+	t.Run("按索引声明在事务外并发创建", func(t *testing.T) {
+		a := &migratorAdapter{catalog: current, concurrent: true}
+
+		Then(t, "迁移成功",
+			ExpectDo(func() error {
+				return Migrate(context.Background(), a, target, IndexBuildProgressInterval(0))
+			}),
+		)
+		Then(t, "未声明的索引随事务创建，声明的索引在事务提交后并发创建",
+			Expect(a.execs, Equal([]string{"ADD INDEX i_id", "ADD INDEX CONCURRENTLY i_name"})),
+		)
+	})
+
+	t.Run("全局启用并发创建", func(t *testing.T) {
+		a := &migratorAdapter{catalog: current, concurrent: true}
+
+		Then(t, "迁移成功",
+			ExpectDo(func() error {
+				return Migrate(context.Background(), a, target, ConcurrentIndexes(), IndexBuildProgressInterval(0))
+			}),
+		)
+		Then(t, "全部新增索引均在事务外并发创建",
+			Expect(slices.Sorted(slices.Values(a.execs)), Equal([]string{"ADD INDEX CONCURRENTLY i_id", "ADD INDEX CONCURRENTLY i_name"})),
+		)
+	})
+
+	t.Run("已变更的索引以临时名并发创建后替换", func(t *testing.T) {
+		changed := &sqlbuilder.Tables{}
+		changed.Add(sqlbuilder.T("t_order",
+			sqlbuilder.Col("f_id"),
+			sqlbuilder.Col("f_name"),
+			sqlbuilder.Index("i_name", sqlbuilder.Cols("f_id", "f_name"), sqlbuilder.IndexConcurrently()),
+		))
+
+		a := &migratorAdapter{catalog: target, concurrent: true}
+
+		Then(t, "迁移成功",
+			ExpectDo(func() error {
+				return Migrate(context.Background(), a, changed, IndexBuildProgressInterval(0))
+			}),
+		)
+		Then(t, "迁移事务中只删除多余索引，旧索引在新索引建成后才被替换",
+			Expect(a.execs, Equal([]string{"DROP INDEX i_id", "ADD INDEX CONCURRENTLY i_name_next", "DROP INDEX i_name", "RENAME INDEX i_name_next TO i_name"})),
+		)
+	})
+
+	t.Run("方言不支持时忽略", func(t *testing.T) {
+		a := &migratorAdapter{catalog: current}
+
+		Then(t, "迁移成功",
+			ExpectDo(func() error {
+				return Migrate(context.Background(), a, target, ConcurrentIndexes())
+			}),
+		)
+		Then(t, "全部索引随事务创建",
+			Expect(a.execs, Equal([]string{"ADD INDEX i_idADD INDEX i_name"})),
+		)
+	})
+}
+
+func TestMigrateWithOnlineColumn(t *testing.T) {
+	newCurrent := func(defs ...sqlfrag.Fragment) *sqlbuilder.Tables {
+		current := &sqlbuilder.Tables{}
+		current.Add(sqlbuilder.T("t_order", append([]sqlfrag.Fragment{
+			sqlbuilder.Col("f_id", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "bigint"})),
+			sqlbuilder.Col("f_amount", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "int"})),
+			sqlbuilder.PrimaryKey(sqlbuilder.Cols("f_id")),
+			sqlbuilder.Index("i_amount", sqlbuilder.Cols("f_amount")),
+		}, defs...)...))
+		return current
+	}
+
+	target := &sqlbuilder.Tables{}
+	target.Add(sqlbuilder.T("t_order",
+		sqlbuilder.Col("f_id", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "bigint"})),
+		sqlbuilder.Col("f_amount", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "bigint", Online: true})),
+		sqlbuilder.PrimaryKey(sqlbuilder.Cols("f_id")),
+		sqlbuilder.Index("i_amount", sqlbuilder.Cols("f_amount")),
+	))
+
+	t.Run("回填完成后切换，预建在影子列上的索引接替原索引", func(t *testing.T) {
+		a := &migratorAdapter{
+			catalog:    newCurrent(),
+			concurrent: true,
+			onExec: func(a *migratorAdapter, q string) {
+				if strings.HasPrefix(q, "CUTOVER f_amount") {
+					a.catalog = &sqlbuilder.Tables{}
+					a.catalog.Add(target.Table("t_order"))
+				}
+			},
+		}
+
+		Then(t, "迁移成功",
+			ExpectDo(func() error {
+				return Migrate(context.Background(), a, target, IndexBuildProgressInterval(0))
+			}),
+		)
+		Then(t, "依次扩展、在影子列上预建索引、在锁外校验非空约束并切换，无需补建索引",
+			Expect(a.execs, Equal([]string{
+				"EXPAND f_amount",
+				"ADD INDEX CONCURRENTLY i_amount_next",
+				"ADD NOT NULL CHECK f_amount",
+				"VALIDATE NOT NULL CHECK f_amount",
+				"CUTOVER f_amount i_amount",
+			})),
+		)
+		Then(t, "按主键分批回填，切换前在锁外检查剩余行",
+			Expect(slices.Contains(a.queries, "BACKFILL f_amount"), Equal(true)),
+			Expect(slices.Index(a.queries, "BACKFILL f_amount") < slices.Index(a.queries, "COUNT BACKFILL f_amount"), Equal(true)),
+		)
+	})
+
+	t.Run("目标表无主键时直接修改列", func(t *testing.T) {
+		current := &sqlbuilder.Tables{}
+		current.Add(sqlbuilder.T("t_order",
+			sqlbuilder.Col("f_amount", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "int"})),
+		))
+
+		withoutPrimary := &sqlbuilder.Tables{}
+		withoutPrimary.Add(sqlbuilder.T("t_order",
+			sqlbuilder.Col("f_amount", sqlbuilder.ColDef(sqlbuilder.ColumnDef{DataType: "bigint", Online: true})),
+		))
+
+		a := &migratorAdapter{catalog: current, concurrent: true}
+
+		Then(t, "迁移成功",
+			ExpectDo(func() error {
+				return Migrate(context.Background(), a, withoutPrimary)
+			}),
+		)
+		Then(t, "走普通的列修改",
+			Expect(a.execs, Equal([]string{"MODIFY COLUMN f_amount"})),
+		)
+	})
+
+	t.Run("延迟切换时保留影子列", func(t *testing.T) {
+		a := &migratorAdapter{catalog: newCurrent(sqlbuilder.Col("__f_amount_online")), concurrent: true}
+
+		Then(t, "迁移成功",
+			ExpectDo(func() error {
+				return Migrate(context.Background(), a, target, DeferOnlineColumnCutover())
+			}),
+		)
+		Then(t, "不删除影子列，也不切换",
+			Expect(a.execs, Equal([]string{"EXPAND f_amount"})),
+		)
+		Then(t, "可查询在线变更状态",
+			ExpectMustValue(func() ([]OnlineColumnStatus, error) {
+				return OnlineColumnChanges(context.Background(), a, target)
+			}, Equal([]OnlineColumnStatus{
+				{Table: "t_order", Column: "f_amount", From: "int", To: "bigint", Phase: OnlineColumnReady},
+			})),
+		)
+	})
+
+	t.Run("放弃在线变更时删除影子列", func(t *testing.T) {
+		a := &migratorAdapter{catalog: newCurrent(sqlbuilder.Col("__f_amount_online")), concurrent: true}
+
+		Then(t, "迁移成功",
+			ExpectDo(func() error {
+				return Migrate(context.Background(), a, newCurrent())
+			}),
+		)
+		Then(t, "影子列连同同步触发器一起删除",
+			Expect(a.execs, Equal([]string{"DROP SHADOW __f_amount_online"})),
+		)
+	})
+
+	t.Run("方言不支持时直接修改列", func(t *testing.T) {
+		a := &migratorAdapter{catalog: newCurrent()}
+
+		Then(t, "迁移成功",
+			ExpectDo(func() error {
+				return Migrate(context.Background(), a, target)
+			}),
+		)
+		Then(t, "走普通的列修改",
+			Expect(a.execs, Equal([]string{"MODIFY COLUMN f_amount"})),
+		)
+	})
+}
+
+func TestMigrateWithEnumValues(t *testing.T) {
+	newTables := func(values ...string) *sqlbuilder.Tables {
+		tables := &sqlbuilder.Tables{}
+		tables.Add(sqlbuilder.T("t_user",
+			sqlbuilder.Col("f_gender", sqlbuilder.ColDef(sqlbuilder.ColumnDef{
+				DataType: "e_gender",
+				Enum:     &sqlbuilder.ColumnEnum{Name: "e_gender", Values: values},
+			})),
+		))
+		return tables
+	}
+
+	t.Run("枚举取值增加时追加新取值", func(t *testing.T) {
+		a := &migratorAdapter{catalog: newTables("0", "1"), concurrent: true}
+
+		Then(t, "迁移成功",
+			ExpectDo(func() error {
+				return Migrate(context.Background(), a, newTables("0", "1", "2", "3"))
+			}),
+		)
+		Then(t, "仅追加新增的取值",
+			Expect(a.execs, Equal([]string{"ADD ENUM VALUE 2,3"})),
+		)
+	})
+
+	t.Run("枚举取值未变化时不迁移", func(t *testing.T) {
+		a := &migratorAdapter{catalog: newTables("0", "1"), concurrent: true}
+
+		Then(t, "迁移成功",
+			ExpectDo(func() error {
+				return Migrate(context.Background(), a, newTables("0", "1"))
+			}),
+		)
+		Then(t, "不执行任何语句",
+			Expect(len(a.execs), Equal(0)),
+		)
+	})
+
+	t.Run("方言不支持原生枚举时重建列以更新取值约束", func(t *testing.T) {
+		a := &migratorAdapter{catalog: newTables("0", "1")}
+
+		Then(t, "迁移成功",
+			ExpectDo(func() error {
+				return Migrate(context.Background(), a, newTables("0", "1", "2"))
+			}),
+		)
+		Then(t, "修改该列",
+			Expect(a.execs, Equal([]string{"MODIFY COLUMN f_gender"})),
+		)
+	})
+}
+
+func TestMigrateTwiceWithPostgres(t *testing.T) {
+	ctx := context.Background()
+
+	u, _ := url.Parse("postgres://postgres@localhost/t_" + strconv.FormatInt(time.Now().Unix(), 10) + "?sslmode=disable")
+
+	a := MustValue(t, func() (internaladapter.Adapter, error) {
+		return postgres.Open(ctx, u)
+	})
+	t.Cleanup(func() {
+		_ = a.Close()
+	})
+
+	target := &sqlbuilder.Tables{}
+	target.Add(sqlbuilder.T("t_order",
+		sqlbuilder.Col("f_name", sqlbuilder.ColTypeOf("", "")),
+		sqlbuilder.Col("f_deleted_at", sqlbuilder.ColTypeOf(int64(0), "")),
+		sqlbuilder.Col("f_price", sqlbuilder.ColTypeOf(int64(0), "")),
+		sqlbuilder.Col("f_discount", sqlbuilder.ColDef(sqlbuilder.ColumnDef{
+			Type:      typex.FromRType(reflect.TypeFor[int64]()),
+			Generated: &sqlbuilder.ColumnGenerated{Expr: "CASE WHEN f_price IN (1, 2) THEN f_price ELSE 0 END", Stored: true},
+		})),
+		sqlbuilder.Index("i_lower_name", nil,
+			sqlbuilder.IndexFieldNameAndOptions("(lower(f_name)),desc"),
+			sqlbuilder.IndexWhere("f_deleted_at = 0 AND f_name <> ''"),
+		),
+		sqlbuilder.CheckConstraint("ck_price", "f_price IN (0, 1, 2)"),
+	))
+
+	Then(
+		t, "PostgreSQL 改写表达式索引、部分索引谓词、CHECK 约束与生成列表达式后，再次迁移没有差异",
+		ExpectDo(func() error {
+			return Migrate(ctx, a, target)
+		}),
+		ExpectMustValue(
+			func() (bool, error) {
+				fromTables, err := a.Catalog(ctx)
+				if err != nil {
+					return false, err
+				}
+				plan, err := diffPlan(ctx, a, fromTables.Table("t_order"), target.Table("t_order"), nil)
+				if err != nil {
+					return false, err
+				}
+				return sqlfrag.IsNil(plan.Migration) && len(plan.ConcurrentKeys) == 0, nil
+			},
+			Equal(true),
+		),
+	)
+}
@@ -0,0 +1,309 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/octohelm/x/logr"
+
+	"github.com/octohelm/storage/internal/sql/adapter"
+	"github.com/octohelm/storage/internal/sql/scanner"
+	"github.com/octohelm/storage/pkg/migrator/internal"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+)
+
+// OnlineColumnPhase 表示在线列类型变更所处的阶段。
+type OnlineColumnPhase string
+
+const (
+	// OnlineColumnPending 影子列尚未创建
+	OnlineColumnPending OnlineColumnPhase = "pending"
+	// OnlineColumnBackfilling 影子列已创建，仍有行待回填
+	OnlineColumnBackfilling OnlineColumnPhase = "backfilling"
+	// OnlineColumnReady 回填已完成，等待切换
+	OnlineColumnReady OnlineColumnPhase = "ready"
+)
+
+// OnlineColumnStatus 表示一项尚未完成的在线列类型变更。
+type OnlineColumnStatus struct {
+	Table     string            `json:"table"`
+	Column    string            `json:"column"`
+	From      string            `json:"from"`
+	To        string            `json:"to"`
+	Phase     OnlineColumnPhase `json:"phase"`
+	Remaining int64             `json:"remaining,omitzero"`
+}
+
+// OnlineColumnChanges 只读查询目标 catalog 中尚未完成的在线列类型变更及其回填进度，方言不支持时返回空。
+func OnlineColumnChanges(ctx context.Context, a adapter.Adapter, toCatalog sqlbuilder.Catalog) ([]OnlineColumnStatus, error) {
+	d, ok := a.Dialect().(adapter.OnlineColumnDialect)
+	if !ok {
+		return nil, nil
+	}
+
+	fromTables, err := a.Catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &tableComparer{dialect: a.Dialect()}
+	list := make([]OnlineColumnStatus, 0)
+
+	for _, name := range slices.Sorted(sqlbuilder.TableNames(toCatalog)) {
+		currentTable := fromTables.Table(name)
+		if currentTable == nil {
+			continue
+		}
+
+		plan, err := internal.DiffPlan(a.Dialect(), currentTable, toCatalog.Table(name), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, col := range plan.OnlineColumns {
+			status := OnlineColumnStatus{
+				Table:  name,
+				Column: col.Name(),
+				From:   c.typeOf(sqlbuilder.GetColumnDef(currentTable.F(col.Name()))),
+				To:     c.typeOf(sqlbuilder.GetColumnDef(col)),
+				Phase:  OnlineColumnPending,
+			}
+
+			if currentTable.F(adapter.ShadowColumnName(col.Name())) != nil {
+				remaining, err := countColumnBackfill(ctx, a, d, col)
+				if err != nil {
+					return nil, err
+				}
+
+				status.Remaining = remaining
+				status.Phase = OnlineColumnReady
+				if remaining > 0 {
+					status.Phase = OnlineColumnBackfilling
+				}
+			}
+
+			list = append(list, status)
+		}
+	}
+
+	return list, nil
+}
+
+type columnBackfill struct {
+	Remaining int64 `db:"remaining"`
+}
+
+func countColumnBackfill(ctx context.Context, a adapter.Adapter, d adapter.OnlineColumnDialect, col sqlbuilder.Column) (int64, error) {
+	rows, err := a.Query(ctx, d.CountColumnBackfill(col))
+	if err != nil {
+		return 0, err
+	}
+
+	list := make([]columnBackfill, 0)
+	if err := scanner.Scan(ctx, rows, &list); err != nil {
+		return 0, err
+	}
+
+	if len(list) == 0 {
+		return 0, nil
+	}
+	return list[0].Remaining, nil
+}
+
+// changeColumnOnline 添加影子列并按主键分批回填，回填完成后在影子列上预建涉及该列的索引，再在事务内切换；返回是否已切换。
+func changeColumnOnline(ctx context.Context, a adapter.Adapter, d adapter.OnlineColumnDialect, col sqlbuilder.Column, o *option) (bool, error) {
+	logger := logr.FromContext(ctx).WithValues("table", sqlbuilder.GetColumnTable(col).TableName(), "column", col.Name())
+
+	if _, err := a.Exec(ctx, d.ExpandColumn(col)); err != nil {
+		return false, fmt.Errorf("expand failed: %w", err)
+	}
+
+	started := time.Now()
+	batches := 0
+
+	// rows are synced by trigger since expand, so one pass over the table is enough;
+	// the remaining check runs before locking, and a second pass only covers rows missed by the first
+	for pass := 0; ; pass++ {
+		n, done, err := backfillColumn(ctx, a, d, col, o, started)
+		batches += n
+		if err != nil {
+			return false, fmt.Errorf("backfill failed: %w", err)
+		}
+		if !done {
+			logger.WithValues("batches", batches).Info("backfill paused, will resume on next migration")
+			return false, nil
+		}
+
+		remaining, err := countColumnBackfill(ctx, a, d, col)
+		if err != nil {
+			return false, fmt.Errorf("count backfill failed: %w", err)
+		}
+		if remaining == 0 {
+			break
+		}
+		if pass > 0 {
+			return false, fmt.Errorf("backfill failed: %d rows remaining", remaining)
+		}
+	}
+
+	if o.deferCutover {
+		logger.WithValues("batches", batches).Info("backfill completed, cutover deferred")
+		return false, nil
+	}
+
+	var keys []sqlbuilder.Key
+
+	// indexes on origin column are dropped with it, so prebuild them on shadow column to take over at cutover
+	if cd, ok := a.Dialect().(adapter.ConcurrentIndexDialect); ok {
+		for key, shadowKey := range shadowKeysOf(col) {
+			if err := createIndexConcurrently(ctx, a, cd, shadowKey, o.progressInterval); err != nil {
+				return false, fmt.Errorf("create index %s on shadow column failed: %w", key, err)
+			}
+			keys = append(keys, key)
+		}
+	}
+
+	// prove shadow column not null before locking, so cutover does not scan the table under the exclusive lock
+	for _, prepare := range d.PrepareCutover(col) {
+		if _, err := a.Exec(ctx, prepare); err != nil {
+			return false, fmt.Errorf("prepare cutover failed: %w", err)
+		}
+	}
+
+	if err := a.Transaction(ctx, func(ctx context.Context) error {
+		_, err := a.Exec(ctx, d.CutoverColumn(col, keys...))
+		return err
+	}); err != nil {
+		return false, fmt.Errorf("cutover failed: %w", err)
+	}
+
+	logger.WithValues("batches", batches).Info("column cutover completed")
+
+	return true, nil
+}
+
+// backfillColumn 从表头按主键顺序分批回填影子列，扫描完整表时返回 done，超出回填预算时暂停。
+func backfillColumn(ctx context.Context, a adapter.Adapter, d adapter.OnlineColumnDialect, col sqlbuilder.Column, o *option, started time.Time) (batches int, done bool, err error) {
+	var after []any
+
+	for {
+		if o.backfillBudget > 0 && time.Since(started) > o.backfillBudget {
+			return batches, false, nil
+		}
+
+		last, err := queryBackfillCursor(ctx, a, d.BackfillColumn(col, o.backfillBatchSize, after))
+		if err != nil {
+			return batches, false, err
+		}
+		if last == nil {
+			return batches, true, nil
+		}
+
+		after = last
+		batches++
+	}
+}
+
+// queryBackfillCursor 执行一批回填，返回本批最后一行的主键，无结果时返回 nil。
+func queryBackfillCursor(ctx context.Context, a adapter.Adapter, backfill sqlfrag.Fragment) ([]any, error) {
+	rows, err := a.Query(ctx, backfill)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		return nil, nil
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// shadowKeysOf 返回目标表上涉及 col 的索引，及其把 col 换为影子列、以 NextKeyName 命名的副本。
+func shadowKeysOf(col sqlbuilder.Column) iter.Seq2[sqlbuilder.Key, sqlbuilder.Key] {
+	return func(yield func(sqlbuilder.Key, sqlbuilder.Key) bool) {
+		t := sqlbuilder.GetColumnTable(col)
+		shadowName := adapter.ShadowColumnName(col.Name())
+		// column name in expression or predicate
+		ref := regexp.MustCompile(`\b` + regexp.QuoteMeta(col.Name()) + `\b`)
+
+		defs := make([]sqlfrag.Fragment, 0)
+		for c := range t.Cols() {
+			defs = append(defs, c)
+		}
+		defs = append(defs, sqlbuilder.Col(shadowName, sqlbuilder.ColDef(sqlbuilder.GetColumnDef(col))))
+
+		shadowTable := sqlbuilder.T(t.TableName(), defs...)
+
+		for key := range t.Keys() {
+			keyDef := sqlbuilder.GetKeyDef(key)
+			if keyDef == nil {
+				continue
+			}
+
+			covered := ref.MatchString(keyDef.Where())
+
+			fieldNameAndOptions := make([]sqlbuilder.FieldNameAndOption, 0)
+			for _, fo := range keyDef.FieldNameAndOptions() {
+				if fo.IsExpr() {
+					if ref.MatchString(fo.Name()) {
+						covered = true
+						fo = sqlbuilder.FieldNameAndOption(ref.ReplaceAllLiteralString(string(fo), shadowName))
+					}
+				} else if c := t.F(fo.Name()); c != nil {
+					name := c.Name()
+					if name == col.Name() {
+						covered = true
+						name = shadowName
+					}
+					if _, options, ok := strings.Cut(string(fo), ","); ok {
+						name += "," + options
+					}
+					fo = sqlbuilder.FieldNameAndOption(name)
+				}
+				fieldNameAndOptions = append(fieldNameAndOptions, fo)
+			}
+
+			if !covered {
+				continue
+			}
+
+			shadowKey := sqlbuilder.Index(adapter.NextKeyName(key.Name()), nil,
+				sqlbuilder.IndexUnique(key.IsUnique()),
+				sqlbuilder.IndexUsing(keyDef.Method()),
+				sqlbuilder.IndexWhere(ref.ReplaceAllLiteralString(keyDef.Where(), shadowName)),
+				sqlbuilder.IndexFieldNameAndOptions(fieldNameAndOptions...),
+			).Of(shadowTable)
+
+			if !yield(key, shadowKey) {
+				return
+			}
+		}
+	}
+}
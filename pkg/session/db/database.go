@@ -35,6 +35,8 @@ type Database struct {
 
 	// EnableMigrate 表示启动前自动执行迁移。
 	EnableMigrate bool `flag:",omitzero"`
+	// MigrateIndexConcurrently 表示迁移时已有表上的新增索引在事务外并发创建。
+	MigrateIndexConcurrently bool `flag:",omitzero"`
 
 	name   string
 	tables *sqlbuilder.Tables
@@ -148,5 +150,8 @@ func (d *Database) Run(ctx context.Context) error {
 	if d.EnableMigrate == false {
 		return nil
 	}
+	if d.MigrateIndexConcurrently {
+		return migrator.Migrate(ctx, d.db, d.tables, migrator.ConcurrentIndexes())
+	}
 	return migrator.Migrate(ctx, d.db, d.tables)
 }
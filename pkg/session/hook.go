@@ -0,0 +1,94 @@
+package session
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/octohelm/storage/internal/sql/adapter"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+)
+
+// Operation 表示写入操作类型。
+type Operation uint8
+
+const (
+	OperationInsert Operation = iota + 1
+	OperationUpdate
+	OperationDelete
+)
+
+func (o Operation) String() string {
+	switch o {
+	case OperationInsert:
+		return "INSERT"
+	case OperationUpdate:
+		return "UPDATE"
+	case OperationDelete:
+		return "DELETE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Change 描述一次写入，传给同一事务内的写入回调。
+type Change struct {
+	Operation Operation
+	Table     sqlbuilder.Table
+	// Values 为写入的模型值；After 阶段在写入带 RETURNING 时为返回的记录
+	Values []any
+	// Assignments 为更新时的赋值
+	Assignments []sqlbuilder.Assignment
+}
+
+// Hook 定义会话级写入回调，返回错误时整个事务回滚。
+type Hook interface {
+	BeforeChange(ctx context.Context, change *Change) error
+	AfterChange(ctx context.Context, change *Change) error
+}
+
+// HookFuncs 以函数形式实现 Hook，未设置的阶段直接跳过。
+type HookFuncs struct {
+	Before func(ctx context.Context, change *Change) error
+	After  func(ctx context.Context, change *Change) error
+}
+
+func (h HookFuncs) BeforeChange(ctx context.Context, change *Change) error {
+	if h.Before == nil {
+		return nil
+	}
+	return h.Before(ctx, change)
+}
+
+func (h HookFuncs) AfterChange(ctx context.Context, change *Change) error {
+	if h.After == nil {
+		return nil
+	}
+	return h.After(ctx, change)
+}
+
+var (
+	hooksMu sync.RWMutex
+	hooks   = map[string][]Hook{}
+)
+
+// RegisterHook 按会话名注册写入回调。
+func RegisterHook(name string, hs ...Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+
+	hooks[name] = append(slices.Clone(hooks[name]), hs...)
+}
+
+// HooksOf 返回会话名对应的写入回调。
+func HooksOf(name string) []Hook {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+
+	return hooks[name]
+}
+
+// AfterCommit 注册在最外层事务提交成功后执行的回调；不在事务中时立即执行。
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	adapter.AfterCommit(ctx, fn)
+}
@@ -114,3 +114,26 @@ func TestHelpers(t *testing.T) {
 		Expect(InTx(context.Background()), Equal(false)),
 	)
 }
+
+func TestRegisterHook(t *testing.T) {
+	called := make([]string, 0)
+
+	RegisterHook("hooked", HookFuncs{
+		After: func(ctx context.Context, change *Change) error {
+			called = append(called, change.Operation.String())
+			return nil
+		},
+	})
+
+	hooks := HooksOf("hooked")
+	change := &Change{Operation: OperationUpdate}
+
+	Then(
+		t, "按会话名注册写入回调，未设置的阶段直接跳过",
+		Expect(len(hooks), Equal(1)),
+		Expect(len(HooksOf("other")), Equal(0)),
+		Expect(hooks[0].BeforeChange(context.Background(), change), Equal(error(nil))),
+		Expect(hooks[0].AfterChange(context.Background(), change), Equal(error(nil))),
+		Expect(called, Equal([]string{"UPDATE"})),
+	)
+}
@@ -23,9 +23,18 @@ const (
 	Desc Order = "desc"
 )
 
+// Nulls 表示空值在排序结果中的位置，写作 field!order!nullsfirst 或 field!order!nullslast。
+type Nulls string
+
+const (
+	NullsFirst Nulls = "nullsfirst"
+	NullsLast  Nulls = "nullslast"
+)
+
 type By[E enumeration.CanEnumValues] struct {
 	Field E
 	Order Order
+	Nulls Nulls
 
 	raw []byte
 }
@@ -76,6 +85,10 @@ func (v By[E]) MarshalText() ([]byte, error) {
 		}
 	}
 
+	if v.Nulls != "" {
+		return fmt.Appendf(nil, "%s!%s!%s", fieldStr, string(cmp.Or(v.Order, Asc)), string(v.Nulls)), nil
+	}
+
 	return fmt.Appendf(nil, "%s!%s", fieldStr, string(cmp.Or(v.Order, Asc))), nil
 }
 
@@ -86,8 +99,8 @@ func (v *By[E]) UnmarshalText(data []byte) error {
 		return nil
 	}
 
-	parts := bytes.SplitN(data, []byte("!"), 2)
-	if len(parts) != 2 {
+	parts := bytes.SplitN(data, []byte("!"), 3)
+	if len(parts) < 2 {
 		return fmt.Errorf("invalid sort format: %s", data)
 	}
 	field, order := parts[0], parts[1]
@@ -107,6 +120,15 @@ func (v *By[E]) UnmarshalText(data []byte) error {
 		return fmt.Errorf("unknown sort order: %s", order)
 	}
 
+	if len(parts) == 3 {
+		switch nulls := Nulls(strings.ToLower(string(parts[2]))); nulls {
+		case NullsFirst, NullsLast:
+			vv.Nulls = nulls
+		default:
+			return fmt.Errorf("unknown sort nulls: %s", parts[2])
+		}
+	}
+
 	vv.raw = data[:]
 
 	*v = *vv
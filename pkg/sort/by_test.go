@@ -2,6 +2,7 @@ package sort_test
 
 import (
 	"encoding"
+	"regexp"
 	"testing"
 
 	. "github.com/octohelm/x/testing/v2"
@@ -122,3 +123,28 @@ func TestByEnumValues(t *testing.T) {
 		}, Equal("user~name!desc")),
 	)
 }
+
+func TestByNulls(t *testing.T) {
+	Then(
+		t, "可选的空值位置经文本往返",
+		ExpectMustValue(func() (sort.Nulls, error) {
+			var b sort.By[UserSortBy]
+			if err := b.UnmarshalText([]byte("user~name!desc!nullslast")); err != nil {
+				return "", err
+			}
+			return b.Nulls, nil
+		}, Equal(sort.NullsLast)),
+		ExpectMustValue(func() (string, error) {
+			raw, err := sort.By[UserSortBy]{Field: UserSortByID, Order: sort.Asc, Nulls: sort.NullsFirst}.MarshalText()
+			return string(raw), err
+		}, Equal("user~id!asc!nullsfirst")),
+	)
+
+	Then(
+		t, "未知的空值位置返回错误",
+		ExpectDo(func() error {
+			var b sort.By[UserSortBy]
+			return b.UnmarshalText([]byte("user~name!desc!nulls"))
+		}, ErrorMatch(regexp.MustCompile("unknown sort nulls"))),
+	)
+}
@@ -29,13 +29,20 @@ func Intersect() CombinationAddition {
 	}
 }
 
-// Expect 创建 EXCEPT 组合子句。
-func Expect() CombinationAddition {
+// Except 创建 EXCEPT 组合子句。
+func Except() CombinationAddition {
 	return &combination{
 		operator: "EXCEPT",
 	}
 }
 
+// Expect 创建 EXCEPT 组合子句。
+//
+// Deprecated: 请改用 Except。
+func Expect() CombinationAddition {
+	return Except()
+}
+
 type combination struct {
 	operator   string // UNION | INTERSECT | EXCEPT
 	method     string // ALL | DISTINCT
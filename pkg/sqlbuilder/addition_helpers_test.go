@@ -38,3 +38,18 @@ func TestAdditionsAndFieldValueHelpers(t *testing.T) {
 		Expect(sqlbuilder.NullsLast().IsNil(), Equal(false)),
 	)
 }
+
+func TestOnConflictWhere(t *testing.T) {
+	onConflict := sqlbuilder.OnConflict(sqlbuilder.Cols("f_name")).
+		Where(sqlfrag.Pair("f_deleted_at = 0")).
+		DoUpdateSet(sqlbuilder.ColumnsAndValues(model.UserT.Nickname, sqlfrag.Pair("EXCLUDED.f_nickname"))).
+		DoUpdateWhere(model.UserT.Nickname.V(sqlbuilder.Neq("")))
+
+	q, args := sqlfrag.Collect(context.Background(), onConflict)
+
+	Then(
+		t, "OnConflict 支持部分索引谓词与更新条件",
+		Expect(q, Equal("ON CONFLICT (f_name) WHERE f_deleted_at = 0 DO UPDATE SET f_nickname = EXCLUDED.f_nickname WHERE t_user.f_nickname <> ?")),
+		Expect(args, Equal([]any{""})),
+	)
+}
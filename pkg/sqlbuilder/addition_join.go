@@ -49,6 +49,16 @@ func CrossJoin(table sqlfrag.Fragment) JoinAddition {
 	return Join(table, "CROSS")
 }
 
+// GetJoinTable 返回 JOIN 的目标表；目标不是表时返回 nil。
+func GetJoinTable(a JoinAddition) Table {
+	if j, ok := a.(*join); ok && j != nil {
+		if t, ok := j.target.(Table); ok {
+			return t
+		}
+	}
+	return nil
+}
+
 type join struct {
 	prefix         string
 	target         sqlfrag.Fragment
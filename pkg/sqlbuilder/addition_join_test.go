@@ -63,4 +63,9 @@ JOIN t_org USING (f_org_id)
 `,
 			))
 	})
+
+	t.Run("GetJoinTable", func(t *testing.T) {
+		testingx.Expect(t, GetJoinTable(LeftJoin(tOrg)).TableName(), testingx.Be("t_org"))
+		testingx.Expect(t, GetJoinTable(Join(Alias(tOrg, "o"))), testingx.Be[Table](nil))
+	})
 }
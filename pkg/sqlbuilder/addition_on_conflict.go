@@ -12,8 +12,12 @@ import (
 type OnConflictAddition interface {
 	Addition
 
+	// Where 为冲突目标追加索引谓词，用于匹配部分唯一索引。
+	Where(where sqlfrag.Fragment) OnConflictAddition
 	DoNothing() OnConflictAddition
 	DoUpdateSet(assignments ...Assignment) OnConflictAddition
+	// DoUpdateWhere 为 DO UPDATE 追加更新条件，不满足时跳过更新。
+	DoUpdateWhere(where sqlfrag.Fragment) OnConflictAddition
 }
 
 // OnConflict 创建 ON CONFLICT 附加子句。
@@ -25,14 +29,26 @@ func OnConflict(columns ColumnSeq) OnConflictAddition {
 
 type onConflict struct {
 	columns     ColumnSeq
+	where       sqlfrag.Fragment
 	doNothing   bool
 	assignments []Assignment
+	updateWhere sqlfrag.Fragment
 }
 
 func (onConflict) AdditionType() AdditionType {
 	return AdditionOnConflict
 }
 
+func (o onConflict) Where(where sqlfrag.Fragment) OnConflictAddition {
+	o.where = where
+	return &o
+}
+
+func (o onConflict) DoUpdateWhere(where sqlfrag.Fragment) OnConflictAddition {
+	o.updateWhere = where
+	return &o
+}
+
 func (o onConflict) DoNothing() OnConflictAddition {
 	o.doNothing = true
 	return &o
@@ -63,6 +79,14 @@ func (o *onConflict) Frag(ctx context.Context) iter.Seq2[string, []any] {
 			}
 		}
 
+		if !sqlfrag.IsNil(o.where) {
+			for q, args := range sqlfrag.Pair(" WHERE ?", o.where).Frag(ctx) {
+				if !yield(q, args) {
+					return
+				}
+			}
+		}
+
 		if !yield(" DO ", nil) {
 			return
 		}
@@ -83,5 +107,16 @@ func (o *onConflict) Frag(ctx context.Context) iter.Seq2[string, []any] {
 				return
 			}
 		}
+
+		if !sqlfrag.IsNil(o.updateWhere) {
+			// qualify columns to avoid ambiguous with EXCLUDED
+			for q, args := range sqlfrag.Pair(" WHERE ?", o.updateWhere).Frag(ContextWithToggles(ctx, Toggles{
+				ToggleMultiTable: true,
+			})) {
+				if !yield(q, args) {
+					return
+				}
+			}
+		}
 	}
 }
@@ -9,3 +9,9 @@ type ColumnDef = columndef.ColumnDef
 
 // DeprecatedActions 复用内部废弃动作定义。
 type DeprecatedActions = columndef.DeprecatedActions
+
+// ColumnGenerated 复用内部生成列定义。
+type ColumnGenerated = columndef.Generated
+
+// ColumnEnum 复用内部枚举列定义。
+type ColumnEnum = columndef.Enum
@@ -37,6 +37,9 @@ func TestConditionsAndCombination(t *testing.T) {
 	exceptQ, _ := sqlfrag.Collect(context.Background(), sqlbuilder.Expect().All(
 		sqlbuilder.Select(sqlfrag.Const("3")),
 	))
+	exceptDistinctQ, _ := sqlfrag.Collect(context.Background(), sqlbuilder.Except().Distinct(
+		sqlbuilder.Select(sqlfrag.Const("4")),
+	))
 
 	Then(
 		t, "条件组合和集合操作都会生成 SQL",
@@ -47,6 +50,7 @@ func TestConditionsAndCombination(t *testing.T) {
 		Expect(strings.Contains(unionQ, "UNION ALL"), Equal(true)),
 		Expect(strings.Contains(intersectQ, "INTERSECT DISTINCT"), Equal(true)),
 		Expect(strings.Contains(exceptQ, "EXCEPT ALL"), Equal(true)),
+		Expect(strings.Contains(exceptDistinctQ, "EXCEPT DISTINCT"), Equal(true)),
 		Expect(sqlbuilder.EmptyCond().IsNil(), Equal(true)),
 	)
 }
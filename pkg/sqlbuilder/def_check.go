@@ -0,0 +1,116 @@
+package sqlbuilder
+
+import (
+	"context"
+	"iter"
+	"strings"
+
+	"github.com/octohelm/storage/pkg/sqlfrag"
+)
+
+// Check 表示表上的 CHECK 约束。
+type Check interface {
+	sqlfrag.Fragment
+
+	Name() string
+	Expr() string
+}
+
+// CheckSeq 表示 CHECK 约束序列。
+type CheckSeq interface {
+	Checks() iter.Seq[Check]
+}
+
+// CheckCollectionManager 定义 CHECK 约束的追加能力。
+type CheckCollectionManager interface {
+	AddCheck(checks ...Check)
+}
+
+// CheckConstraint 创建 CHECK 约束定义，expr 为原样输出的 SQL 表达式。
+func CheckConstraint(name string, expr string) Check {
+	return &check{
+		name: strings.ToLower(name),
+		expr: strings.TrimSpace(expr),
+	}
+}
+
+// GetTableChecks 返回表上声明的 CHECK 约束；表不支持时返回空序列。
+func GetTableChecks(t Table) iter.Seq[Check] {
+	for t != nil {
+		if cs, ok := t.(CheckSeq); ok {
+			return cs.Checks()
+		}
+
+		u, ok := t.(interface{ Unwrap() Table })
+		if !ok {
+			break
+		}
+		t = u.Unwrap()
+	}
+
+	return func(yield func(Check) bool) {}
+}
+
+// ParseCheckDefine 解析 CHECK 约束定义标签文本。
+// 例如：
+// @def check ck_age f_age >= 0
+func ParseCheckDefine(def string) (name string, expr string, ok bool) {
+	kind, rest, _ := strings.Cut(strings.TrimSpace(def), " ")
+	if kind != "check" {
+		return "", "", false
+	}
+
+	name, expr, _ = strings.Cut(strings.TrimSpace(rest), " ")
+	if name == "" || strings.TrimSpace(expr) == "" {
+		return "", "", false
+	}
+
+	return strings.ToLower(name), strings.TrimSpace(expr), true
+}
+
+type check struct {
+	name string
+	expr string
+}
+
+func (c *check) IsNil() bool {
+	return c == nil
+}
+
+func (c *check) Frag(ctx context.Context) iter.Seq2[string, []any] {
+	return sqlfrag.Const(c.name).Frag(ctx)
+}
+
+func (c *check) Name() string {
+	return c.name
+}
+
+func (c *check) Expr() string {
+	return c.expr
+}
+
+type checks struct {
+	l []Check
+}
+
+func (cs *checks) AddCheck(nextChecks ...Check) {
+	for _, c := range nextChecks {
+		if c == nil {
+			continue
+		}
+		cs.l = append(cs.l, c)
+	}
+}
+
+func (cs *checks) Checks() iter.Seq[Check] {
+	return func(yield func(Check) bool) {
+		if cs == nil {
+			return
+		}
+		for _, c := range cs.l {
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
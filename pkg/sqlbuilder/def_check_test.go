@@ -0,0 +1,68 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	testingx "github.com/octohelm/x/testing"
+
+	. "github.com/octohelm/storage/pkg/sqlbuilder"
+)
+
+type orderWithChecks struct {
+	Price int64 `db:"f_price"`
+	Total int64 `db:"f_total,stored" generated:"f_price * 2"`
+}
+
+func (orderWithChecks) TableName() string {
+	return "t_order"
+}
+
+func (orderWithChecks) Checks() Checks {
+	return Checks{
+		"ck_total": "f_total >= 0",
+		"CK_Price": "f_price >= 0",
+	}
+}
+
+func checksOf(t Table) map[string]string {
+	m := map[string]string{}
+	for check := range GetTableChecks(t) {
+		m[check.Name()] = check.Expr()
+	}
+	return m
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("parse check define", func(t *testing.T) {
+		name, expr, ok := ParseCheckDefine("check CK_Age f_age >= 0 AND f_age < 200")
+
+		testingx.Expect(t, ok, testingx.Be(true))
+		testingx.Expect(t, name, testingx.Be("ck_age"))
+		testingx.Expect(t, expr, testingx.Be("f_age >= 0 AND f_age < 200"))
+
+		_, _, ok = ParseCheckDefine("index i_age Age")
+		testingx.Expect(t, ok, testingx.Be(false))
+	})
+
+	t.Run("declare by T", func(t *testing.T) {
+		tOrder := T("t_order", CheckConstraint("ck_price", " f_price >= 0 "))
+
+		testingx.Expect(t, checksOf(tOrder), testingx.Equal(map[string]string{
+			"ck_price": "f_price >= 0",
+		}))
+		testingx.Expect(t, checksOf(tOrder.(TableWithTableName).WithTableName("t_order_2")), testingx.Equal(checksOf(tOrder)))
+	})
+
+	t.Run("declare by model", func(t *testing.T) {
+		tOrder := TableFromModel(&orderWithChecks{})
+
+		testingx.Expect(t, checksOf(tOrder), testingx.Equal(map[string]string{
+			"ck_price": "f_price >= 0",
+			"ck_total": "f_total >= 0",
+		}))
+		testingx.Expect(t, GetColumnDef(tOrder.F("f_total")).Generated, testingx.Equal(&ColumnGenerated{
+			Expr:   "f_price * 2",
+			Stored: true,
+		}))
+	})
+}
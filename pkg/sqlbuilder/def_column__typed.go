@@ -169,34 +169,51 @@ func Neq[T any](expect T) ColumnValuer[T] {
 	}
 }
 
-// Like 生成包含匹配的 LIKE 条件。
+// Like 生成包含匹配的 LIKE 条件，值中的通配符会被转义。
 func Like[T ~string](s T) ColumnValuer[T] {
 	return func(c Column) sqlfrag.Fragment {
-		return sqlfrag.Pair("? LIKE ?", c, "%"+s+"%")
+		return LikePattern(c, "LIKE", "%", string(s), "%")
 	}
 }
 
-// NotLike 生成排除匹配的 NOT LIKE 条件。
+// NotLike 生成排除匹配的 NOT LIKE 条件，值中的通配符会被转义。
 func NotLike[T ~string](s T) ColumnValuer[T] {
 	return func(c Column) sqlfrag.Fragment {
-		return sqlfrag.Pair("? NOT LIKE ?", c, "%"+s+"%")
+		return LikePattern(c, "NOT LIKE", "%", string(s), "%")
 	}
 }
 
-// LeftLike 生成左模糊匹配条件。
+// LeftLike 生成左模糊匹配条件，值中的通配符会被转义。
 func LeftLike[T ~string](s T) ColumnValuer[T] {
 	return func(c Column) sqlfrag.Fragment {
-		return sqlfrag.Pair("? LIKE ?", c, "%"+s)
+		return LikePattern(c, "LIKE", "%", string(s), "")
 	}
 }
 
-// RightLike 生成右模糊匹配条件。
+// RightLike 生成右模糊匹配条件，值中的通配符会被转义。
 func RightLike[T ~string](s T) ColumnValuer[T] {
 	return func(c Column) sqlfrag.Fragment {
-		return sqlfrag.Pair("? LIKE ?", c, s+"%")
+		return LikePattern(c, "LIKE", "", string(s), "%")
 	}
 }
 
+// LikePattern 生成 `<expr> <op> <prefix><s><suffix>` 的模式匹配条件，op 如 LIKE、NOT LIKE、ILIKE。
+//
+// s 中的 `%`、`_` 与 `\` 被转义，仅在发生转义时追加 `ESCAPE '\'`。
+func LikePattern(expr sqlfrag.Fragment, op string, prefix string, s string, suffix string) sqlfrag.Fragment {
+	if escaped := EscapeLike(s); escaped != s {
+		return sqlfrag.Pair("? "+op+" ? ESCAPE '\\'", expr, prefix+escaped+suffix)
+	}
+	return sqlfrag.Pair("? "+op+" ?", expr, prefix+s+suffix)
+}
+
+// EscapeLike 转义 LIKE 模式中的 `\`、`%` 与 `_`。
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // Between 生成闭区间范围条件。
 func Between[T comparable](leftValue T, rightValue T) ColumnValuer[T] {
 	return func(c Column) sqlfrag.Fragment {
@@ -36,6 +36,20 @@ func IndexUsing(method string) IndexOptionFunc {
 	}
 }
 
+// IndexWhere 设置部分索引的谓词，predicate 为原样输出的 SQL 表达式。
+func IndexWhere(predicate string) IndexOptionFunc {
+	return func(k *key) {
+		k.where = strings.TrimSpace(predicate)
+	}
+}
+
+// IndexConcurrently 标记索引在已有表上由迁移在事务外并发创建，仅部分方言支持。
+func IndexConcurrently() IndexOptionFunc {
+	return func(k *key) {
+		k.concurrently = true
+	}
+}
+
 // IndexFieldNameAndOptions 直接设置索引列及其附加选项。
 func IndexFieldNameAndOptions(colNameAndOptions ...FieldNameAndOption) IndexOptionFunc {
 	return func(k *key) {
@@ -88,6 +102,10 @@ type Key interface {
 type KeyDef interface {
 	Method() string
 	FieldNameAndOptions() []FieldNameAndOption
+	// Where 返回部分索引的谓词，非部分索引时为空
+	Where() string
+	// Concurrently 返回迁移时是否在事务外并发创建
+	Concurrently() bool
 }
 
 // GetKeyDef 返回索引的定义元信息。
@@ -133,6 +151,22 @@ func AsKeyColumnsTableDef(key Key, optionFns ...func(o *opt)) sqlfrag.Fragment {
 					}
 				}
 
+				if fo.IsExpr() {
+					if !yield(fo.Name(), nil) {
+						return
+					}
+
+					if !o.KeyColumnOnly {
+						if options := fo.Options(); len(options) > 0 {
+							if !yield(" "+strings.Join(options, " "), nil) {
+								return
+							}
+						}
+					}
+
+					continue
+				}
+
 				if c := cc.F(fo.Name()); c != nil {
 					if !yield(c.Name(), nil) {
 						return
@@ -143,7 +177,6 @@ func AsKeyColumnsTableDef(key Key, optionFns ...func(o *opt)) sqlfrag.Fragment {
 							if !yield(" "+strings.Join(options, " "), nil) {
 								return
 							}
-							return
 						}
 					}
 
@@ -159,7 +192,6 @@ func AsKeyColumnsTableDef(key Key, optionFns ...func(o *opt)) sqlfrag.Fragment {
 						if !yield(" "+strings.Join(options, " "), nil) {
 							return
 						}
-						return
 					}
 
 					continue
@@ -176,6 +208,8 @@ type key struct {
 	name                string
 	isUnique            bool
 	method              string
+	where               string
+	concurrently        bool
 	fieldNameAndOptions []FieldNameAndOption
 }
 
@@ -199,12 +233,22 @@ func (k *key) FieldNameAndOptions() []FieldNameAndOption {
 	return k.fieldNameAndOptions
 }
 
+func (k *key) Where() string {
+	return k.where
+}
+
+func (k *key) Concurrently() bool {
+	return k.concurrently
+}
+
 func (k key) Of(table Table) Key {
 	return &key{
 		table:               table,
 		name:                k.name,
 		isUnique:            k.isUnique,
 		method:              k.method,
+		where:               k.where,
+		concurrently:        k.concurrently,
 		fieldNameAndOptions: k.fieldNameAndOptions,
 	}
 }
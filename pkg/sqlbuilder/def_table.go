@@ -60,6 +60,8 @@ func T(tableName string, tableDefinitions ...sqlfrag.Fragment) Table {
 		switch d := tableDef.(type) {
 		case Key:
 			t.AddKey(d.Of(t))
+		case Check:
+			t.AddCheck(d)
 		}
 	}
 
@@ -72,6 +74,7 @@ type table struct {
 	description []string
 	ColumnCollection
 	KeyCollection
+	checks checks
 }
 
 func (t *table) AddCol(cols ...Column) {
@@ -86,11 +89,20 @@ func (t *table) AddKey(keys ...Key) {
 	}
 }
 
+func (t *table) AddCheck(checks ...Check) {
+	t.checks.AddCheck(checks...)
+}
+
+func (t *table) Checks() iter.Seq[Check] {
+	return t.checks.Checks()
+}
+
 func (t table) WithTableName(name string) Table {
 	newTable := &table{
 		database:    t.database,
 		name:        name,
 		description: t.description,
+		checks:      t.checks,
 	}
 
 	newTable.ColumnCollection = t.ColumnCollection.Of(newTable)
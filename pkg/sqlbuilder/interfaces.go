@@ -38,6 +38,27 @@ type WithIndexes interface {
 	Indexes() Indexes
 }
 
+// IndexPredicates 表示索引名到部分索引谓词的映射。
+type IndexPredicates map[string]string
+
+// WithIndexPredicates 表示模型为索引声明部分索引谓词。
+type WithIndexPredicates interface {
+	IndexPredicates() IndexPredicates
+}
+
+// WithConcurrentIndexes 表示模型声明需在迁移时于事务外并发创建的索引名。
+type WithConcurrentIndexes interface {
+	ConcurrentIndexes() []string
+}
+
+// Checks 表示约束名到 CHECK 表达式的映射。
+type Checks map[string]string
+
+// WithChecks 表示模型声明 CHECK 约束。
+type WithChecks interface {
+	Checks() Checks
+}
+
 // WithComments 表示模型声明字段注释。
 type WithComments interface {
 	Comments() map[string]string
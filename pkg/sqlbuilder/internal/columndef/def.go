@@ -1,11 +1,15 @@
 package columndef
 
 import (
+	"database/sql/driver"
 	"fmt"
+	"path"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/octohelm/gengo/pkg/camelcase"
 	typex "github.com/octohelm/x/types"
 )
 
@@ -16,6 +20,11 @@ func FromTypeAndTag(typ typex.Type, nameAndFlags string, st reflect.StructTag) *
 	}
 	ct.Type = typex.Deref(typ)
 
+	// expr may contain comma, so declare in standalone tag
+	if expr, ok := st.Lookup("generated"); ok && expr != "" {
+		ct.Generated = &Generated{Expr: expr}
+	}
+
 	if strings.Contains(nameAndFlags, ",") {
 		for _, flag := range strings.Split(nameAndFlags, ",")[1:] {
 			nameAndValue := strings.Split(flag, "=")
@@ -24,6 +33,13 @@ func FromTypeAndTag(typ typex.Type, nameAndFlags string, st reflect.StructTag) *
 				ct.Null = true
 			case "autoincrement":
 				ct.AutoIncrement = true
+			case "online":
+				ct.Online = true
+			case "enum":
+				ct.Enum = &Enum{}
+				if len(nameAndValue) > 1 {
+					ct.Enum.Name = nameAndValue[1]
+				}
 			case "deprecated":
 				rename := ""
 				if len(nameAndValue) > 1 {
@@ -58,9 +74,19 @@ func FromTypeAndTag(typ typex.Type, nameAndFlags string, st reflect.StructTag) *
 					panic(fmt.Errorf("missing onupdate value"))
 				}
 				ct.OnUpdate = &nameAndValue[1]
+			case "stored":
+				if ct.Generated == nil {
+					panic(fmt.Errorf("stored requires generated tag"))
+				}
+				ct.Generated.Stored = true
 			}
 		}
 	}
+
+	if ct.Enum != nil {
+		ct.Enum = enumOf(ct.Type, ct.Enum.Name, ct.Default)
+	}
+
 	return ct
 }
 
@@ -74,14 +100,87 @@ type ColumnDef struct {
 	OnUpdate          *string
 	Null              bool
 	AutoIncrement     bool
+	Online            bool
+	Enum              *Enum
 	DeprecatedActions *DeprecatedActions
+	Generated         *Generated
 	Comment           string
 	Description       []string
 	Relation          []string
 	StructTag         reflect.StructTag
 }
 
+// Generated 表示生成列的表达式，Stored 为 false 时为虚拟列。
+type Generated struct {
+	Expr   string
+	Stored bool
+}
+
+// Enum 表示枚举列的数据库枚举类型名与全部取值（驱动值的文本形式）。
+type Enum struct {
+	Name   string
+	Values []string
+}
+
 // DeprecatedActions 表示字段废弃后的迁移动作。
 type DeprecatedActions struct {
 	RenameTo string `name:"rename"`
 }
+
+type canEnumValues interface {
+	EnumValues() []any
+}
+
+// enumOf 解析枚举取值，未指定类型名时以包名与类型名生成，如 e_model_gender。
+func enumOf(typ typex.Type, name string, defaultValue *string) *Enum {
+	rv, ok := typex.TryNew(typ)
+	if !ok {
+		panic(fmt.Errorf("enum requires enumeration type, but got %s", typ))
+	}
+
+	ev, ok := rv.Interface().(canEnumValues)
+	if !ok {
+		panic(fmt.Errorf("enum requires enumeration type, but got %s", typ))
+	}
+
+	if name == "" {
+		name = camelcase.LowerSnakeCase("e_" + path.Base(typ.PkgPath()) + "_" + typ.Name())
+	}
+
+	e := &Enum{
+		Name: name,
+	}
+
+	// default value (like zero value of int enum) must be one of enum values
+	if defaultValue != nil {
+		e.Values = append(e.Values, strings.Trim(*defaultValue, "'"))
+	}
+
+	for _, v := range ev.EnumValues() {
+		value := enumValueText(v)
+		if !slices.Contains(e.Values, value) {
+			e.Values = append(e.Values, value)
+		}
+	}
+
+	return e
+}
+
+func enumValueText(v any) string {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if dv, err := valuer.Value(); err == nil {
+			v = dv
+		}
+	}
+
+	switch x := v.(type) {
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	default:
+		return fmt.Sprint(x)
+	}
+}
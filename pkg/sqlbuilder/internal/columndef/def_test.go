@@ -1,6 +1,7 @@
 package columndef
 
 import (
+	"database/sql/driver"
 	"reflect"
 	"testing"
 
@@ -9,6 +10,16 @@ import (
 	"github.com/octohelm/x/types"
 )
 
+type taskState int
+
+func (taskState) EnumValues() []any {
+	return []any{taskState(1), taskState(2)}
+}
+
+func (v taskState) Value() (driver.Value, error) {
+	return int64(v), nil
+}
+
 func TestColumnTypeFromTypeAndTag(t *testing.T) {
 	cases := map[string]*ColumnDef{
 		`,deprecated=f_target_env_id`: {
@@ -19,6 +30,10 @@ func TestColumnTypeFromTypeAndTag(t *testing.T) {
 			Type:          types.FromRType(reflect.TypeFor[int]()),
 			AutoIncrement: true,
 		},
+		`,online`: {
+			Type:   types.FromRType(reflect.TypeFor[int64]()),
+			Online: true,
+		},
 		`,null`: {
 			Type: types.FromRType(reflect.TypeFor[float64]()),
 			Null: true,
@@ -42,4 +57,36 @@ func TestColumnTypeFromTypeAndTag(t *testing.T) {
 			testingx.Expect(t, FromTypeAndTag(ct.Type, tagValue, ""), testingx.Equal(ct))
 		})
 	}
+
+	t.Run("generated", func(t *testing.T) {
+		st := reflect.StructTag(`db:"f_total,stored" generated:"f_price * f_quantity"`)
+
+		testingx.Expect(t, FromTypeAndTag(types.FromRType(reflect.TypeFor[int64]()), ",stored", st), testingx.Equal(&ColumnDef{
+			Type:      types.FromRType(reflect.TypeFor[int64]()),
+			Generated: &Generated{Expr: "f_price * f_quantity", Stored: true},
+			StructTag: st,
+		}))
+	})
+
+	t.Run("enum", func(t *testing.T) {
+		testingx.Expect(t, FromTypeAndTag(types.FromRType(reflect.TypeFor[taskState]()), ",enum,default='0'", ""), testingx.Equal(&ColumnDef{
+			Type:    types.FromRType(reflect.TypeFor[taskState]()),
+			Default: ptr.Ptr(`'0'`),
+			Enum: &Enum{
+				Name:   "e_columndef_task_state",
+				Values: []string{"0", "1", "2"},
+			},
+		}))
+	})
+
+	t.Run("enum with type name", func(t *testing.T) {
+		testingx.Expect(t, FromTypeAndTag(types.FromRType(reflect.TypeFor[taskState]()), ",enum=e_task_state,default='0'", ""), testingx.Equal(&ColumnDef{
+			Type:    types.FromRType(reflect.TypeFor[taskState]()),
+			Default: ptr.Ptr(`'0'`),
+			Enum: &Enum{
+				Name:   "e_task_state",
+				Values: []string{"0", "1", "2"},
+			},
+		}))
+	})
 }
@@ -42,6 +42,8 @@ type Table[Model internal.Model] interface {
 	ColumnSeq[Model]
 
 	KeySeq[Model]
+
+	sqlbuilder.CheckSeq
 }
 
 // ColumnSeq 是类型化列迭代器。
@@ -66,6 +68,10 @@ func (t *table[Model]) Unwrap() sqlbuilder.Table {
 	return t.Table
 }
 
+func (t *table[Model]) Checks() iter.Seq[sqlbuilder.Check] {
+	return sqlbuilder.GetTableChecks(t.Table)
+}
+
 func (t *table[Model]) MK(name string) Key[Model] {
 	return CastKey[Model](t.K(name))
 }
@@ -0,0 +1,32 @@
+package sqlbuilder
+
+import (
+	"github.com/octohelm/storage/pkg/sqlfrag"
+)
+
+// GetStmtTable 返回 INSERT、UPDATE、DELETE 或 MERGE 语句写入的表，WITH 语句按其主语句解析；不是写入语句时返回 nil。
+func GetStmtTable(stmt sqlfrag.Fragment) Table {
+	switch x := stmt.(type) {
+	case *StmtInsert:
+		if x != nil {
+			return x.table
+		}
+	case *StmtUpdate:
+		if x != nil {
+			return x.table
+		}
+	case *StmtDelete:
+		if x != nil {
+			return x.table
+		}
+	case *StmtMerge:
+		if x != nil {
+			return x.table
+		}
+	case *WithStmt:
+		if !x.IsNil() {
+			return GetStmtTable(x.statement(x.tables...))
+		}
+	}
+	return nil
+}
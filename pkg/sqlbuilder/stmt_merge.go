@@ -0,0 +1,150 @@
+package sqlbuilder
+
+import (
+	"context"
+	"iter"
+
+	"github.com/octohelm/storage/pkg/sqlfrag"
+)
+
+// MergeInto 创建 MERGE 语句（PostgreSQL 15+）。
+func MergeInto(table Table, additions ...Addition) *StmtMerge {
+	return &StmtMerge{
+		table:     table,
+		additions: additions,
+	}
+}
+
+// StmtMerge 表示 MERGE 语句。
+type StmtMerge struct {
+	table     Table
+	using     sqlfrag.Fragment
+	on        sqlfrag.Fragment
+	whens     []sqlfrag.Fragment
+	additions Additions
+}
+
+func (s *StmtMerge) IsNil() bool {
+	return s == nil || sqlfrag.IsNil(s.table) || sqlfrag.IsNil(s.using) || len(s.whens) == 0
+}
+
+func (s StmtMerge) Using(source sqlfrag.Fragment, on sqlfrag.Fragment) *StmtMerge {
+	s.using = source
+	s.on = on
+	return &s
+}
+
+func (s StmtMerge) WhenMatched(where sqlfrag.Fragment, action MergeAction) *StmtMerge {
+	s.whens = append(s.whens, mergeWhen("WHEN MATCHED", where, action))
+	return &s
+}
+
+func (s StmtMerge) WhenNotMatched(where sqlfrag.Fragment, action MergeAction) *StmtMerge {
+	s.whens = append(s.whens, mergeWhen("WHEN NOT MATCHED", where, action))
+	return &s
+}
+
+func (s *StmtMerge) Frag(ctx context.Context) iter.Seq2[string, []any] {
+	// target and source always co-exist
+	ctx = ContextWithToggles(ctx, Toggles{
+		ToggleMultiTable: true,
+	})
+
+	return func(yield func(string, []any) bool) {
+		if !yield("\nMERGE INTO ", nil) {
+			return
+		}
+
+		for q, args := range s.table.Frag(ctx) {
+			if !yield(q, args) {
+				return
+			}
+		}
+
+		for q, args := range sqlfrag.Pair("\nUSING ?\nON ?", s.using, s.on).Frag(ctx) {
+			if !yield(q, args) {
+				return
+			}
+		}
+
+		for _, when := range s.whens {
+			if !yield("\n", nil) {
+				return
+			}
+
+			for q, args := range when.Frag(ctx) {
+				if !yield(q, args) {
+					return
+				}
+			}
+		}
+
+		for q, args := range s.additions.Frag(ctx) {
+			if !yield(q, args) {
+				return
+			}
+		}
+	}
+}
+
+func mergeWhen(prefix string, where sqlfrag.Fragment, action MergeAction) sqlfrag.Fragment {
+	if sqlfrag.IsNil(where) {
+		return sqlfrag.Pair(prefix+" THEN ?", action)
+	}
+	return sqlfrag.Pair(prefix+" AND ? THEN ?", where, action)
+}
+
+// MergeAction 表示 MERGE 中 WHEN 分支执行的动作。
+type MergeAction interface {
+	sqlfrag.Fragment
+
+	mergeAction()
+}
+
+// MergeUpdateSet 创建 MERGE 的 UPDATE SET 动作。
+func MergeUpdateSet(assignments ...Assignment) MergeAction {
+	return &mergeAction{
+		frag: sqlfrag.Pair("UPDATE SET ?", Assignments(assignments)),
+	}
+}
+
+// MergeInsert 创建 MERGE 的 INSERT 动作。
+func MergeInsert(cols ColumnSeq, values ...sqlfrag.Fragment) MergeAction {
+	return &mergeAction{
+		frag: sqlfrag.Pair(
+			"INSERT ? VALUES ?",
+			sqlfrag.InlineBlock(sqlfrag.Join(",", sqlfrag.Map(cols.Cols(), func(col Column) sqlfrag.Fragment {
+				return sqlfrag.Const(col.Name())
+			}))),
+			sqlfrag.InlineBlock(sqlfrag.JoinValues(",", values...)),
+		),
+	}
+}
+
+// MergeDelete 创建 MERGE 的 DELETE 动作。
+func MergeDelete() MergeAction {
+	return &mergeAction{
+		frag: sqlfrag.Const("DELETE"),
+	}
+}
+
+// MergeDoNothing 创建 MERGE 的 DO NOTHING 动作。
+func MergeDoNothing() MergeAction {
+	return &mergeAction{
+		frag: sqlfrag.Const("DO NOTHING"),
+	}
+}
+
+type mergeAction struct {
+	frag sqlfrag.Fragment
+}
+
+func (mergeAction) mergeAction() {}
+
+func (a *mergeAction) IsNil() bool {
+	return a == nil || sqlfrag.IsNil(a.frag)
+}
+
+func (a *mergeAction) Frag(ctx context.Context) iter.Seq2[string, []any] {
+	return a.frag.Frag(ctx)
+}
@@ -0,0 +1,64 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	testingx "github.com/octohelm/x/testing"
+
+	. "github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlfrag/testutil"
+)
+
+func TestStmtMerge(t *testing.T) {
+	t0 := T(
+		"t_0",
+		Col("f_a"),
+		Col("f_b"),
+	)
+	t1 := T(
+		"t_1",
+		Col("f_a"),
+		Col("f_b"),
+	)
+
+	t.Run("merge", func(t *testing.T) {
+		testingx.Expect[sqlfrag.Fragment](t,
+			MergeInto(t0).
+				Using(
+					sqlfrag.Pair("? AS s", t1),
+					sqlfrag.Pair("t_0.f_a = s.f_a"),
+				).
+				WhenMatched(
+					sqlfrag.Pair("t_0.f_b <> s.f_b"),
+					MergeUpdateSet(ColumnsAndValues(t0.F("f_b"), sqlfrag.Pair("s.f_b"))),
+				).
+				WhenNotMatched(
+					nil,
+					MergeInsert(t0, sqlfrag.Pair("s.f_a"), sqlfrag.Pair("s.f_b")),
+				),
+			testutil.BeFragment(`
+MERGE INTO t_0
+USING t_1 AS s
+ON t_0.f_a = s.f_a
+WHEN MATCHED AND t_0.f_b <> s.f_b THEN UPDATE SET f_b = s.f_b
+WHEN NOT MATCHED THEN INSERT (f_a,f_b) VALUES (s.f_a,s.f_b)
+`))
+	})
+
+	t.Run("merge do nothing", func(t *testing.T) {
+		testingx.Expect[sqlfrag.Fragment](t,
+			MergeInto(t0).
+				Using(
+					sqlfrag.Pair("? AS s", t1),
+					sqlfrag.Pair("t_0.f_a = s.f_a"),
+				).
+				WhenMatched(nil, MergeDoNothing()),
+			testutil.BeFragment(`
+MERGE INTO t_0
+USING t_1 AS s
+ON t_0.f_a = s.f_a
+WHEN MATCHED THEN DO NOTHING
+`))
+	})
+}
@@ -0,0 +1,42 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	testingx "github.com/octohelm/x/testing"
+
+	. "github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+)
+
+func TestGetStmtTable(t *testing.T) {
+	table := T("T", Col("f_a"))
+
+	cases := map[string]struct {
+		stmt   sqlfrag.Fragment
+		expect string
+	}{
+		"insert": {Insert().Into(table).Values(Cols("f_a"), 1), "T"},
+		"update": {Update(table).Set(CastColumn[int](table.F("f_a")).By(Value(1))), "T"},
+		"delete": {Delete().From(table), "T"},
+		"with": {
+			With(T("tmp"), func(tmp Table) sqlfrag.Fragment {
+				return Select(nil).From(table)
+			}).Exec(func(tables ...Table) sqlfrag.Fragment {
+				return Delete().From(table)
+			}),
+			"T",
+		},
+		"select": {Select(nil).From(table), ""},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			name := ""
+			if tt := GetStmtTable(c.stmt); tt != nil {
+				name = tt.TableName()
+			}
+			testingx.Expect(t, name, testingx.Be(c.expect))
+		})
+	}
+}
@@ -86,6 +86,18 @@ func TestTypedColumnValuers(t *testing.T) {
 		Expect(argsLte, Equal([]any{9})),
 	)
 
+	qEscaped, argsEscaped := sqlfrag.Collect(context.Background(), nameCol.V(sqlbuilder.Like(`50%_off\`)))
+	qRightEscaped, argsRightEscaped := sqlfrag.Collect(context.Background(), nameCol.V(sqlbuilder.RightLike("a_b")))
+
+	Then(
+		t, "LIKE 系列 valuer 转义用户输入的通配符",
+		Expect(qEscaped, Equal(`f_name LIKE ? ESCAPE '\'`)),
+		Expect(argsEscaped, Equal([]any{`%50\%\_off\\%`})),
+		Expect(qRightEscaped, Equal(`f_name LIKE ? ESCAPE '\'`)),
+		Expect(argsRightEscaped, Equal([]any{`a\_b%`})),
+		Expect(sqlbuilder.EscapeLike("100%"), Equal(`100\%`)),
+	)
+
 	qValue, argsValue := sqlfrag.Collect(context.Background(), created.By(sqlbuilder.Value("x")))
 	qAsValue, _ := sqlfrag.Collect(context.Background(), created.By(sqlbuilder.AsValue(nameCol)))
 	qIncr, argsIncr := sqlfrag.Collect(context.Background(), idCol.By(sqlbuilder.Incr(1)))
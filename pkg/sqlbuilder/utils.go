@@ -3,7 +3,9 @@ package sqlbuilder
 import (
 	"context"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 
@@ -120,6 +122,20 @@ func scanDefToTable(tab *table, i any) {
 		}).Of(tab))
 	}
 
+	predicates := IndexPredicates{}
+	if indexPredicatesHook, ok := i.(WithIndexPredicates); ok {
+		for name, predicate := range indexPredicatesHook.IndexPredicates() {
+			predicates[strings.ToLower(name)] = predicate
+		}
+	}
+
+	concurrentIndexes := map[string]bool{}
+	if concurrentIndexesHook, ok := i.(WithConcurrentIndexes); ok {
+		for _, name := range concurrentIndexesHook.ConcurrentIndexes() {
+			concurrentIndexes[strings.ToLower(name)] = true
+		}
+	}
+
 	if uniqueIndexesHook, ok := i.(WithUniqueIndexes); ok {
 		for indexNameAndMethod, fieldNames := range uniqueIndexesHook.UniqueIndexes() {
 			indexName, method := resolveIndexNameAndMethod(indexNameAndMethod)
@@ -127,18 +143,28 @@ func scanDefToTable(tab *table, i any) {
 			tab.KeyCollection.(KeyCollectionManager).AddKey((&key{
 				name:                indexName,
 				method:              method,
+				where:               predicates[indexName],
+				concurrently:        concurrentIndexes[indexName],
 				isUnique:            true,
 				fieldNameAndOptions: FieldNameAndOptionFromStringSlice(fieldNames),
 			}).Of(tab))
 		}
 	}
 
+	if checksHook, ok := i.(WithChecks); ok {
+		for _, name := range slices.Sorted(maps.Keys(checksHook.Checks())) {
+			tab.AddCheck(CheckConstraint(name, checksHook.Checks()[name]))
+		}
+	}
+
 	if indexesHook, ok := i.(WithIndexes); ok {
 		for indexNameAndMethod, fieldNames := range indexesHook.Indexes() {
 			indexName, method := resolveIndexNameAndMethod(indexNameAndMethod)
 			tab.KeyCollection.(KeyCollectionManager).AddKey((&key{
 				name:                indexName,
 				method:              method,
+				where:               predicates[indexName],
+				concurrently:        concurrentIndexes[indexName],
 				fieldNameAndOptions: FieldNameAndOptionFromStringSlice(fieldNames),
 			}).Of(tab))
 		}
@@ -158,9 +184,16 @@ func resolveIndexNameAndMethod(n string) (name string, method string) {
 // 例如：
 // @def index i_xxx,BTREE Name
 // @def index i_xxx,GIST TEST,gist_trgm_ops
+// @def index i_lower_name (lower(f_name)),desc
+// @def unique_index i_name Name WHERE f_deleted_at = 0
 func ParseIndexDefine(def string) *IndexDefine {
 	d := IndexDefine{}
 
+	if i := strings.Index(def, " WHERE "); i != -1 {
+		d.Where = strings.TrimSpace(def[i+len(" WHERE "):])
+		def = def[0:i]
+	}
+
 	for i := strings.Index(def, " "); i != -1; i = strings.Index(def, " ") {
 		part := def[0:i]
 
@@ -177,16 +210,40 @@ func ParseIndexDefine(def string) *IndexDefine {
 		def = def[i+1:]
 	}
 
-	d.FieldNameAndOptions = FieldNameAndOptionFromStringSlice(strings.Split(strings.TrimSpace(def), " "))
+	d.FieldNameAndOptions = FieldNameAndOptionFromStringSlice(splitFieldNameAndOptions(strings.TrimSpace(def)))
 
 	return &d
 }
 
+// splitFieldNameAndOptions 按空格切分，忽略表达式括号内的空格。
+func splitFieldNameAndOptions(s string) []string {
+	parts := make([]string, 0)
+	depth := 0
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ' ':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
 // IndexDefine 表示解析后的索引定义。
 type IndexDefine struct {
 	Kind                string
 	Name                string
 	Method              string
+	Where               string
 	FieldNameAndOptions []FieldNameAndOption
 }
 
@@ -212,15 +269,20 @@ func FieldNameAndOptionFromStringSlice(slices []string) []FieldNameAndOption {
 }
 
 // {FieldName}[,DESC][,NULLS][,FIRST]
+// 或表达式 ({Expr})[,DESC]，如 (lower(f_name)),desc
 type FieldNameAndOption string
 
+// IsExpr 判断是否为表达式索引项。
+func (x FieldNameAndOption) IsExpr() bool {
+	return strings.HasPrefix(string(x), "(")
+}
+
 func (x FieldNameAndOption) Name() string {
-	s := string(x)
-	i := strings.Index(s, ",")
-	if i > 0 {
-		return pickFieldName(s[0:i])
+	name, _ := x.split()
+	if x.IsExpr() {
+		return name
 	}
-	return pickFieldName(s)
+	return pickFieldName(name)
 }
 
 func pickFieldName(ref string) string {
@@ -229,10 +291,36 @@ func pickFieldName(ref string) string {
 }
 
 func (x FieldNameAndOption) Options() []string {
+	_, options := x.split()
+	if options != "" {
+		return strings.Split(strings.ToUpper(options), ",")
+	}
+	return nil
+}
+
+func (x FieldNameAndOption) split() (name string, options string) {
 	s := string(x)
+
+	if x.IsExpr() {
+		depth := 0
+		for i := 0; i < len(s); i++ {
+			switch s[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					_, options, _ = strings.Cut(s[i+1:], ",")
+					return s[0 : i+1], options
+				}
+			}
+		}
+		return s, ""
+	}
+
 	i := strings.Index(s, ",")
 	if i > 0 {
-		return strings.Split(strings.ToUpper(s[i+1:]), ",")
+		return s[0:i], s[i+1:]
 	}
-	return nil
+	return s, ""
 }
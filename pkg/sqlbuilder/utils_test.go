@@ -21,3 +21,26 @@ func TestParseIndexDefine(t *testing.T) {
 	testingx.Expect(t, i.FieldNameAndOptions[0].Name(), testingx.Be("TEST"))
 	testingx.Expect(t, i.FieldNameAndOptions[0].Options(), testingx.Equal([]string{"GIST_TRGM_OPS"}))
 }
+
+func TestParseIndexDefineWithExprAndWhere(t *testing.T) {
+	i := ParseIndexDefine("unique_index i_name (lower(f_name)),desc DeletedAt WHERE f_deleted_at = 0")
+
+	testingx.Expect(t, i, testingx.Equal(&IndexDefine{
+		Kind:  "unique_index",
+		Name:  "i_name",
+		Where: "f_deleted_at = 0",
+		FieldNameAndOptions: []FieldNameAndOption{
+			"(lower(f_name)),desc",
+			"DeletedAt",
+		},
+	}))
+
+	testingx.Expect(t, i.FieldNameAndOptions[0].IsExpr(), testingx.Be(true))
+	testingx.Expect(t, i.FieldNameAndOptions[0].Name(), testingx.Be("(lower(f_name))"))
+	testingx.Expect(t, i.FieldNameAndOptions[0].Options(), testingx.Equal([]string{"DESC"}))
+
+	spaced := ParseIndexDefine("index i_full_name (f_first_name || ' ' || f_last_name)")
+	testingx.Expect(t, spaced.FieldNameAndOptions, testingx.Equal([]FieldNameAndOption{
+		"(f_first_name || ' ' || f_last_name)",
+	}))
+}
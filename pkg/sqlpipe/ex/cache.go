@@ -0,0 +1,227 @@
+package ex
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	contextx "github.com/octohelm/x/context"
+
+	"github.com/octohelm/storage/internal/sql/adapter"
+	"github.com/octohelm/storage/pkg/session"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/pkg/sqlpipe/internal"
+)
+
+// Cache 定义查询结果缓存后端。
+type Cache interface {
+	// Get 返回 key 对应且未过期的缓存值。
+	Get(ctx context.Context, key string) (any, bool)
+	// Generation 返回依赖表当前的失效代数，每次 Invalidate 都会使其增大。
+	Generation(ctx context.Context, tables ...string) uint64
+	// Set 写入缓存值，并记录其依赖的表以便失效；generation 为查询前取得的失效代数，期间依赖表已失效时丢弃该值。
+	Set(ctx context.Context, key string, value any, ttl time.Duration, generation uint64, tables ...string)
+	// Invalidate 使依赖任一表的缓存值失效。
+	Invalidate(ctx context.Context, tables ...string)
+}
+
+var cacheContext = contextx.New[Cache]()
+
+// InjectCache 把查询结果缓存注入上下文；经会话适配器直接执行的 sqlbuilder 写入语句同样会使目标表的缓存失效。
+func InjectCache(ctx context.Context, c Cache) context.Context {
+	return adapter.ContextWithWriteObserver(cacheContext.Inject(ctx, c), func(ctx context.Context, tables ...string) {
+		invalidateTables(ctx, c, tables...)
+	})
+}
+
+// CacheFromContext 返回上下文中的查询结果缓存。
+func CacheFromContext(ctx context.Context) (Cache, bool) {
+	return cacheContext.MayFrom(ctx)
+}
+
+// CacheTTL 为当前执行器的 Items、List、CountTo 开启查询缓存，需配合 InjectCache 使用。
+//
+// 仅在通过 PipeE 追加时生效；事务内查询与写入不读写缓存。命中时返回缓存值的副本。
+func CacheTTL[M sqlpipe.Model](ttl time.Duration) sqlpipe.SourceOperator[M] {
+	return &cacheTTLOperator[M]{ttl: ttl}
+}
+
+type cacheTTLOperator[M sqlpipe.Model] struct {
+	ttl time.Duration
+}
+
+func (c *cacheTTLOperator[M]) OperatorType() sqlpipe.OperatorType {
+	return sqlpipe.OperatorSetting
+}
+
+func (c *cacheTTLOperator[M]) Next(src sqlpipe.Source[M]) sqlpipe.Source[M] {
+	return src
+}
+
+// tables 返回数据源的目标表与 JOIN 表，以及数据源是否为写入。
+func (e *Executor[M]) tables(ctx context.Context) (tables []string, mutating bool) {
+	b := e.source().ApplyStmt(ctx, &internal.Builder[M]{})
+
+	_, mutating = b.Source.(*internal.Mutation[M])
+
+	tables = append(tables, b.T(ctx, nil).TableName())
+
+	for _, j := range b.TableJoins {
+		if t := sqlbuilder.GetJoinTable(j); t != nil {
+			tables = append(tables, t.TableName())
+		}
+	}
+
+	return tables, mutating || e.forCommit
+}
+
+// cacheFor 返回可用于当前查询的缓存与缓存键；参数无法转换为驱动值时不缓存。
+func (e *Executor[M]) cacheFor(ctx context.Context, name string, frag sqlfrag.Fragment) (c Cache, key string, tables []string, ok bool) {
+	e.source()
+
+	if e.cacheTTL <= 0 {
+		return nil, "", nil, false
+	}
+
+	c, ok = CacheFromContext(ctx)
+	if !ok || session.InTx(ctx) {
+		return nil, "", nil, false
+	}
+
+	tables, mutating := e.tables(ctx)
+	if mutating {
+		return nil, "", nil, false
+	}
+
+	q, args := sqlfrag.Collect(ctx, frag)
+	if q == "" {
+		return nil, "", nil, false
+	}
+
+	key, ok = cacheKey(name, q, args)
+	if !ok {
+		return nil, "", nil, false
+	}
+
+	return c, key, tables, true
+}
+
+// invalidate 在写入成功后使目标表的缓存失效。
+func (e *Executor[M]) invalidate(ctx context.Context) {
+	c, ok := CacheFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	tables, mutating := e.tables(ctx)
+	if !mutating {
+		return
+	}
+
+	invalidateTables(ctx, c, tables[0])
+}
+
+// invalidateTables 使表的缓存失效。
+//
+// 事务内写入在提交前对其他连接不可见，期间的并发读取可能把旧数据重新写入缓存，因此提交后会再失效一次。
+func invalidateTables(ctx context.Context, c Cache, tables ...string) {
+	c.Invalidate(ctx, tables...)
+
+	if session.InTx(ctx) {
+		session.AfterCommit(ctx, func(ctx context.Context) {
+			c.Invalidate(ctx, tables...)
+		})
+	}
+}
+
+// selfInvalidating 屏蔽适配器层的写入观察，执行器的写入由 invalidate 按数据源的目标表失效，避免重复失效。
+func selfInvalidating(ctx context.Context) context.Context {
+	return adapter.ContextWithWriteObserver(ctx, nil)
+}
+
+// cloneOf 深拷贝缓存值中的指针、切片与映射，避免调用方修改命中结果时污染缓存；未导出字段仍为浅拷贝。
+func cloneOf[T any](v T) T {
+	out := reflect.New(reflect.TypeFor[T]()).Elem()
+	deepCopy(out, reflect.ValueOf(&v).Elem())
+	return out.Interface().(T)
+}
+
+func deepCopy(dst reflect.Value, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		deepCopy(dst.Elem(), src.Elem())
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := range src.Len() {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Array:
+		for i := range src.Len() {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		for iter := src.MapRange(); iter.Next(); {
+			v := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(v, iter.Value())
+			dst.SetMapIndex(iter.Key(), v)
+		}
+	case reflect.Struct:
+		dst.Set(src)
+		for i := range src.NumField() {
+			if f := dst.Field(i); f.CanSet() {
+				deepCopy(f, src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// cacheKey 以查询文本与参数的驱动值计算缓存键，指针参数按其指向的值参与计算。
+func cacheKey(name string, query string, args []any) (string, bool) {
+	h := sha256.New()
+
+	_, _ = fmt.Fprintf(h, "%s\x00%s", name, query)
+
+	for _, arg := range args {
+		v, err := driver.DefaultParameterConverter.ConvertValue(arg)
+		if err != nil {
+			return "", false
+		}
+
+		_, _ = io.WriteString(h, "\x00")
+
+		switch x := v.(type) {
+		case nil:
+			_, _ = io.WriteString(h, "nil")
+		case []byte:
+			_, _ = fmt.Fprintf(h, "bytes:%x", x)
+		case string:
+			_, _ = fmt.Fprintf(h, "string:%q", x)
+		case time.Time:
+			_, _ = fmt.Fprintf(h, "time:%s", x.Format(time.RFC3339Nano))
+		default:
+			_, _ = fmt.Fprintf(h, "%T:%v", x, x)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), true
+}
@@ -0,0 +1,142 @@
+package ex
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// NewLRUCache 创建容量为 size 的进程内 LRU 查询缓存。
+func NewLRUCache(size int) Cache {
+	if size <= 0 {
+		size = 1024
+	}
+
+	return &lruCache{
+		size:        size,
+		ll:          list.New(),
+		entries:     map[string]*list.Element{},
+		tables:      map[string]map[string]struct{}{},
+		generations: map[string]uint64{},
+	}
+}
+
+type lruCache struct {
+	mu          sync.Mutex
+	size        int
+	ll          *list.List
+	entries     map[string]*list.Element
+	tables      map[string]map[string]struct{}
+	generations map[string]uint64
+}
+
+type lruEntry struct {
+	key       string
+	value     any
+	expiresAt time.Time
+	tables    []string
+}
+
+func (c *lruCache) Get(ctx context.Context, key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*lruEntry)
+	if time.Now().After(e.expiresAt) {
+		c.remove(el)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	return e.value, true
+}
+
+func (c *lruCache) Generation(ctx context.Context, tables ...string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generationOf(tables)
+}
+
+// generationOf 累加各表的失效代数，任一表失效都会使结果增大。
+func (c *lruCache) generationOf(tables []string) uint64 {
+	g := uint64(0)
+	for _, t := range tables {
+		g += c.generations[t]
+	}
+	return g
+}
+
+func (c *lruCache) Set(ctx context.Context, key string, value any, ttl time.Duration, generation uint64, tables ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// invalidated after the value was read, which may be stale
+	if c.generationOf(tables) != generation {
+		return
+	}
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+
+	e := &lruEntry{
+		key:       key,
+		value:     value,
+		expiresAt: time.Now().Add(ttl),
+		tables:    tables,
+	}
+
+	c.entries[key] = c.ll.PushFront(e)
+
+	for _, t := range tables {
+		keys, ok := c.tables[t]
+		if !ok {
+			keys = map[string]struct{}{}
+			c.tables[t] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *lruCache) Invalidate(ctx context.Context, tables ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, t := range tables {
+		c.generations[t]++
+
+		for key := range c.tables[t] {
+			if el, ok := c.entries[key]; ok {
+				c.remove(el)
+			}
+		}
+		delete(c.tables, t)
+	}
+}
+
+func (c *lruCache) remove(el *list.Element) {
+	e := el.Value.(*lruEntry)
+
+	c.ll.Remove(el)
+	delete(c.entries, e.key)
+
+	for _, t := range e.tables {
+		if keys, ok := c.tables[t]; ok {
+			delete(keys, e.key)
+			if len(keys) == 0 {
+				delete(c.tables, t)
+			}
+		}
+	}
+}
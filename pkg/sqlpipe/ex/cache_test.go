@@ -0,0 +1,214 @@
+package ex
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/storage/internal/testutil"
+	"github.com/octohelm/storage/pkg/session"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/testdata/model"
+)
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(2)
+
+	c.Set(ctx, "a", 1, time.Minute, 0, "t_user")
+	c.Set(ctx, "b", 2, time.Minute, 0, "t_org")
+	_, _ = c.Get(ctx, "a")
+	c.Set(ctx, "c", 3, time.Minute, 0, "t_user", "t_org")
+
+	_, hasA := c.Get(ctx, "a")
+	_, hasB := c.Get(ctx, "b")
+	Then(
+		t, "超出容量时淘汰最久未使用的值",
+		Expect(hasA, Equal(true)),
+		Expect(hasB, Equal(false)),
+	)
+
+	c.Invalidate(ctx, "t_org")
+	_, hasA = c.Get(ctx, "a")
+	_, hasC := c.Get(ctx, "c")
+	Then(
+		t, "按表失效依赖该表的值",
+		Expect(hasA, Equal(true)),
+		Expect(hasC, Equal(false)),
+	)
+
+	c.Set(ctx, "d", 4, -time.Second, 0)
+	_, hasD := c.Get(ctx, "d")
+	Then(
+		t, "过期的值不再返回",
+		Expect(hasD, Equal(false)),
+	)
+
+	generation := c.Generation(ctx, "t_user", "t_org")
+	c.Invalidate(ctx, "t_user")
+	c.Set(ctx, "e", 5, time.Minute, generation, "t_user", "t_org")
+	_, hasE := c.Get(ctx, "e")
+	Then(
+		t, "查询期间依赖表失效时丢弃写入的值",
+		Expect(hasE, Equal(false)),
+		Expect(c.Generation(ctx, "t_user", "t_org"), Equal(generation+1)),
+	)
+}
+
+func TestCacheKey(t *testing.T) {
+	a, b := "a", "a"
+
+	keyA, _ := cacheKey("db", "SELECT ?", []any{&a})
+	keyB, _ := cacheKey("db", "SELECT ?", []any{&b})
+	keyV, _ := cacheKey("db", "SELECT ?", []any{"a"})
+
+	Then(
+		t, "指针参数按指向的值计算缓存键",
+		Expect(keyA, Equal(keyB)),
+		Expect(keyA, Equal(keyV)),
+	)
+
+	_, ok := cacheKey("db", "SELECT ?", []any{[]string{"a"}})
+	Then(
+		t, "无法转换为驱动值的参数不缓存",
+		Expect(ok, Equal(false)),
+	)
+}
+
+func TestCloneOf(t *testing.T) {
+	type value struct {
+		Tags   []string
+		Labels map[string]string
+		Parent *value
+	}
+
+	v := value{Tags: []string{"a"}, Labels: map[string]string{"k": "v"}, Parent: &value{Tags: []string{"b"}}}
+
+	c := cloneOf(v)
+	c.Tags[0] = "x"
+	c.Labels["k"] = "x"
+	c.Parent.Tags[0] = "x"
+
+	Then(
+		t, "修改副本不影响缓存值",
+		Expect(v, Equal(value{Tags: []string{"a"}, Labels: map[string]string{"k": "v"}, Parent: &value{Tags: []string{"b"}}})),
+	)
+}
+
+type countingCache struct {
+	Cache
+	hits          int
+	invalidations int
+}
+
+func (c *countingCache) Invalidate(ctx context.Context, tables ...string) {
+	c.invalidations++
+	c.Cache.Invalidate(ctx, tables...)
+}
+
+func (c *countingCache) Get(ctx context.Context, key string) (any, bool) {
+	v, ok := c.Cache.Get(ctx, key)
+	if ok {
+		c.hits++
+	}
+	return v, ok
+}
+
+func TestQueryCache(t *testing.T) {
+	for _, ctx := range []context.Context{
+		ContextWithDatabase(t, "sqlpipe_cache", ""),
+		ContextWithDatabase(t, "sqlpipe_cache", "postgres://postgres@localhost?sslmode=disable"),
+	} {
+		c := &countingCache{Cache: NewLRUCache(16)}
+		ctx := InjectCache(ctx, c)
+
+		insert := func(n int) {
+			err := FromSource(sqlpipe.ValueSeq(func(yield func(*model.User) bool) {
+				for i := range n {
+					if !yield(&model.User{Name: fmt.Sprintf("user-%d-%d", time.Now().UnixNano(), i), Age: int64(i)}) {
+						return
+					}
+				}
+			})).Commit(ctx)
+			testutil.Expect(t, err, testutil.Be[error](nil))
+		}
+
+		insert(3)
+
+		cached := FromSource(sqlpipe.From[model.User]()).PipeE(
+			sqlpipe.Where(model.UserT.Age, sqlbuilder.Gte[int64](0)),
+			CacheTTL[model.User](time.Minute),
+		)
+
+		t.Run("should hit cache for the same query", func(t *testing.T) {
+			var count int64
+			testutil.Expect(t, cached.CountTo(ctx, &count), testutil.Be[error](nil))
+			testutil.Expect(t, count, testutil.Be[int64](3))
+
+			list, err := cached.List(ctx)
+			testutil.Expect(t, err, testutil.Be[error](nil))
+			testutil.Expect(t, len(list), testutil.Be(3))
+
+			testutil.Expect(t, cached.CountTo(ctx, &count), testutil.Be[error](nil))
+			list, err = cached.List(ctx)
+			testutil.Expect(t, err, testutil.Be[error](nil))
+			testutil.Expect(t, len(list), testutil.Be(3))
+
+			testutil.Expect(t, c.hits, testutil.Be(2))
+		})
+
+		t.Run("should invalidate after commit", func(t *testing.T) {
+			insert(2)
+
+			var count int64
+			testutil.Expect(t, cached.CountTo(ctx, &count), testutil.Be[error](nil))
+			testutil.Expect(t, count, testutil.Be[int64](5))
+			testutil.Expect(t, c.hits, testutil.Be(2))
+		})
+
+		t.Run("should not cache without ttl", func(t *testing.T) {
+			src := FromSource(sqlpipe.From[model.User]())
+
+			var count int64
+			testutil.Expect(t, src.CountTo(ctx, &count), testutil.Be[error](nil))
+			testutil.Expect(t, src.CountTo(ctx, &count), testutil.Be[error](nil))
+			testutil.Expect(t, c.hits, testutil.Be(2))
+		})
+
+		t.Run("should invalidate on writes through session adapter", func(t *testing.T) {
+			var count int64
+			testutil.Expect(t, cached.CountTo(ctx, &count), testutil.Be[error](nil))
+
+			invalidations := c.invalidations
+
+			s := session.For(ctx, &model.User{})
+			_, err := s.Adapter().Exec(ctx, sqlbuilder.Delete().From(s.T(&model.User{}), sqlbuilder.Where(model.UserT.Name.V(sqlbuilder.Eq("nobody")))))
+			testutil.Expect(t, err, testutil.Be[error](nil))
+			testutil.Expect(t, c.invalidations, testutil.Be(invalidations+1))
+		})
+
+		t.Run("should invalidate again after tx committed", func(t *testing.T) {
+			var count int64
+			testutil.Expect(t, cached.CountTo(ctx, &count), testutil.Be[error](nil))
+
+			invalidations := 0
+
+			err := (&Executor[model.User]{}).Tx(ctx, func(ctx context.Context) error {
+				if err := FromSource(sqlpipe.Value(&model.User{Name: fmt.Sprintf("user-%d-tx", time.Now().UnixNano())})).Commit(ctx); err != nil {
+					return err
+				}
+				invalidations = c.invalidations
+				return nil
+			})
+			testutil.Expect(t, err, testutil.Be[error](nil))
+			testutil.Expect(t, c.invalidations, testutil.Be(invalidations+1))
+
+			testutil.Expect(t, cached.CountTo(ctx, &count), testutil.Be[error](nil))
+			testutil.Expect(t, count, testutil.Be[int64](6))
+		})
+	}
+}
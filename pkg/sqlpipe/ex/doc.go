@@ -0,0 +1,6 @@
+// Package ex 提供 sqlpipe 数据源的执行能力。
+// +gengo:runtimedoc=false
+package ex
+
+// P 用于承载包级文档生成入口。
+type P struct{}
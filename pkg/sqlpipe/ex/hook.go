@@ -0,0 +1,193 @@
+package ex
+
+import (
+	"context"
+	"slices"
+
+	"github.com/octohelm/storage/pkg/session"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/pkg/sqlpipe/internal"
+	"github.com/octohelm/storage/pkg/sqltype"
+)
+
+func hasModelHooks[M sqlpipe.Model]() bool {
+	switch any(new(M)).(type) {
+	case sqltype.BeforeInsertHook, sqltype.AfterInsertHook,
+		sqltype.BeforeUpdateHook, sqltype.AfterUpdateHook,
+		sqltype.BeforeDeleteHook, sqltype.AfterDeleteHook:
+		return true
+	}
+	return false
+}
+
+// mutation 表示需要执行写入回调的写入。
+type mutation[M sqlpipe.Model] struct {
+	b      *internal.Builder[M]
+	hooks  []session.Hook
+	change *session.Change
+	values []*M
+}
+
+// prepareMutation 在存在写入回调时解析写入；不是写入或没有回调时返回 nil。
+func prepareMutation[M sqlpipe.Model](ctx context.Context, s session.Session, src sqlpipe.Source[M]) *mutation[M] {
+	hooks := session.HooksOf(s.Name())
+	if len(hooks) == 0 && !hasModelHooks[M]() {
+		return nil
+	}
+
+	b := &internal.Builder[M]{}
+	if f, ok := internal.FlagContext.MayFrom(ctx); ok {
+		b.Flag = f
+	}
+	b = src.ApplyStmt(ctx, b)
+
+	mut, ok := b.Source.(*internal.Mutation[M])
+	if !ok {
+		return nil
+	}
+
+	m := &mutation[M]{
+		hooks: hooks,
+		change: &session.Change{
+			Table: b.T(ctx, nil),
+		},
+	}
+
+	if mut.Values != nil {
+		// collect once to make sure hooks and statement see the same values
+		m.values = slices.Collect(mut.Values)
+
+		next := *mut
+		next.Values = slices.Values(m.values)
+		mut = &next
+
+		b = b.WithSource(mut)
+	}
+
+	switch {
+	case mut.ForDelete != internal.DeleteTypeNone:
+		m.change.Operation = session.OperationDelete
+	case mut.ForUpdate:
+		m.change.Operation = session.OperationUpdate
+		if assignments := mut.PrepareAssignments(ctx, m.change.Table); assignments != nil {
+			m.change.Assignments = slices.Collect(assignments)
+		}
+	default:
+		m.change.Operation = session.OperationInsert
+	}
+
+	m.change.Values = anyValues(m.values)
+	m.b = b
+
+	return m
+}
+
+// do 在同一事务内依次执行 Before 回调、写入与 After 回调；exec 返回 RETURNING 的记录时传给 After 回调。
+func (m *mutation[M]) do(ctx context.Context, s session.Session, exec func(ctx context.Context, frag sqlfrag.Fragment) ([]*M, error)) error {
+	return s.Tx(ctx, func(ctx context.Context) error {
+		if err := m.before(ctx); err != nil {
+			return err
+		}
+
+		returned, err := exec(ctx, m.b.BuildStmt(ctx))
+		if err != nil {
+			return err
+		}
+
+		return m.after(ctx, returned)
+	})
+}
+
+// before 依次执行模型与会话级的 Before 回调；按条件更新或删除时没有模型值，只执行会话级回调。
+func (m *mutation[M]) before(ctx context.Context) error {
+	for _, v := range m.values {
+		var err error
+
+		switch m.change.Operation {
+		case session.OperationInsert:
+			if x, ok := any(v).(sqltype.BeforeInsertHook); ok {
+				err = x.BeforeInsert(ctx)
+			}
+		case session.OperationUpdate:
+			if x, ok := any(v).(sqltype.BeforeUpdateHook); ok {
+				err = x.BeforeUpdate(ctx, m.change.Assignments)
+			}
+		case session.OperationDelete:
+			if x, ok := any(v).(sqltype.BeforeDeleteHook); ok {
+				err = x.BeforeDelete(ctx)
+			}
+		default:
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, h := range m.hooks {
+		if err := h.BeforeChange(ctx, m.change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// after 依次执行模型与会话级的 After 回调；模型回调的接收者为写入的值或 RETURNING 的记录，二者皆无时跳过。
+func (m *mutation[M]) after(ctx context.Context, returned []*M) error {
+	values := m.values
+
+	change := m.change
+	if returned != nil {
+		values = returned
+
+		c := *m.change
+		c.Values = anyValues(returned)
+		change = &c
+	}
+
+	for _, v := range values {
+		var err error
+
+		switch change.Operation {
+		case session.OperationInsert:
+			if x, ok := any(v).(sqltype.AfterInsertHook); ok {
+				err = x.AfterInsert(ctx)
+			}
+		case session.OperationUpdate:
+			if x, ok := any(v).(sqltype.AfterUpdateHook); ok {
+				err = x.AfterUpdate(ctx, change.Assignments)
+			}
+		case session.OperationDelete:
+			if x, ok := any(v).(sqltype.AfterDeleteHook); ok {
+				err = x.AfterDelete(ctx)
+			}
+		default:
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, h := range m.hooks {
+		if err := h.AfterChange(ctx, change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func anyValues[M sqlpipe.Model](values []*M) []any {
+	if values == nil {
+		return nil
+	}
+
+	list := make([]any, len(values))
+	for i, v := range values {
+		list[i] = v
+	}
+	return list
+}
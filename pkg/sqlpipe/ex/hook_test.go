@@ -0,0 +1,128 @@
+package ex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/octohelm/storage/internal/testutil"
+	"github.com/octohelm/storage/pkg/session"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/testdata/model"
+)
+
+func TestWriteHooks(t *testing.T) {
+	changes := make([]string, 0)
+	committed := make([]string, 0)
+
+	errRejected := errors.New("rejected")
+
+	session.RegisterHook("sqlpipe_hook", session.HookFuncs{
+		Before: func(ctx context.Context, change *session.Change) error {
+			for _, v := range change.Values {
+				if u, ok := v.(*model.User); ok && u.Name == "rejected" {
+					return errRejected
+				}
+			}
+			return nil
+		},
+		After: func(ctx context.Context, change *session.Change) error {
+			changes = append(changes, fmt.Sprintf("%s %s %d %d", change.Operation, change.Table.TableName(), len(change.Values), len(change.Assignments)))
+
+			session.AfterCommit(ctx, func(ctx context.Context) {
+				committed = append(committed, change.Operation.String())
+			})
+			return nil
+		},
+	})
+
+	for _, ctx := range []context.Context{
+		ContextWithDatabase(t, "sqlpipe_hook", ""),
+		ContextWithDatabase(t, "sqlpipe_hook", "postgres://postgres@localhost?sslmode=disable"),
+	} {
+		changes = changes[:0]
+		committed = committed[:0]
+
+		t.Run("should run hooks for insert, update and delete", func(t *testing.T) {
+			err := FromSource(sqlpipe.Values([]*model.User{
+				{Name: "a", Age: 1},
+				{Name: "b", Age: 2},
+			})).Commit(ctx)
+			testutil.Expect(t, err, testutil.Be[error](nil))
+
+			err = FromSource(sqlpipe.From[model.User]()).PipeE(
+				sqlpipe.Where(model.UserT.Name, sqlbuilder.Eq("a")),
+				sqlpipe.DoUpdateSet(&model.User{Nickname: "A"}, model.UserT.Nickname),
+			).Commit(ctx)
+			testutil.Expect(t, err, testutil.Be[error](nil))
+
+			deleted, err := FromSource(sqlpipe.From[model.User]()).PipeE(
+				sqlpipe.Where(model.UserT.Name, sqlbuilder.Eq("b")),
+				sqlpipe.DoDelete[model.User](),
+			).List(ctx)
+			testutil.Expect(t, err, testutil.Be[error](nil))
+			testutil.Expect(t, len(deleted), testutil.Be(1))
+
+			testutil.Expect(t, changes, testutil.Equal([]string{
+				"INSERT t_user 2 0",
+				"UPDATE t_user 1 1",
+				"DELETE t_user 1 0",
+			}))
+			testutil.Expect(t, committed, testutil.Equal([]string{"INSERT", "UPDATE", "DELETE"}))
+		})
+
+		t.Run("should fire after commit hooks only when outer tx committed", func(t *testing.T) {
+			committed = committed[:0]
+
+			err := (&Executor[model.User]{}).Tx(ctx, func(ctx context.Context) error {
+				if err := FromSource(sqlpipe.Value(&model.User{Name: "c", Age: 3})).Commit(ctx); err != nil {
+					return err
+				}
+
+				testutil.Expect(t, len(committed), testutil.Be(0))
+
+				return FromSource(sqlpipe.Value(&model.User{Name: "rejected", Age: 4})).Commit(ctx)
+			})
+			testutil.Expect(t, err, testutil.Be[error](errRejected))
+			testutil.Expect(t, len(committed), testutil.Be(0))
+
+			var count int64
+			err = FromSource(sqlpipe.From[model.User]()).PipeE(
+				sqlpipe.Where(model.UserT.Name, sqlbuilder.Eq("c")),
+			).CountTo(ctx, &count)
+			testutil.Expect(t, err, testutil.Be[error](nil))
+			testutil.Expect(t, count, testutil.Be[int64](0))
+		})
+	}
+}
+
+var errFakeRow = errors.New("model hook called")
+
+type hookedUser struct {
+	model.User
+}
+
+func (hookedUser) BeforeDelete(ctx context.Context) error {
+	return errFakeRow
+}
+
+func (hookedUser) AfterDelete(ctx context.Context) error {
+	return errFakeRow
+}
+
+func TestModelHooksWithoutValues(t *testing.T) {
+	m := &mutation[hookedUser]{
+		change: &session.Change{Operation: session.OperationDelete},
+	}
+
+	t.Run("should skip model hooks when delete by condition", func(t *testing.T) {
+		testutil.Expect(t, m.before(context.Background()), testutil.Be[error](nil))
+		testutil.Expect(t, m.after(context.Background(), nil), testutil.Be[error](nil))
+	})
+
+	t.Run("should call model hooks on returned rows", func(t *testing.T) {
+		testutil.Expect(t, m.after(context.Background(), []*hookedUser{{}}), testutil.Be[error](errFakeRow))
+	})
+}
@@ -0,0 +1,158 @@
+package ex
+
+import (
+	"context"
+	"slices"
+
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
+	"github.com/octohelm/storage/pkg/sqlbuilder/structs"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+)
+
+// DefaultRelationChunkSize 是关联加载时单次 IN 查询的默认值数量。
+const DefaultRelationChunkSize = 500
+
+// Loader 为已查询出的父记录批量加载关联记录。
+type Loader[P sqlpipe.Model] interface {
+	Load(ctx context.Context, parents []*P) error
+}
+
+// LoadRelations 依次执行关联加载。
+func LoadRelations[P sqlpipe.Model](ctx context.Context, parents []*P, loaders ...Loader[P]) error {
+	if len(parents) == 0 {
+		return nil
+	}
+
+	for _, l := range loaders {
+		if err := l.Load(ctx, parents); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rel 以父记录关联列与子记录关联列构造关联加载，operators 会作用于子记录查询。
+//
+// 一对多时传入父表主键与子表外键，如 Rel(UserT.ID, OrgUserT.UserID)；
+// 多对一时传入父表外键与子表主键，如 Rel(OrgUserT.OrgID, OrgT.ID)。
+func Rel[P sqlpipe.Model, C sqlpipe.Model, K comparable](
+	parentKey modelscoped.TypedColumn[P, K],
+	childKey modelscoped.TypedColumn[C, K],
+	operators ...sqlpipe.SourceOperator[C],
+) *Relation[P, C, K] {
+	return &Relation[P, C, K]{
+		parentKey: parentKey,
+		childKey:  childKey,
+		operators: operators,
+		chunkSize: DefaultRelationChunkSize,
+	}
+}
+
+// Relation 描述父记录到子记录的关联加载。
+type Relation[P sqlpipe.Model, C sqlpipe.Model, K comparable] struct {
+	parentKey modelscoped.TypedColumn[P, K]
+	childKey  modelscoped.TypedColumn[C, K]
+	operators []sqlpipe.SourceOperator[C]
+	chunkSize int
+
+	nested []Loader[C]
+	set    Set[K, C]
+	each   func(parent *P, children []*C)
+}
+
+// ChunkSize 设置单次 IN 查询的值数量。
+func (r Relation[P, C, K]) ChunkSize(n int) *Relation[P, C, K] {
+	if n > 0 {
+		r.chunkSize = n
+	}
+	return &r
+}
+
+// With 追加以子记录为父记录的嵌套关联加载。
+func (r Relation[P, C, K]) With(loaders ...Loader[C]) *Relation[P, C, K] {
+	r.nested = append(slices.Clone(r.nested), loaders...)
+	return &r
+}
+
+// Into 把子记录按关联值写入 set。
+func (r Relation[P, C, K]) Into(set Set[K, C]) *Relation[P, C, K] {
+	r.set = set
+	return &r
+}
+
+// Each 为每条父记录回调其关联的子记录。
+func (r Relation[P, C, K]) Each(fn func(parent *P, children []*C)) *Relation[P, C, K] {
+	r.each = fn
+	return &r
+}
+
+func (r *Relation[P, C, K]) Load(ctx context.Context, parents []*P) error {
+	keys := make([]K, 0, len(parents))
+	added := make(map[K]bool, len(parents))
+
+	var zero K
+
+	for _, p := range parents {
+		k, ok := fieldValueOf[K](ctx, p, r.parentKey)
+		if !ok || k == zero || added[k] {
+			continue
+		}
+		added[k] = true
+		keys = append(keys, k)
+	}
+
+	children := make([]*C, 0, len(keys))
+
+	for chunk := range slices.Chunk(keys, r.chunkSize) {
+		list, err := FromSource(sqlpipe.From[C]()).PipeE(
+			append(
+				slices.Clone(r.operators),
+				sqlpipe.Where(r.childKey, sqlbuilder.In(chunk...)),
+			)...,
+		).List(ctx)
+		if err != nil {
+			return err
+		}
+		children = append(children, list...)
+	}
+
+	if err := LoadRelations(ctx, children, r.nested...); err != nil {
+		return err
+	}
+
+	grouped := OneToMulti[K, C]{}
+
+	for _, c := range children {
+		k, ok := fieldValueOf[K](ctx, c, r.childKey)
+		if !ok {
+			continue
+		}
+		grouped.Record(k, c)
+
+		if r.set != nil {
+			r.set.Record(k, c)
+		}
+	}
+
+	if r.each != nil {
+		for _, p := range parents {
+			k, _ := fieldValueOf[K](ctx, p, r.parentKey)
+			r.each(p, grouped[k])
+		}
+	}
+
+	return nil
+}
+
+func fieldValueOf[K comparable](ctx context.Context, v any, col sqlbuilder.Column) (K, bool) {
+	for sfv := range structs.AllFieldValue(ctx, v) {
+		if sfv.Field.FieldName == col.FieldName() {
+			k, ok := sfv.Value.Interface().(K)
+			return k, ok
+		}
+	}
+
+	return *new(K), false
+}
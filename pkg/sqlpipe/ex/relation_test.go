@@ -0,0 +1,91 @@
+package ex
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/octohelm/storage/internal/testutil"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/testdata/model"
+)
+
+func TestRelation(t *testing.T) {
+	for _, ctx := range []context.Context{
+		ContextWithDatabase(t, "sqlpipe_relation", ""),
+		ContextWithDatabase(t, "sqlpipe_relation", "postgres://postgres@localhost?sslmode=disable"),
+	} {
+		users, err := FromSource(sqlpipe.ValueSeq(func(yield func(*model.User) bool) {
+			for i := range 3 {
+				if !yield(&model.User{Name: fmt.Sprintf("user-%d", i), Age: int64(i)}) {
+					return
+				}
+			}
+		})).List(ctx)
+		testutil.Expect(t, err, testutil.Be[error](nil))
+
+		orgs, err := FromSource(sqlpipe.ValueSeq(func(yield func(*model.Org) bool) {
+			for i := range 2 {
+				if !yield(&model.Org{Name: fmt.Sprintf("org-%d", i)}) {
+					return
+				}
+			}
+		})).List(ctx)
+		testutil.Expect(t, err, testutil.Be[error](nil))
+
+		// user-0 in org-0 and org-1, user-1 in org-1, user-2 in none
+		err = FromSource(sqlpipe.Values([]*model.OrgUser{
+			{UserID: users[0].ID, OrgID: orgs[0].ID},
+			{UserID: users[0].ID, OrgID: orgs[1].ID},
+			{UserID: users[1].ID, OrgID: orgs[1].ID},
+		})).Commit(ctx)
+		testutil.Expect(t, err, testutil.Be[error](nil))
+
+		t.Run("should load nested relations", func(t *testing.T) {
+			orgUsers := OneToMulti[model.UserID, model.OrgUser]{}
+			orgsByID := OneToOne[model.OrgID, model.Org]{}
+
+			list, err := FromSource(sqlpipe.From[model.User]()).PipeE(
+				sqlpipe.AscSort(model.UserT.Age),
+			).ListWith(
+				ctx,
+				Rel(model.UserT.ID, model.OrgUserT.UserID).
+					ChunkSize(1).
+					With(
+						Rel(model.OrgUserT.OrgID, model.OrgT.ID).Into(orgsByID),
+					).
+					Into(orgUsers),
+			)
+			testutil.Expect(t, err, testutil.Be[error](nil))
+			testutil.Expect(t, len(list), testutil.Be(3))
+
+			testutil.Expect(t, len(orgUsers[list[0].ID]), testutil.Be(2))
+			testutil.Expect(t, len(orgUsers[list[1].ID]), testutil.Be(1))
+			testutil.Expect(t, len(orgUsers[list[2].ID]), testutil.Be(0))
+			testutil.Expect(t, len(orgsByID), testutil.Be(2))
+			testutil.Expect(t, orgsByID[orgs[1].ID].Name, testutil.Be("org-1"))
+		})
+
+		t.Run("should callback each parent with filtered children", func(t *testing.T) {
+			counts := map[string]int{}
+
+			err := LoadRelations(
+				ctx,
+				users,
+				Rel(
+					model.UserT.ID, model.OrgUserT.UserID,
+					sqlpipe.Where(model.OrgUserT.OrgID, sqlbuilder.Eq(orgs[1].ID)),
+				).Each(func(u *model.User, orgUsers []*model.OrgUser) {
+					counts[u.Name] = len(orgUsers)
+				}),
+			)
+			testutil.Expect(t, err, testutil.Be[error](nil))
+			testutil.Expect(t, counts, testutil.Equal(map[string]int{
+				"user-0": 1,
+				"user-1": 1,
+				"user-2": 0,
+			}))
+		})
+	}
+}
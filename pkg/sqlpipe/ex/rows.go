@@ -0,0 +1,71 @@
+package ex
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/octohelm/storage/pkg/session"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+)
+
+// Row 表示以列名为键的动态结果行。
+type Row = map[string]any
+
+// ListRows 执行查询并以列名为键返回动态结果行，适用于投影在运行时决定、没有对应模型的查询（如聚合报表）。
+//
+// 文本与二进制列统一转为 string，NULL 为 nil。
+func ListRows[M sqlpipe.Model](ctx context.Context, src sqlpipe.Source[M]) ([]Row, error) {
+	m := new(M)
+	s := session.For(ctx, m)
+	if s == nil {
+		return nil, fmt.Errorf("invalid model %T", m)
+	}
+
+	a := s.Adapter(session.ReadOnly())
+	if session.InTx(ctx) {
+		a = s.Adapter()
+	}
+
+	rows, err := a.Query(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Row, 0)
+
+	for rows.Next() {
+		values := make([]any, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := make(Row, len(columns))
+		for i, col := range columns {
+			if raw, ok := values[i].([]byte); ok {
+				row[col] = string(raw)
+				continue
+			}
+			row[col] = values[i]
+		}
+		list = append(list, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
@@ -0,0 +1,42 @@
+package ex
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/storage/internal/testutil"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/testdata/model"
+)
+
+func TestListRows(t *testing.T) {
+	for _, ctx := range []context.Context{
+		ContextWithDatabase(t, "sqlpipe_rows", ""),
+		ContextWithDatabase(t, "sqlpipe_rows", "postgres://postgres@localhost?sslmode=disable"),
+	} {
+		name := fmt.Sprintf("user-%d", time.Now().UnixNano())
+
+		err := FromSource(sqlpipe.Value(&model.User{Name: name, Age: 18})).Commit(ctx)
+		testutil.Expect(t, err, testutil.Be[error](nil))
+
+		Then(
+			t, "按列名返回动态结果行",
+			ExpectMustValue(
+				func() ([]Row, error) {
+					return ListRows(ctx, sqlpipe.FromAll[model.User]().Pipe(
+						sqlpipe.Where(model.UserT.Name, sqlbuilder.Eq(name)),
+						sqlpipe.Select[model.User](model.UserT.Name, model.UserT.Age),
+					))
+				},
+				Equal([]Row{
+					{"f_name": name, "f_age": int64(18)},
+				}),
+			),
+		)
+	}
+}
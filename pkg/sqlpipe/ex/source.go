@@ -7,11 +7,13 @@ import (
 	"iter"
 	"slices"
 	"sync"
+	"time"
 
 	"github.com/octohelm/storage/internal/sql/adapter"
 	"github.com/octohelm/storage/internal/sql/scanner"
 	"github.com/octohelm/storage/pkg/session"
 	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlfrag"
 	"github.com/octohelm/storage/pkg/sqlpipe"
 	exiternal "github.com/octohelm/storage/pkg/sqlpipe/ex/internal"
 	"github.com/octohelm/storage/pkg/sqlpipe/internal"
@@ -35,6 +37,8 @@ type SourceExecutor[M sqlpipe.Model] interface {
 	FindOne(ctx context.Context) (*M, error)
 	// List 执行查询并返回结果列表。
 	List(ctx context.Context) ([]*M, error)
+	// ListWith 执行查询并按 loaders 批量加载关联记录。
+	ListWith(ctx context.Context, loaders ...Loader[M]) ([]*M, error)
 	// ListTo 执行查询并把结果逐条写入接收器。
 	ListTo(ctx context.Context, adder Adder[M]) error
 	// CountTo 执行计数查询并写入目标值。
@@ -61,6 +65,7 @@ type Executor[M sqlpipe.Model] struct {
 	once      sync.Once
 	prepared  sqlpipe.Source[M]
 	forCommit bool
+	cacheTTL  time.Duration
 }
 
 func (e *Executor[M]) adapterOf(ctx context.Context, s session.Session) adapter.Adapter {
@@ -139,7 +144,9 @@ func (e *Executor[M]) source() sqlpipe.Source[M] {
 			for _, op := range operators {
 				if op.OperatorType() == sqlpipe.OperatorCommit {
 					e.forCommit = true
-					break
+				}
+				if c, ok := op.(*cacheTTLOperator[M]); ok {
+					e.cacheTTL = c.ttl
 				}
 			}
 
@@ -203,8 +210,24 @@ func flatten[M sqlpipe.Model](opSeq iter.Seq[sqlpipe.SourceOperator[M]]) iter.Se
 func (e *Executor[M]) Commit(ctx context.Context) error {
 	// always for mutating
 	e.forCommit = true
-	_, err := e.adapterOf(ctx, e.session(ctx)).Exec(ctx, e.source())
-	return err
+	s := e.session(ctx)
+
+	if m := prepareMutation(ctx, s, e.source()); m != nil {
+		if err := m.do(ctx, s, func(ctx context.Context, frag sqlfrag.Fragment) ([]*M, error) {
+			_, err := e.adapterOf(ctx, s).Exec(selfInvalidating(ctx), frag)
+			return nil, err
+		}); err != nil {
+			return err
+		}
+		e.invalidate(ctx)
+		return nil
+	}
+
+	if _, err := e.adapterOf(ctx, s).Exec(selfInvalidating(ctx), e.source()); err != nil {
+		return err
+	}
+	e.invalidate(ctx)
+	return nil
 }
 
 // Items 执行 SQL，并以 iter.Seq2 返回模型或错误。
@@ -216,13 +239,75 @@ func (e *Executor[M]) Items(ctx context.Context) iter.Seq2[*M, error] {
 	)
 
 	x := scanner.RecvFunc[M](func(ctx context.Context, recv func(v *M) error) error {
-		rows, err := e.adapterOf(ctx, s).Query(internal.FlagContext.Inject(ctx, flags.ForReturning), ex)
+		ctx = internal.FlagContext.Inject(ctx, flags.ForReturning)
+
+		if m := prepareMutation(ctx, s, ex); m != nil {
+			if err := m.do(ctx, s, func(ctx context.Context, frag sqlfrag.Fragment) ([]*M, error) {
+				rows, err := e.adapterOf(ctx, s).Query(selfInvalidating(ctx), frag)
+				if err != nil {
+					return nil, err
+				}
+
+				returned := make([]*M, 0)
+				if err := scanner.Scan(ctx, rows, scanner.Recv(func(v *M) error {
+					returned = append(returned, v)
+					return recv(v)
+				})); err != nil {
+					return nil, err
+				}
+				return returned, nil
+			}); err != nil {
+				return err
+			}
+			e.invalidate(ctx)
+			return nil
+		}
+
+		rows, err := e.adapterOf(ctx, s).Query(selfInvalidating(ctx), ex)
 		if err != nil {
 			return err
 		}
-		return scanner.Scan(ctx, rows, scanner.Recv(recv))
+		if err := scanner.Scan(ctx, rows, scanner.Recv(recv)); err != nil {
+			return err
+		}
+		e.invalidate(ctx)
+		return nil
 	})
 
+	if c, key, tables, ok := e.cacheFor(internal.FlagContext.Inject(ctx, flags.ForReturning), s.Name(), ex); ok {
+		return func(yield func(*M, error) bool) {
+			if cached, ok := c.Get(ctx, key); ok {
+				for _, v := range cached.([]M) {
+					v = cloneOf(v)
+					if !yield(&v, nil) {
+						return
+					}
+				}
+				return
+			}
+
+			generation := c.Generation(ctx, tables...)
+			values := make([]M, 0)
+
+			for item, err := range x.Items(ctx) {
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				values = append(values, *item)
+			}
+
+			c.Set(ctx, key, values, e.cacheTTL, generation, tables...)
+
+			for _, v := range values {
+				v = cloneOf(v)
+				if !yield(&v, nil) {
+					return
+				}
+			}
+		}
+	}
+
 	return x.Items(ctx)
 }
 
@@ -240,6 +325,20 @@ func (e *Executor[M]) List(ctx context.Context) ([]*M, error) {
 	return list, nil
 }
 
+// ListWith 执行 SQL，并在返回模型切片前批量加载关联记录。
+func (e *Executor[M]) ListWith(ctx context.Context, loaders ...Loader[M]) ([]*M, error) {
+	list, err := e.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := LoadRelations(ctx, list, loaders...); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
 // CountTo 执行计数 SQL，并把结果写入 int64 指针。
 func (e *Executor[M]) CountTo(ctx context.Context, x *int64) error {
 	s := e.session(ctx)
@@ -249,11 +348,29 @@ func (e *Executor[M]) CountTo(ctx context.Context, x *int64) error {
 		exiternal.ForCount[M](),
 	)
 
+	c, key, tables, cacheable := e.cacheFor(ctx, s.Name(), ex)
+
+	generation := uint64(0)
+	if cacheable {
+		if cached, ok := c.Get(ctx, key); ok {
+			*x = cached.(int64)
+			return nil
+		}
+		generation = c.Generation(ctx, tables...)
+	}
+
 	rows, err := e.adapterOf(ctx, s).Query(ctx, ex)
 	if err != nil {
 		return err
 	}
-	return scanner.Scan(ctx, rows, x)
+	if err := scanner.Scan(ctx, rows, x); err != nil {
+		return err
+	}
+
+	if cacheable {
+		c.Set(ctx, key, *x, e.cacheTTL, generation, tables...)
+	}
+	return nil
 }
 
 // ListTo 执行 SQL，并把模型逐条写入接收器。
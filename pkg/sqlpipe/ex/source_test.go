@@ -13,6 +13,7 @@ import (
 	"github.com/octohelm/storage/pkg/session"
 	sessiondb "github.com/octohelm/storage/pkg/session/db"
 	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
 	"github.com/octohelm/storage/pkg/sqlpipe"
 	"github.com/octohelm/storage/testdata/model"
 	modelfilter "github.com/octohelm/storage/testdata/model/filter"
@@ -73,6 +74,34 @@ func TestSourceExecutor(t *testing.T) {
 				testutil.Expect(t, len(updatedUsers), testutil.Be(100))
 			})
 
+			t.Run("upsert", func(t *testing.T) {
+				toUpserts := make([]*model.User, 0, len(users))
+				for _, u := range users {
+					toUpserts = append(toUpserts, &model.User{
+						Name:     u.Name,
+						Age:      u.Age,
+						Nickname: "upserted",
+					})
+				}
+
+				err := FromSource(sqlpipe.Values(toUpserts)).PipeE(
+					sqlpipe.Upsert(
+						model.UserT.I.IName,
+						[]modelscoped.Column[model.User]{model.UserT.Nickname},
+						sqlpipe.UpdateWhere(model.UserT.Age.V(sqlbuilder.Lt[int64](50))),
+						sqlpipe.UseMerge(),
+					),
+				).Commit(ctx)
+				testutil.Expect(t, err, testutil.Be[error](nil))
+
+				var count int64
+				err = repo.User.PipeE(
+					sqlpipe.Where(model.UserT.Nickname, sqlbuilder.Eq("upserted")),
+				).CountTo(ctx, &count)
+				testutil.Expect(t, err, testutil.Be[error](nil))
+				testutil.Expect(t, count, testutil.Be[int64](50))
+			})
+
 			t.Run("then could count", func(t *testing.T) {
 				var count int64
 				err := repo.User.CountTo(ctx, &count)
@@ -112,6 +141,48 @@ func TestSourceExecutor(t *testing.T) {
 				testutil.Expect(t, count, testutil.Be[int64](50))
 			})
 
+			t.Run("then could list union", func(t *testing.T) {
+				src := FromSource(sqlpipe.UnionAll(
+					sqlpipe.From[model.User]().Pipe(
+						sqlpipe.Where(model.UserT.Age, sqlbuilder.Lt[int64](10)),
+					),
+					sqlpipe.From[model.User]().Pipe(
+						sqlpipe.Where(model.UserT.Age, sqlbuilder.Gte[int64](90)),
+					),
+				)).PipeE(
+					sqlpipe.DescSort(model.UserT.Age),
+				)
+
+				items, err := src.List(ctx)
+				testutil.Expect(t, err, testutil.Be[error](nil))
+				testutil.Expect(t, len(items), testutil.Be(20))
+				testutil.Expect(t, items[0].Age, testutil.Be[int64](99))
+
+				var count int64
+				err = src.CountTo(ctx, &count)
+				testutil.Expect(t, err, testutil.Be[error](nil))
+				testutil.Expect(t, count, testutil.Be[int64](20))
+			})
+
+			t.Run("then could list union of limited sources", func(t *testing.T) {
+				items, err := FromSource(sqlpipe.UnionAll(
+					sqlpipe.From[model.User]().Pipe(
+						sqlpipe.DescSort(model.UserT.Age),
+						sqlpipe.Limit[model.User](1),
+					),
+					sqlpipe.From[model.User]().Pipe(
+						sqlpipe.AscSort(model.UserT.Age),
+						sqlpipe.Limit[model.User](1),
+					),
+				)).PipeE(
+					sqlpipe.DescSort(model.UserT.Age),
+				).List(ctx)
+				testutil.Expect(t, err, testutil.Be[error](nil))
+				testutil.Expect(t, len(items), testutil.Be(2))
+				testutil.Expect(t, items[0].Age, testutil.Be[int64](99))
+				testutil.Expect(t, items[1].Age, testutil.Be[int64](0))
+			})
+
 			t.Run("then delete", func(t *testing.T) {
 				t.Run("delete one ", func(t *testing.T) {
 					src := repo.User.PipeE(
@@ -0,0 +1,312 @@
+package filter
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/octohelm/storage/pkg/filter"
+	"github.com/octohelm/storage/pkg/sort"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+)
+
+// AggFunc 表示聚合函数。
+type AggFunc string
+
+const (
+	AggCount         AggFunc = "count"
+	AggCountDistinct AggFunc = "countdistinct"
+	AggSum           AggFunc = "sum"
+	AggAvg           AggFunc = "avg"
+	AggMin           AggFunc = "min"
+	AggMax           AggFunc = "max"
+)
+
+var aggFuncs = []AggFunc{AggCount, AggCountDistinct, AggSum, AggAvg, AggMin, AggMax}
+
+// Metric 表示聚合指标，文本形式为 `count`、`count(user~id)`、`countdistinct(user~orgID)`、`sum(user~age)` 等。
+type Metric struct {
+	Func AggFunc
+	// Field 为被聚合的字段名，仅 count 可省略
+	Field string
+}
+
+func (m Metric) String() string {
+	if m.Field == "" {
+		return string(m.Func)
+	}
+	return fmt.Sprintf("%s(%s)", m.Func, m.Field)
+}
+
+func (m Metric) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Metric) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+
+	mm := Metric{Func: AggFunc(strings.ToLower(s))}
+
+	if i := strings.IndexByte(s, '('); i > 0 {
+		if !strings.HasSuffix(s, ")") {
+			return &ErrUnsupportedMetric{Metric: s}
+		}
+		mm = Metric{
+			Func:  AggFunc(strings.ToLower(strings.TrimSpace(s[:i]))),
+			Field: strings.Trim(strings.TrimSpace(s[i+1:len(s)-1]), `"`),
+		}
+	}
+
+	if !slices.Contains(aggFuncs, mm.Func) || (mm.Field == "" && mm.Func != AggCount) {
+		return &ErrUnsupportedMetric{Metric: s}
+	}
+
+	*m = mm
+	return nil
+}
+
+// ErrUnsupportedMetric 表示聚合指标不受支持。
+type ErrUnsupportedMetric struct {
+	Metric string
+}
+
+func (ErrUnsupportedMetric) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (e *ErrUnsupportedMetric) Error() string {
+	return fmt.Sprintf("unsupported metric `%s`", e.Metric)
+}
+
+// ErrUnsupportedSortOrder 表示排序方向不受支持，仅允许 asc 与 desc。
+type ErrUnsupportedSortOrder struct {
+	Sort string
+}
+
+func (ErrUnsupportedSortOrder) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (e *ErrUnsupportedSortOrder) Error() string {
+	return fmt.Sprintf("unsupported sort order `%s`", e.Sort)
+}
+
+// AggregateParams 表示来自请求参数的聚合查询，如 `?groupBy=user~age&metric=count&metric=avg(user~age)&sort=count!desc&limit=10`。
+type AggregateParams struct {
+	// GroupBy 为分组字段
+	GroupBy []string `name:"groupBy,omitzero" in:"query"`
+	// Metric 为聚合指标，未指定时为 count
+	Metric []Metric `name:"metric,omitzero" in:"query"`
+	// Sort 为排序键，形如 `count!desc`、`user~age!asc`，键为已选的分组字段或指标；未指定时按分组字段升序
+	Sort []string `name:"sort,omitzero" in:"query"`
+	// Limit 为返回的行数上限，0 表示不限
+	Limit int64 `name:"limit,omitzero" in:"query"`
+}
+
+// MetricField 表示可聚合的字段及其可用的聚合函数。
+type MetricField[M sqlpipe.Model] struct {
+	Column modelscoped.Column[M]
+	Funcs  []AggFunc
+}
+
+// AggregateSpec 声明运行时聚合允许的分组字段与指标，未声明的字段返回 ErrUnsupportedQLField，未允许的函数返回 ErrUnsupportedMetric。
+//
+// 不带字段的 count 总是允许。
+type AggregateSpec[M sqlpipe.Model] struct {
+	// GroupBy 为允许分组的字段
+	GroupBy map[string]modelscoped.Column[M]
+	// Metrics 为允许聚合的字段
+	Metrics map[string]MetricField[M]
+}
+
+// Aggregate 按白名单把聚合参数翻译为聚合数据源，src 上已有的 WHERE（如 AsComposedWhere 翻译的过滤规则）先于分组生效。
+//
+// 返回的 AggregateQuery 可交给 ex.ListRows 执行，再以 Rows 把结果列映射回字段名与指标文本。
+func (s *AggregateSpec[M]) Aggregate(src sqlpipe.Source[M], params AggregateParams) (*AggregateQuery[M], error) {
+	q := &AggregateQuery[M]{
+		keys:    map[string]string{},
+		metrics: map[string]bool{},
+	}
+
+	sortable := map[string]modelscoped.Column[M]{}
+
+	groupBy := make([]modelscoped.Column[M], 0, len(params.GroupBy))
+	projects := make([]modelscoped.Column[M], 0, len(params.GroupBy)+len(params.Metric))
+
+	for _, name := range params.GroupBy {
+		col, ok := s.GroupBy[name]
+		if !ok {
+			return nil, &filter.ErrUnsupportedQLField{FieldName: name}
+		}
+		if _, ok := sortable[name]; ok {
+			continue
+		}
+
+		sortable[name] = col
+		q.keys[col.Name()] = name
+
+		groupBy = append(groupBy, col)
+		projects = append(projects, col)
+	}
+
+	metrics := params.Metric
+	if len(metrics) == 0 {
+		metrics = []Metric{{Func: AggCount}}
+	}
+
+	for i, m := range metrics {
+		fn, err := s.aggregateOf(m)
+		if err != nil {
+			return nil, err
+		}
+
+		alias := fmt.Sprintf("f_metric_%d", i)
+
+		col := modelscoped.CastColumn[M](sqlbuilder.Col(alias, sqlbuilder.ColComputedBy(fn)))
+
+		sortable[m.String()] = col
+		q.keys[alias] = m.String()
+		// min/max keep the column value, like text or time
+		q.metrics[alias] = m.Func != AggMin && m.Func != AggMax
+
+		projects = append(projects, col)
+	}
+
+	var aggregated sqlpipe.Source[M]
+	if len(groupBy) > 0 {
+		aggregated = sqlpipe.AggregateGroupBy[M, M](src, modelscoped.AllColumns(groupBy...), projects...)
+	} else {
+		aggregated = sqlpipe.Aggregate[M, M](src, projects...)
+	}
+
+	operators := make([]sqlpipe.SourceOperator[M], 0, len(params.Sort)+1)
+
+	for _, by := range params.Sort {
+		key, order, _ := strings.Cut(by, "!")
+
+		col, ok := sortable[key]
+		if !ok {
+			return nil, &filter.ErrUnsupportedQLField{FieldName: key}
+		}
+
+		switch sort.Order(strings.ToLower(order)) {
+		case "", sort.Asc:
+			operators = append(operators, sqlpipe.AscSort(col.ComputedBy(nil)))
+		case sort.Desc:
+			operators = append(operators, sqlpipe.DescSort(col.ComputedBy(nil)))
+		default:
+			return nil, &ErrUnsupportedSortOrder{Sort: by}
+		}
+	}
+
+	if len(params.Sort) == 0 {
+		for _, col := range groupBy {
+			operators = append(operators, sqlpipe.AscSort(col))
+		}
+	}
+
+	if params.Limit > 0 {
+		operators = append(operators, sqlpipe.Limit[M](params.Limit))
+	}
+
+	if len(operators) > 0 {
+		aggregated = aggregated.Pipe(operators...)
+	}
+
+	q.Source = aggregated
+
+	return q, nil
+}
+
+func (s *AggregateSpec[M]) aggregateOf(m Metric) (sqlfrag.Fragment, error) {
+	if m.Field == "" {
+		if m.Func != AggCount {
+			return nil, &ErrUnsupportedMetric{Metric: m.String()}
+		}
+		return sqlbuilder.Count(), nil
+	}
+
+	f, ok := s.Metrics[m.Field]
+	if !ok {
+		return nil, &filter.ErrUnsupportedQLField{FieldName: m.Field}
+	}
+	if !slices.Contains(f.Funcs, m.Func) {
+		return nil, &ErrUnsupportedMetric{Metric: m.String()}
+	}
+
+	switch m.Func {
+	case AggCount:
+		return sqlbuilder.Count(f.Column), nil
+	case AggCountDistinct:
+		return sqlbuilder.Count(sqlbuilder.Distinct(f.Column)), nil
+	case AggSum:
+		return sqlbuilder.Sum(f.Column), nil
+	case AggAvg:
+		return sqlbuilder.Avg(f.Column), nil
+	case AggMin:
+		return sqlbuilder.Min(f.Column), nil
+	case AggMax:
+		return sqlbuilder.Max(f.Column), nil
+	default:
+		return nil, &ErrUnsupportedMetric{Metric: m.String()}
+	}
+}
+
+// AggregateQuery 表示翻译后的聚合数据源，记录结果列到字段名与指标文本的映射。
+type AggregateQuery[M sqlpipe.Model] struct {
+	sqlpipe.Source[M]
+
+	keys    map[string]string
+	metrics map[string]bool
+}
+
+// AggregateRow 表示一行聚合结果，键为分组字段名与指标文本（如 `avg(user~age)`）。
+type AggregateRow map[string]any
+
+// Rows 把按列名读取的结果行映射为聚合结果，以文本返回的计数与数值指标（如 PostgreSQL 的 SUM、AVG）转为数值；
+// min/max 保留列的原值，文本列的结果不会被当作数值。
+func (q *AggregateQuery[M]) Rows(list []map[string]any) []AggregateRow {
+	rows := make([]AggregateRow, 0, len(list))
+
+	for _, values := range list {
+		row := make(AggregateRow, len(q.keys))
+
+		for col, v := range values {
+			col = strings.ToLower(col)
+
+			key, ok := q.keys[col]
+			if !ok {
+				continue
+			}
+
+			if q.metrics[col] {
+				v = numberOf(v)
+			}
+
+			row[key] = v
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func numberOf(v any) any {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return v
+}
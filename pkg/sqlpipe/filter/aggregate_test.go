@@ -0,0 +1,137 @@
+package filter
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/testdata/model"
+)
+
+var userAggregateSpec = &AggregateSpec[model.User]{
+	GroupBy: map[string]modelscoped.Column[model.User]{
+		"user~age":    model.UserT.Age,
+		"user~gender": model.UserT.Gender,
+	},
+	Metrics: map[string]MetricField[model.User]{
+		"user~age":  {Column: model.UserT.Age, Funcs: []AggFunc{AggSum, AggAvg, AggMax}},
+		"user~name": {Column: model.UserT.Name, Funcs: []AggFunc{AggCountDistinct, AggMax}},
+	},
+}
+
+func TestAggregate(t *testing.T) {
+	collect := func(src sqlpipe.Source[model.User], params AggregateParams) (string, error) {
+		q, err := userAggregateSpec.Aggregate(src, params)
+		if err != nil {
+			return "", err
+		}
+		s, _ := sqlfrag.Collect(context.Background(), q)
+		return s, nil
+	}
+
+	Then(
+		t, "按请求参数分组并计算指标",
+		ExpectMustValue(
+			func() (string, error) {
+				params := AggregateParams{GroupBy: []string{"user~gender"}, Sort: []string{"avg(user~age)!desc"}, Limit: 10}
+				for _, text := range []string{"count", "avg(user~age)", "countdistinct(user~name)"} {
+					m := Metric{}
+					if err := m.UnmarshalText([]byte(text)); err != nil {
+						return "", err
+					}
+					params.Metric = append(params.Metric, m)
+				}
+				return collect(sqlpipe.FromAll[model.User](), params)
+			},
+			Equal(`SELECT f_gender, f_metric_0, f_metric_1, f_metric_2
+FROM (
+	SELECT f_gender, COUNT(1) AS f_metric_0, AVG(f_age) AS f_metric_1, COUNT(DISTINCT(f_name)) AS f_metric_2
+	FROM t_user
+	GROUP BY f_gender
+) AS t_user
+ORDER BY (f_metric_1) DESC
+LIMIT 10`),
+		),
+	)
+
+	Then(
+		t, "过滤规则先于分组生效，未指定排序时按分组字段升序",
+		ExpectMustValue(
+			func() (string, error) {
+				return collect(
+					sqlpipe.FromAll[model.User]().Pipe(sqlpipe.Where(model.UserT.Name, sqlbuilder.Eq("x"))),
+					AggregateParams{GroupBy: []string{"user~age"}, Metric: []Metric{{Func: AggMax, Field: "user~age"}}},
+				)
+			},
+			Equal(`SELECT f_age, f_metric_0
+FROM (
+	SELECT f_age, MAX(f_age) AS f_metric_0
+	FROM (
+		SELECT *
+		FROM t_user
+		WHERE f_name = ?
+	) AS t_user
+	GROUP BY f_age
+) AS t_user
+ORDER BY (f_age) ASC`),
+		),
+	)
+
+	Then(
+		t, "白名单外的字段与函数返回错误",
+		ExpectDo(
+			func() error {
+				_, err := collect(sqlpipe.FromAll[model.User](), AggregateParams{GroupBy: []string{"user~name"}})
+				return err
+			},
+			ErrorMatch(regexp.MustCompile("unsupported ql field `user~name`")),
+		),
+		ExpectDo(
+			func() error {
+				_, err := collect(sqlpipe.FromAll[model.User](), AggregateParams{Metric: []Metric{{Func: AggMin, Field: "user~age"}}})
+				return err
+			},
+			ErrorMatch(regexp.MustCompile("unsupported metric `min\\(user~age\\)`")),
+		),
+		ExpectDo(
+			func() error {
+				return (&Metric{}).UnmarshalText([]byte("median(user~age)"))
+			},
+			ErrorMatch(regexp.MustCompile("unsupported metric `median\\(user~age\\)`")),
+		),
+		ExpectDo(
+			func() error {
+				_, err := collect(sqlpipe.FromAll[model.User](), AggregateParams{Sort: []string{"count!down"}})
+				return err
+			},
+			ErrorMatch(regexp.MustCompile("unsupported sort order `count!down`")),
+		),
+	)
+
+	Then(
+		t, "结果列映射为字段名与指标文本，min/max 保留列的原值",
+		ExpectMustValue(
+			func() ([]AggregateRow, error) {
+				q, err := userAggregateSpec.Aggregate(sqlpipe.FromAll[model.User](), AggregateParams{
+					GroupBy: []string{"user~age"},
+					Metric:  []Metric{{Func: AggCount}, {Func: AggSum, Field: "user~age"}, {Func: AggMax, Field: "user~name"}},
+				})
+				if err != nil {
+					return nil, err
+				}
+				return q.Rows([]map[string]any{
+					{"f_age": int64(18), "f_metric_0": int64(2), "f_metric_1": "36", "f_metric_2": "007"},
+				}), nil
+			},
+			Equal([]AggregateRow{
+				{"user~age": int64(18), "count": int64(2), "sum(user~age)": int64(36), "max(user~name)": "007"},
+			}),
+		),
+	)
+}
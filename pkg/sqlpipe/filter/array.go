@@ -0,0 +1,104 @@
+package filter
+
+import (
+	"context"
+	"iter"
+	"slices"
+
+	"github.com/octohelm/storage/pkg/filter"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+)
+
+// AsArrayWhere 把元素类型的 filter.Filter 转为集合列（如 sqltype/json.Array）的 WHERE 操作符，包含条件按模型所在会话的方言生成。
+func AsArrayWhere[M sqlpipe.Model, E comparable, A ~[]E](col modelscoped.TypedColumn[M, A], f *filter.Filter[E], optFns ...OptionFunc) sqlpipe.SourceOperator[M] {
+	o := &option{}
+	for _, optFn := range append([]OptionFunc{dialectOf[M]()}, optFns...) {
+		optFn(o)
+	}
+
+	return sqlpipe.WhereCond[M](
+		BuildArrayWhere(f, func(op filter.Op, values []E) sqlfrag.Fragment {
+			return sqlfrag.Func(func(ctx context.Context) iter.Seq2[string, []any] {
+				return func(yield func(string, []any) bool) {
+					if cond := ArrayContains(o.dialectOf(ctx), col, op, values); !sqlfrag.IsNil(cond) {
+						for q, args := range cond.Frag(ctx) {
+							if !yield(q, args) {
+								return
+							}
+						}
+					}
+				}
+			})
+		}),
+	)
+}
+
+// BuildArrayWhere 把集合列的过滤规则树构造成 SQL 条件片段。
+//
+// eq/neq 视为包含/不包含单个值，in/notin 视为包含/不包含任一值；
+// intersection 要求同时满足全部子条件；apply 以 OP__CONTAINSALL、OP__CONTAINSANY 及其取反 OP__NEQ、OP__NOTIN 调用。
+func BuildArrayWhere[E comparable](f *filter.Filter[E], apply func(op filter.Op, values []E) sqlfrag.Fragment) sqlfrag.Fragment {
+	if f == nil || f.IsZero() {
+		return nil
+	}
+
+	sub := func() iter.Seq[sqlfrag.Fragment] {
+		return filter.MapFilter(f.Args(), func(f *filter.Filter[E]) (sqlfrag.Fragment, bool) {
+			return BuildArrayWhere[E](f, apply), true
+		})
+	}
+
+	values := slices.Collect(Values(f))
+
+	switch f.Op() {
+	case filter.OP__AND, filter.OP__INTERSECTION:
+		return sqlbuilder.AndSeq(sub())
+	case filter.OP__OR:
+		return sqlbuilder.OrSeq(sub())
+	case filter.OP__EQ, filter.OP__CONTAINSALL:
+		return apply(filter.OP__CONTAINSALL, values)
+	case filter.OP__IN, filter.OP__CONTAINSANY:
+		return apply(filter.OP__CONTAINSANY, values)
+	case filter.OP__NEQ, filter.OP__NOTIN:
+		return apply(f.Op(), values)
+	default:
+		return nil
+	}
+}
+
+// ArrayContains 按方言生成集合列包含全部（OP__CONTAINSALL）或任一（OP__CONTAINSANY）给定值的条件，
+// OP__NEQ、OP__NOTIN 为对应取反，集合列为 NULL 或空数组时取反条件不成立，与 filter.Match 一致。
+//
+// 集合列以 JSON 数组存储，空数组存为空串；d 为 nil 时使用通用写法，以 json_each 展开。
+func ArrayContains[E comparable](d Dialect, col sqlbuilder.Column, op filter.Op, values []E) sqlfrag.Fragment {
+	if len(values) == 0 {
+		return nil
+	}
+
+	if d == nil {
+		d = genericDialect{}
+	}
+
+	distinct := make([]any, 0, len(values))
+	for _, v := range values {
+		if !slices.Contains(distinct, any(v)) {
+			distinct = append(distinct, v)
+		}
+	}
+
+	switch op {
+	case filter.OP__CONTAINSALL:
+		return d.JSONArrayContains(col, distinct, true)
+	case filter.OP__CONTAINSANY:
+		return d.JSONArrayContains(col, distinct, false)
+	case filter.OP__NEQ:
+		return col.Fragment("# <> '' AND NOT (?)", d.JSONArrayContains(col, distinct, true))
+	case filter.OP__NOTIN:
+		return col.Fragment("# <> '' AND NOT (?)", d.JSONArrayContains(col, distinct, false))
+	default:
+		return nil
+	}
+}
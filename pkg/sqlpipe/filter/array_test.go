@@ -0,0 +1,113 @@
+package filter
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	rootfilter "github.com/octohelm/storage/pkg/filter"
+	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	sqltypejson "github.com/octohelm/storage/pkg/sqltype/json"
+)
+
+type post struct {
+	ID    uint64                    `db:"f_id,autoincrement"`
+	Title string                    `db:"f_title,default=''"`
+	Tags  sqltypejson.Array[string] `db:"f_tags,default=''"`
+}
+
+func (post) TableName() string {
+	return "t_post"
+}
+
+var postTitle = modelscoped.CastTypedColumn[post, string](modelscoped.FromModel[post]().F("Title"))
+
+var postTags = modelscoped.CastTypedColumn[post, sqltypejson.Array[string]](modelscoped.FromModel[post]().F("Tags"))
+
+func TestArrayContains(t *testing.T) {
+	cases := map[string]struct {
+		op    rootfilter.Op
+		query string
+		args  []any
+	}{
+		"contains all": {
+			op:    rootfilter.OP__CONTAINSALL,
+			query: "(SELECT COUNT(DISTINCT json_each.value) FROM json_each(f_tags) WHERE json_each.value IN (?,?)) = ?",
+			args:  []any{"a", "b", 2},
+		},
+		"contains any": {
+			op:    rootfilter.OP__CONTAINSANY,
+			query: "EXISTS (SELECT 1 FROM json_each(f_tags) WHERE json_each.value IN (?,?))",
+			args:  []any{"a", "b"},
+		},
+		"not contains any": {
+			op:    rootfilter.OP__NOTIN,
+			query: "f_tags <> '' AND NOT (EXISTS (SELECT 1 FROM json_each(f_tags) WHERE json_each.value IN (?,?)))",
+			args:  []any{"a", "b"},
+		},
+	}
+
+	for name, c := range cases {
+		q, args := sqlfrag.Collect(context.Background(), ArrayContains(nil, postTags, c.op, []string{"a", "b", "a"}))
+
+		Then(
+			t, "未指定方言时 "+name+" 使用 json_each 展开",
+			Expect(q, Equal(c.query)),
+			Expect(args, Equal(c.args)),
+		)
+	}
+}
+
+func TestAsArrayWhere(t *testing.T) {
+	Then(
+		t, "eq/in/neq 按包含语义组合，intersection 要求同时满足",
+		ExpectMustValue(
+			func() (string, error) {
+				f := &rootfilter.Filter[string]{}
+				if err := f.UnmarshalText([]byte(`intersection(containsall("a","b"),in("c","d"),neq("e"))`)); err != nil {
+					return "", err
+				}
+
+				q, _ := sqlfrag.Collect(context.Background(), sqlpipe.FromAll[post]().Pipe(
+					AsArrayWhere(postTags, f),
+				))
+				return q, nil
+			},
+			Equal(`SELECT *
+FROM t_post
+WHERE ((SELECT COUNT(DISTINCT json_each.value) FROM json_each(f_tags) WHERE json_each.value IN (?,?)) = ?) AND (EXISTS (SELECT 1 FROM json_each(f_tags) WHERE json_each.value IN (?,?))) AND (f_tags <> '' AND NOT ((SELECT COUNT(DISTINCT json_each.value) FROM json_each(f_tags) WHERE json_each.value IN (?)) = ?))`),
+		),
+	)
+
+	Then(
+		t, "集合操作符可经指令编码往返",
+		ExpectMustValue(
+			func() (string, error) {
+				raw, err := rootfilter.Intersection[string](
+					rootfilter.ContainsAll("a", "b"),
+					rootfilter.ContainsAny("c"),
+				).MarshalText()
+				return string(raw), err
+			},
+			Equal(`intersection(containsall("a","b"),containsany("c"))`),
+		),
+	)
+
+	Then(
+		t, "标量列上 intersection 与 containsany 不再被忽略",
+		ExpectMustValue(
+			func() (string, error) {
+				q, _ := sqlfrag.Collect(context.Background(), sqlpipe.FromAll[post]().Pipe(
+					AsWhere(postTitle, rootfilter.Intersection[string](rootfilter.ContainsAny("a", "b"), rootfilter.Neq("c"))),
+				))
+				return q, nil
+			},
+			Equal(`SELECT *
+FROM t_post
+WHERE (f_title IN (?,?)) AND (f_title <> ?)`),
+		),
+	)
+}
@@ -0,0 +1,133 @@
+package filter
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/octohelm/storage/pkg/filter"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+)
+
+// Field 表示 filter.Composed 中可按字段名翻译为列条件的字段。
+type Field interface {
+	// Name 返回 where 规则中使用的字段名。
+	Name() string
+
+	buildWhere(rule filter.WhereRule, optFns ...OptionFunc) (sqlfrag.Fragment, error)
+}
+
+// Fields 表示一组可翻译的字段。
+type Fields []Field
+
+// FieldOf 把字段名绑定到类型化列，列可来自被查询模型或与其连接的模型；optFns 仅作用于该字段，如 Unaccent()。
+func FieldOf[M sqlpipe.Model, T comparable](name string, col modelscoped.TypedColumn[M, T], optFns ...OptionFunc) Field {
+	return &field[M, T]{name: name, col: col, optFns: optFns}
+}
+
+type field[M sqlpipe.Model, T comparable] struct {
+	name   string
+	col    modelscoped.TypedColumn[M, T]
+	optFns []OptionFunc
+}
+
+func (f *field[M, T]) Name() string {
+	return f.name
+}
+
+func (f *field[M, T]) buildWhere(rule filter.WhereRule, optFns ...OptionFunc) (sqlfrag.Fragment, error) {
+	// field options take precedence over options of the whole composed where
+	optFns = append(append([]OptionFunc{dialectOf[M]()}, optFns...), f.optFns...)
+
+	wheres := make([]sqlfrag.Fragment, 0)
+
+	for arg := range rule.Args() {
+		var ff *filter.Filter[T]
+
+		switch x := arg.(type) {
+		case *filter.Filter[T]:
+			ff = x
+		case filter.Filter[T]:
+			ff = &x
+		default:
+			return nil, fmt.Errorf("filter of field `%s` should be %T, but got %T", f.name, ff, arg)
+		}
+
+		wheres = append(wheres, BuildWhere(ff, func(op filter.Op, seq iter.Seq[T], create func(seq iter.Seq[T]) sqlbuilder.ColumnValuer[T]) sqlfrag.Fragment {
+			return f.col.V(create(seq))
+		}, optFns...))
+	}
+
+	return sqlbuilder.And(wheres...), nil
+}
+
+// Registry 表示字段名到可翻译字段的映射。
+type Registry map[string]Field
+
+// NewRegistry 合并多组字段创建注册表，同名字段以后注册的为准。
+func NewRegistry(fieldSets ...Fields) Registry {
+	r := Registry{}
+	for _, fields := range fieldSets {
+		for _, f := range fields {
+			r[f.Name()] = f
+		}
+	}
+	return r
+}
+
+// BuildComposedWhere 把 filter.Composed 翻译为 SQL 条件片段，保留跨字段与跨模型的 OR 分组；optFns 作用于全部字段。
+func BuildComposedWhere(r Registry, c *filter.Composed, optFns ...OptionFunc) (sqlfrag.Fragment, error) {
+	if c == nil || c.IsZero() {
+		return nil, nil
+	}
+
+	return buildRules(r, filter.OP__OR, c.Args(), optFns)
+}
+
+// AsComposedWhere 把 filter.Composed 翻译为单个 sqlpipe 的 WHERE 操作符；optFns 作用于全部字段，如 Unaccent()。
+func AsComposedWhere[M sqlpipe.Model](r Registry, c *filter.Composed, optFns ...OptionFunc) (sqlpipe.SourceOperator[M], error) {
+	cond, err := BuildComposedWhere(r, c, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	return sqlpipe.WhereCond[M](cond), nil
+}
+
+func buildRules(r Registry, op filter.Op, args iter.Seq[filter.Arg], optFns []OptionFunc) (sqlfrag.Fragment, error) {
+	wheres := make([]sqlfrag.Fragment, 0)
+
+	for arg := range args {
+		w, err := buildRule(r, arg, optFns)
+		if err != nil {
+			return nil, err
+		}
+		wheres = append(wheres, w)
+	}
+
+	if op == filter.OP__AND {
+		return sqlbuilder.And(wheres...), nil
+	}
+	return sqlbuilder.Or(wheres...), nil
+}
+
+func buildRule(r Registry, arg filter.Arg, optFns []OptionFunc) (sqlfrag.Fragment, error) {
+	switch x := arg.(type) {
+	case filter.WhereRule:
+		f, ok := r[x.Name()]
+		if !ok {
+			return nil, &filter.ErrUnsupportedQLField{FieldName: x.Name()}
+		}
+		return f.buildWhere(x, optFns...)
+	case filter.Rule:
+		switch op := x.Op(); op {
+		case filter.OP__AND, filter.OP__OR:
+			return buildRules(r, op, x.Args(), optFns)
+		default:
+			return nil, &filter.ErrInvalidFilterOp{Op: op.String()}
+		}
+	default:
+		return nil, &filter.ErrInvalidFilter{Filter: fmt.Sprintf("%T", arg)}
+	}
+}
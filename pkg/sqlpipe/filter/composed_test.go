@@ -0,0 +1,156 @@
+package filter_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/storage/pkg/filter"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	sqlpipefilter "github.com/octohelm/storage/pkg/sqlpipe/filter"
+	"github.com/octohelm/storage/testdata/model"
+	modelfilter "github.com/octohelm/storage/testdata/model/filter"
+)
+
+type OrgUser struct {
+	model.OrgUser
+
+	User model.User
+	Org  model.Org
+}
+
+type unaccentDialect struct {
+	sqlpipefilter.Dialect
+}
+
+func (unaccentDialect) ILike(col sqlbuilder.Column, pattern string, not bool, unaccent bool) sqlfrag.Fragment {
+	op := "ILIKE"
+	if not {
+		op = "NOT ILIKE"
+	}
+	if unaccent {
+		return col.Fragment("unaccent(#) "+op+" unaccent(?)", pattern)
+	}
+	return col.Fragment("# "+op+" ?", pattern)
+}
+
+func TestAsComposedWhere(t *testing.T) {
+	fields := sqlpipefilter.NewRegistry(
+		modelfilter.UserFields,
+		modelfilter.OrgFields,
+	)
+
+	c := filter.Compose(
+		modelfilter.UserByName{},
+		modelfilter.UserByAge{},
+		modelfilter.OrgByName{},
+	)
+
+	Then(
+		t, "跨模型的 OR 分组翻译为单个 WHERE",
+		ExpectDo(func() error {
+			return c.UnmarshalText([]byte(`or(where("user~name",prefix("a")),where("user~age",and(gte(18),lt(30))),where("org~name",in("x","y")))`))
+		}),
+		ExpectMustValue(
+			func() (string, error) {
+				where, err := sqlpipefilter.AsComposedWhere[OrgUser](fields, c)
+				if err != nil {
+					return "", err
+				}
+
+				src := sqlpipe.FromAll[OrgUser]().Pipe(
+					sqlpipe.JoinOnAs[OrgUser](model.OrgUserT.UserID, model.UserT.ID),
+					sqlpipe.JoinOnAs[OrgUser](model.OrgUserT.OrgID, model.OrgT.ID),
+					where,
+				)
+
+				q, _ := sqlfrag.Collect(context.Background(), src)
+				return q, nil
+			},
+			Equal(`SELECT *
+FROM t_org_user
+JOIN t_user ON (t_org_user.f_user_id = t_user.f_id) AND (t_user.f_deleted_at = ?)
+JOIN t_org ON (t_org_user.f_org_id = t_org.f_id) AND (t_org.f_deleted_at = ?)
+WHERE (t_user.f_name LIKE ?) OR ((t_user.f_age >= ?) AND (t_user.f_age < ?)) OR (t_org.f_name IN (?,?))`),
+		),
+	)
+
+	Then(
+		t, "与其他过滤条件以 AND 组合",
+		ExpectMustValue(
+			func() ([]any, error) {
+				where, err := sqlpipefilter.AsComposedWhere[model.User](fields, filter.Compose(modelfilter.UserByName{
+					Name: filter.Eq("alice"),
+				}))
+				if err != nil {
+					return nil, err
+				}
+
+				_, args := sqlfrag.Collect(context.Background(), sqlpipe.From[model.User]().Pipe(where))
+				return args, nil
+			},
+			Equal([]any{"alice", int64(0)}),
+		),
+	)
+
+	Then(
+		t, "组合与字段上的选项传给每个字段的翻译",
+		ExpectMustValue(
+			func() (string, error) {
+				dialect := sqlpipefilter.DialectFrom(func(ctx context.Context) sqlpipefilter.Dialect {
+					return unaccentDialect{}
+				})
+
+				where, err := sqlpipefilter.AsComposedWhere[model.User](
+					sqlpipefilter.NewRegistry(sqlpipefilter.Fields{
+						sqlpipefilter.FieldOf("user~name", model.UserT.Name, sqlpipefilter.Unaccent()),
+						sqlpipefilter.FieldOf("user~nickname", model.UserT.Nickname),
+					}),
+					filter.Compose(
+						modelfilter.UserByName{Name: filter.IContains("é")},
+						modelfilter.UserByNickname{Nickname: filter.NotIContains("é")},
+					),
+					dialect,
+				)
+				if err != nil {
+					return "", err
+				}
+				q, _ := sqlfrag.Collect(context.Background(), sqlpipe.FromAll[model.User]().Pipe(where))
+				return q, nil
+			},
+			Equal("SELECT *\nFROM t_user\nWHERE (unaccent(f_name) ILIKE unaccent(?)) OR (f_nickname NOT ILIKE ?)"),
+		),
+	)
+
+	Then(
+		t, "未注册的字段返回错误",
+		ExpectDo(
+			func() error {
+				_, err := sqlpipefilter.AsComposedWhere[model.User](sqlpipefilter.NewRegistry(modelfilter.OrgFields), filter.Compose(modelfilter.UserByName{
+					Name: filter.Eq("alice"),
+				}))
+				return err
+			},
+			ErrorMatch(regexp.MustCompile("unsupported ql field `user~name`")),
+		),
+	)
+
+	Then(
+		t, "空的组合条件不生成 WHERE",
+		ExpectMustValue(
+			func() (string, error) {
+				where, err := sqlpipefilter.AsComposedWhere[model.User](fields, filter.Compose(modelfilter.UserByName{}))
+				if err != nil {
+					return "", err
+				}
+				q, _ := sqlfrag.Collect(context.Background(), sqlpipe.FromAll[model.User]().Pipe(where))
+				return q, nil
+			},
+			Equal("SELECT *\nFROM t_user"),
+		),
+	)
+}
@@ -1,50 +1,141 @@
 package filter
 
 import (
+	"context"
 	"fmt"
 	"iter"
+	"reflect"
 
+	"github.com/octohelm/storage/internal/sql/adapter"
+	"github.com/octohelm/storage/internal/xiter"
 	"github.com/octohelm/storage/pkg/filter"
+	"github.com/octohelm/storage/pkg/session"
 	"github.com/octohelm/storage/pkg/sqlbuilder"
 	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
 	"github.com/octohelm/storage/pkg/sqlfrag"
 	"github.com/octohelm/storage/pkg/sqlpipe"
 )
 
-// AsWhere 把 filter.Filter 转为 sqlpipe 的 WHERE 操作符。
-func AsWhere[M sqlpipe.Model, T comparable](col modelscoped.TypedColumn[M, T], f *filter.Filter[T]) sqlpipe.SourceOperator[M] {
+// AsWhere 把 filter.Filter 转为 sqlpipe 的 WHERE 操作符，方言相关的匹配按模型所在会话的方言生成。
+func AsWhere[M sqlpipe.Model, T comparable](col modelscoped.TypedColumn[M, T], f *filter.Filter[T], optFns ...OptionFunc) sqlpipe.SourceOperator[M] {
+	optFns = append([]OptionFunc{dialectOf[M]()}, optFns...)
+
 	return sqlpipe.NewWhere(sqlpipe.FilterOpAnd, col, func(v sqlbuilder.Column) sqlfrag.Fragment {
 		return BuildWhere(f, func(op filter.Op, seq iter.Seq[T], create func(seq iter.Seq[T]) sqlbuilder.ColumnValuer[T]) sqlfrag.Fragment {
 			return col.V(create(seq))
-		})
+		}, optFns...)
+	})
+}
+
+// OptionFunc 表示构造过滤条件的选项。
+type OptionFunc func(o *option)
+
+// Dialect 复用底层方言的过滤条件渲染接口。
+type Dialect = adapter.FilterDialect
+
+// DialectFrom 指定从 context 解析方言，用于生成方言相关的文本与集合匹配；未指定或解析为 nil 时生成通用写法。
+func DialectFrom(fn func(ctx context.Context) Dialect) OptionFunc {
+	return func(o *option) {
+		o.dialect = fn
+	}
+}
+
+// Unaccent 让不区分大小写的文本匹配同时忽略重音，仅 PostgreSQL 生效且需启用 unaccent 扩展。
+func Unaccent() OptionFunc {
+	return func(o *option) {
+		o.unaccent = true
+	}
+}
+
+func dialectOf[M sqlpipe.Model]() OptionFunc {
+	return DialectFrom(func(ctx context.Context) Dialect {
+		if s := session.For(ctx, new(M)); s != nil {
+			if d, ok := s.Adapter().Dialect().(Dialect); ok {
+				return d
+			}
+		}
+		return nil
 	})
 }
 
+type option struct {
+	dialect  func(ctx context.Context) Dialect
+	unaccent bool
+}
+
+func (o *option) dialectOf(ctx context.Context) Dialect {
+	if o.dialect != nil {
+		if d := o.dialect(ctx); d != nil {
+			return d
+		}
+	}
+	return genericDialect{}
+}
+
+// genericDialect 以通用写法渲染过滤条件，用于未实现 Dialect 的方言或未绑定会话的场景。
+type genericDialect struct{}
+
+func (genericDialect) ILike(col sqlbuilder.Column, pattern string, not bool, unaccent bool) sqlfrag.Fragment {
+	if not {
+		return col.Fragment("lower(#) NOT LIKE lower(?)", pattern)
+	}
+	return col.Fragment("lower(#) LIKE lower(?)", pattern)
+}
+
+func (genericDialect) JSONArrayContains(col sqlbuilder.Column, values []any, all bool) sqlfrag.Fragment {
+	if all {
+		return col.Fragment("(SELECT COUNT(DISTINCT json_each.value) FROM json_each(#) WHERE json_each.value IN (?)) = ?", values, len(values))
+	}
+	return col.Fragment("EXISTS (SELECT 1 FROM json_each(#) WHERE json_each.value IN (?))", values)
+}
+
 // BuildWhere 把过滤规则树构造成 SQL 条件片段。
-func BuildWhere[T comparable](f *filter.Filter[T], apply func(op filter.Op, seq iter.Seq[T], create func(seq iter.Seq[T]) sqlbuilder.ColumnValuer[T]) sqlfrag.Fragment) sqlfrag.Fragment {
+func BuildWhere[T comparable](f *filter.Filter[T], apply func(op filter.Op, seq iter.Seq[T], create func(seq iter.Seq[T]) sqlbuilder.ColumnValuer[T]) sqlfrag.Fragment, optFns ...OptionFunc) sqlfrag.Fragment {
 	if f == nil || f.IsZero() {
 		return nil
 	}
 
+	o := &option{}
+	for _, optFn := range optFns {
+		optFn(o)
+	}
+
 	op := f.Op()
 	switch op {
-	case filter.OP__AND:
+	case filter.OP__AND, filter.OP__INTERSECTION:
 		rules := filter.MapFilter(f.Args(), func(f *filter.Filter[T]) (sqlfrag.Fragment, bool) {
-			return BuildWhere[T](f, apply), true
+			return BuildWhere[T](f, apply, optFns...), true
 		})
 		return sqlbuilder.AndSeq(rules)
 	case filter.OP__OR:
 		rules := filter.MapFilter(f.Args(), func(f *filter.Filter[T]) (sqlfrag.Fragment, bool) {
-			return BuildWhere[T](f, apply), true
+			return BuildWhere[T](f, apply, optFns...), true
 		})
 		return sqlbuilder.OrSeq(rules)
+	case filter.OP__BETWEEN, filter.OP__SINCE, filter.OP__BEFORE, filter.OP__DAY:
+		return apply(op, Values(f), func(seq iter.Seq[T]) sqlbuilder.ColumnValuer[T] {
+			return func(col sqlbuilder.Column) sqlfrag.Fragment {
+				return TimeRange(col, f)
+			}
+		})
 	default:
 		return apply(op, Values(f), func(seq iter.Seq[T]) sqlbuilder.ColumnValuer[T] {
 			switch op {
-			case filter.OP__IN:
+			case filter.OP__IN, filter.OP__CONTAINSANY:
 				return sqlbuilder.InSeq(seq)
+			case filter.OP__CONTAINSALL:
+				// a single value could only contain all when every value equals to it
+				return func(col sqlbuilder.Column) sqlfrag.Fragment {
+					return sqlbuilder.AndSeq(xiter.Map(seq, func(v T) sqlfrag.Fragment {
+						return sqlbuilder.Eq(v)(col)
+					}))
+				}
 			case filter.OP__NOTIN:
 				return sqlbuilder.NotInSeq(seq)
+			case filter.OP__ISNULL:
+				return sqlbuilder.IsNull[T]()
+			case filter.OP__NOTNULL:
+				return sqlbuilder.IsNotNull[T]()
 			default:
 				for v := range seq {
 					switch op {
@@ -60,21 +151,10 @@ func BuildWhere[T comparable](f *filter.Filter[T], apply func(op filter.Op, seq
 						return sqlbuilder.Lt(v)
 					case filter.OP__LTE:
 						return sqlbuilder.Lte(v)
-					case filter.OP__PREFIX:
-						return func(col sqlbuilder.Column) sqlfrag.Fragment {
-							return col.Fragment("# LIKE ?", fmt.Sprintf("%v", v)+"%")
-						}
-					case filter.OP__SUFFIX:
+					case filter.OP__PREFIX, filter.OP__SUFFIX, filter.OP__CONTAINS, filter.OP__NOTCONTAINS,
+						filter.OP__IPREFIX, filter.OP__ISUFFIX, filter.OP__ICONTAINS, filter.OP__NOTICONTAINS:
 						return func(col sqlbuilder.Column) sqlfrag.Fragment {
-							return col.Fragment("# LIKE ?", "%"+fmt.Sprintf("%v", v))
-						}
-					case filter.OP__CONTAINS:
-						return func(col sqlbuilder.Column) sqlfrag.Fragment {
-							return col.Fragment("# LIKE ?", "%"+fmt.Sprintf("%v", v)+"%")
-						}
-					case filter.OP__NOTCONTAINS:
-						return func(col sqlbuilder.Column) sqlfrag.Fragment {
-							return col.Fragment("# NOT LIKE ?", "%"+fmt.Sprintf("%v", v)+"%")
+							return o.textMatch(col, op, textOf(v))
 						}
 					default:
 
@@ -87,6 +167,54 @@ func BuildWhere[T comparable](f *filter.Filter[T], apply func(op filter.Op, seq
 	}
 }
 
+// textMatch 生成文本匹配条件，用户输入中的通配符会被转义。
+//
+// 不区分大小写的匹配由方言的 ILike 生成，如 PostgreSQL 使用 ILIKE，通用写法使用 lower() 比较。
+func (o *option) textMatch(col sqlbuilder.Column, op filter.Op, v string) sqlfrag.Fragment {
+	prefix, suffix := "%", "%"
+	switch op {
+	case filter.OP__PREFIX, filter.OP__IPREFIX:
+		prefix = ""
+	case filter.OP__SUFFIX, filter.OP__ISUFFIX:
+		suffix = ""
+	default:
+	}
+
+	switch op {
+	case filter.OP__NOTCONTAINS:
+		return sqlbuilder.LikePattern(col, "NOT LIKE", prefix, v, suffix)
+	case filter.OP__PREFIX, filter.OP__SUFFIX, filter.OP__CONTAINS:
+		return sqlbuilder.LikePattern(col, "LIKE", prefix, v, suffix)
+	default:
+	}
+
+	return sqlfrag.Func(func(ctx context.Context) iter.Seq2[string, []any] {
+		escaped := sqlbuilder.EscapeLike(v)
+
+		cond := o.dialectOf(ctx).ILike(col, prefix+escaped+suffix, op == filter.OP__NOTICONTAINS, o.unaccent)
+		if escaped != v {
+			cond = sqlfrag.Pair("? ESCAPE '\\'", cond)
+		}
+
+		return cond.Frag(ctx)
+	})
+}
+
+// textOf 返回值的文本形式，指针类型取其指向的值。
+func textOf(v any) string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return ""
+	}
+	return fmt.Sprintf("%v", rv.Interface())
+}
+
 // SubFilters 返回复合过滤器中的子过滤器。
 func SubFilters[T comparable](f *filter.Filter[T]) iter.Seq[*filter.Filter[T]] {
 	return func(yield func(*filter.Filter[T]) bool) {
@@ -10,8 +10,10 @@ import (
 
 	rootfilter "github.com/octohelm/storage/pkg/filter"
 	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
 	"github.com/octohelm/storage/pkg/sqlfrag"
 	"github.com/octohelm/storage/pkg/sqlpipe"
+	nullable "github.com/octohelm/storage/pkg/sqltype/nullable"
 	"github.com/octohelm/storage/testdata/model"
 )
 
@@ -69,3 +71,143 @@ func TestAsWhereAndHelpers(t *testing.T) {
 		Expect(BuildWhere[string](nil, nil), Equal(sqlfrag.Fragment(nil))),
 	)
 }
+
+type ilikeDialect struct {
+	Dialect
+}
+
+func (ilikeDialect) ILike(col sqlbuilder.Column, pattern string, not bool, unaccent bool) sqlfrag.Fragment {
+	if not {
+		return col.Fragment("# NOT ILIKE ?", pattern)
+	}
+	return col.Fragment("# ILIKE ?", pattern)
+}
+
+func TestBuildWhereTextMatch(t *testing.T) {
+	cases := map[string]struct {
+		dialect Dialect
+		filter  *rootfilter.Filter[string]
+		optFns  []OptionFunc
+		query   string
+		args    []any
+	}{
+		"contains 转义通配符": {
+			filter: rootfilter.Contains("50%_off"),
+			query:  `f_name LIKE ? ESCAPE '\'`,
+			args:   []any{`%50\%\_off%`},
+		},
+		"icontains 使用方言的 ILike": {
+			dialect: ilikeDialect{},
+			filter:  rootfilter.IContains("Ali"),
+			query:   "f_name ILIKE ?",
+			args:    []any{"%Ali%"},
+		},
+		"noticontains 在方言写法后追加 ESCAPE": {
+			dialect: ilikeDialect{},
+			filter:  mustParse(`noticontains("é_")`),
+			query:   `f_name NOT ILIKE ? ESCAPE '\'`,
+			args:    []any{`%é\_%`},
+		},
+		"通用写法 iprefix 使用 lower() 并忽略 unaccent": {
+			filter: rootfilter.IPrefix("Ali"),
+			optFns: []OptionFunc{Unaccent()},
+			query:  "lower(f_name) LIKE lower(?)",
+			args:   []any{"Ali%"},
+		},
+		"方言解析为 nil 时 isuffix 使用 lower()": {
+			optFns: []OptionFunc{DialectFrom(func(ctx context.Context) Dialect {
+				return nil
+			})},
+			filter: rootfilter.ISuffix("ce"),
+			query:  "lower(f_name) LIKE lower(?)",
+			args:   []any{"%ce"},
+		},
+	}
+
+	for name, c := range cases {
+		optFns := c.optFns
+		if c.dialect != nil {
+			optFns = append([]OptionFunc{DialectFrom(func(ctx context.Context) Dialect {
+				return c.dialect
+			})}, optFns...)
+		}
+
+		frag := BuildWhere(c.filter, func(op rootfilter.Op, seq iter.Seq[string], create func(iter.Seq[string]) sqlbuilder.ColumnValuer[string]) sqlfrag.Fragment {
+			return model.UserT.Name.V(create(seq))
+		}, optFns...)
+
+		q, args := sqlfrag.Collect(context.Background(), frag)
+
+		Then(
+			t, name,
+			Expect(q, Equal(c.query)),
+			Expect(args, Equal(c.args)),
+		)
+	}
+}
+
+type profile struct {
+	ID       uint64        `db:"f_id,autoincrement"`
+	Bio      nullable.Text `db:"f_bio,null"`
+	Nickname *string       `db:"f_nickname,null"`
+}
+
+func (profile) TableName() string {
+	return "t_profile"
+}
+
+var profileBio = modelscoped.CastTypedColumn[profile, nullable.Text](modelscoped.FromModel[profile]().F("Bio"))
+
+var profileNickname = modelscoped.CastTypedColumn[profile, *string](modelscoped.FromModel[profile]().F("Nickname"))
+
+func TestAsWhereNull(t *testing.T) {
+	Then(
+		t, "isnull 与 eq 组合表达为空或 NULL",
+		ExpectMustValue(
+			func() (string, error) {
+				f := &rootfilter.Filter[nullable.Text]{}
+				if err := f.UnmarshalText([]byte(`or(isnull(),eq(""))`)); err != nil {
+					return "", err
+				}
+				q, _ := sqlfrag.Collect(context.Background(), sqlpipe.FromAll[profile]().Pipe(
+					AsWhere(profileBio, f),
+				))
+				return q, nil
+			},
+			Equal(`SELECT *
+FROM t_profile
+WHERE (f_bio IS NULL) OR (f_bio = ?)`),
+		),
+	)
+
+	Then(
+		t, "指针字段支持 notnull 与文本匹配",
+		ExpectMustValue(
+			func() ([]any, error) {
+				f := &rootfilter.Filter[*string]{}
+				if err := f.UnmarshalText([]byte(`and(notnull(),prefix("ali"))`)); err != nil {
+					return nil, err
+				}
+				q, args := sqlfrag.Collect(context.Background(), sqlpipe.FromAll[profile]().Pipe(
+					AsWhere(profileNickname, f),
+				))
+				return append([]any{q}, args...), nil
+			},
+			Equal([]any{"SELECT *\nFROM t_profile\nWHERE (f_nickname IS NOT NULL) AND (f_nickname LIKE ?)", "ali%"}),
+		),
+	)
+
+	Then(
+		t, "空值操作符无参数也不视为空规则",
+		Expect(rootfilter.IsNull[string]().IsZero(), Equal(false)),
+		Expect(rootfilter.NotNull[string]().String(), Equal("notnull()")),
+	)
+}
+
+func mustParse(text string) *rootfilter.Filter[string] {
+	f := &rootfilter.Filter[string]{}
+	if err := f.UnmarshalText([]byte(text)); err != nil {
+		panic(err)
+	}
+	return f
+}
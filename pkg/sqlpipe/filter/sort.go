@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"github.com/octohelm/enumeration/pkg/enumeration"
+
+	"github.com/octohelm/storage/pkg/sort"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+)
+
+// SortField 表示可用于 sort.By 的排序字段枚举。
+type SortField interface {
+	comparable
+	enumeration.CanEnumValues
+}
+
+// SortFields 表示排序字段到模型列的映射。
+type SortFields[M sqlpipe.Model, E SortField] map[E]modelscoped.Column[M]
+
+// AsSort 把多个 sort.By 依次转为排序操作符，未映射的字段被忽略；
+// 最后追加未参与排序的主键列升序作为兜底，保证分页结果稳定。
+func AsSort[M sqlpipe.Model, E SortField](fields SortFields[M, E], byList []sort.By[E]) sqlpipe.SourceOperator[M] {
+	return sqlpipe.SourceOperatorFunc[M](sqlpipe.OperatorSort, func(src sqlpipe.Source[M]) sqlpipe.Source[M] {
+		operators := make([]sqlpipe.SourceOperator[M], 0, len(byList)+1)
+		sorted := map[string]bool{}
+
+		for _, by := range byList {
+			col, ok := fields[by.Field]
+			if !ok {
+				continue
+			}
+
+			sorted[col.Name()] = true
+
+			if by.Order == sort.Desc {
+				operators = append(operators, sqlpipe.DescSort(col, nullsOf(by.Nulls)...))
+			} else {
+				operators = append(operators, sqlpipe.AscSort(col, nullsOf(by.Nulls)...))
+			}
+		}
+
+		if pk := modelscoped.FromModel[M]().K("primary"); pk != nil {
+			for col := range pk.Cols() {
+				if !sorted[col.Name()] {
+					operators = append(operators, sqlpipe.AscSort(modelscoped.CastColumn[M](col)))
+				}
+			}
+		}
+
+		return src.Pipe(operators...)
+	})
+}
+
+func nullsOf(nulls sort.Nulls) []sqlfrag.Fragment {
+	switch nulls {
+	case sort.NullsFirst:
+		return []sqlfrag.Fragment{sqlbuilder.NullsFirst()}
+	case sort.NullsLast:
+		return []sqlfrag.Fragment{sqlbuilder.NullsLast()}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,96 @@
+package filter
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/storage/pkg/sort"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/testdata/model"
+)
+
+type userSortField string
+
+const (
+	userSortFieldID   userSortField = "user~id"
+	userSortFieldName userSortField = "user~name"
+	userSortFieldAge  userSortField = "user~age"
+)
+
+func (userSortField) EnumValues() []any {
+	return []any{userSortFieldID, userSortFieldName, userSortFieldAge}
+}
+
+var userSortFields = SortFields[model.User, userSortField]{
+	userSortFieldID:   model.UserT.ID,
+	userSortFieldName: model.UserT.Name,
+	userSortFieldAge:  model.UserT.Age,
+}
+
+func TestAsSort(t *testing.T) {
+	sortBy := func(texts ...string) ([]sort.By[userSortField], error) {
+		list := make([]sort.By[userSortField], len(texts))
+		for i, text := range texts {
+			if err := list[i].UnmarshalText([]byte(text)); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	}
+
+	Then(
+		t, "多个排序键按顺序生效，并以主键兜底",
+		ExpectMustValue(
+			func() (string, error) {
+				list, err := sortBy("user~age!desc!nullslast", "user~name!asc")
+				if err != nil {
+					return "", err
+				}
+				q, _ := sqlfrag.Collect(context.Background(), sqlpipe.FromAll[model.User]().Pipe(
+					AsSort(userSortFields, list),
+				))
+				return q, nil
+			},
+			Equal(`SELECT *
+FROM t_user
+ORDER BY (f_age) DESC NULLS LAST,(f_name) ASC,(f_id) ASC`),
+		),
+	)
+
+	Then(
+		t, "已按主键排序时不重复追加",
+		ExpectMustValue(
+			func() (string, error) {
+				list, err := sortBy("user~id!desc!nullsfirst")
+				if err != nil {
+					return "", err
+				}
+				q, _ := sqlfrag.Collect(context.Background(), sqlpipe.FromAll[model.User]().Pipe(
+					AsSort(userSortFields, list),
+				))
+				return q, nil
+			},
+			Equal(`SELECT *
+FROM t_user
+ORDER BY (f_id) DESC NULLS FIRST`),
+		),
+	)
+
+	Then(
+		t, "未指定排序时仅按主键排序",
+		ExpectMustValue(
+			func() (string, error) {
+				q, _ := sqlfrag.Collect(context.Background(), sqlpipe.FromAll[model.User]().Pipe(
+					AsSort(userSortFields, nil),
+				))
+				return q, nil
+			},
+			Equal(`SELECT *
+FROM t_user
+ORDER BY (f_id) ASC`),
+		),
+	)
+}
@@ -0,0 +1,59 @@
+package filter
+
+import (
+	"context"
+	"iter"
+	"reflect"
+	"time"
+
+	"github.com/octohelm/storage/pkg/filter"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+)
+
+var typeTime = reflect.TypeFor[time.Time]()
+
+// TimeRange 生成时间操作符（between/since/before/day）的区间条件，now 在渲染时取自 context 中的时钟。
+//
+// 区间统一展开为 `>=` 与 `<` 比较而不截断列值，参数经列类型 T 的 driver.Valuer 编码，
+// 因此秒级整数（sqltype/time.Timestamp）与原生时间列在各驱动下都能命中索引；T 及其指针类型不能由 time.Time 转换或参数不全时不生成条件。
+func TimeRange[T comparable](col sqlbuilder.Column, f *filter.Filter[T]) sqlfrag.Fragment {
+	typ := reflect.TypeFor[T]()
+	elem := typ
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if !typeTime.ConvertibleTo(elem) {
+		return nil
+	}
+	if _, _, ok := filter.TimeRange(f, time.Time{}); !ok {
+		return nil
+	}
+
+	timeOf := func(t time.Time) T {
+		v := reflect.ValueOf(t).Convert(elem)
+		if typ.Kind() == reflect.Pointer {
+			ptr := reflect.New(elem)
+			ptr.Elem().Set(v)
+			v = ptr
+		}
+		return v.Interface().(T)
+	}
+
+	return sqlfrag.Func(func(ctx context.Context) iter.Seq2[string, []any] {
+		from, to, _ := filter.TimeRange(f, filter.ClockFromContext(ctx)())
+
+		conds := make([]sqlfrag.Fragment, 0, 2)
+		if !from.IsZero() {
+			conds = append(conds, sqlbuilder.Gte(timeOf(from))(col))
+		}
+		if !to.IsZero() {
+			conds = append(conds, sqlbuilder.Lt(timeOf(to))(col))
+		}
+
+		if len(conds) == 1 {
+			return conds[0].Frag(ctx)
+		}
+		return sqlbuilder.And(conds...).Frag(ctx)
+	})
+}
@@ -0,0 +1,84 @@
+package filter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	rootfilter "github.com/octohelm/storage/pkg/filter"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/testdata/model"
+)
+
+func TestTimeRange(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 30, 0, 0, model.CST)
+	ctx := rootfilter.InjectClock(context.Background(), func() time.Time {
+		return now
+	})
+
+	at := func(t time.Time) model.Datetime {
+		return model.Datetime(t)
+	}
+
+	cases := map[string]struct {
+		filter string
+		query  string
+		args   []any
+	}{
+		"since 相对时间": {
+			filter: `since(-7d)`,
+			query:  "SELECT *\nFROM t_user\nWHERE f_created_at >= ?",
+			args:   []any{at(now.AddDate(0, 0, -7))},
+		},
+		"before now": {
+			filter: `before(now)`,
+			query:  "SELECT *\nFROM t_user\nWHERE f_created_at < ?",
+			args:   []any{at(now)},
+		},
+		"day 展开为当天区间": {
+			filter: `day(2026-10-18)`,
+			query:  "SELECT *\nFROM t_user\nWHERE (f_created_at >= ?) AND (f_created_at < ?)",
+			args: []any{
+				at(time.Date(2026, 10, 18, 0, 0, 0, 0, model.CST)),
+				at(time.Date(2026, 10, 19, 0, 0, 0, 0, model.CST)),
+			},
+		},
+		"between 的日期上界包含当天": {
+			filter: `between(2026-10-01,2026-10-18)`,
+			query:  "SELECT *\nFROM t_user\nWHERE (f_created_at >= ?) AND (f_created_at < ?)",
+			args: []any{
+				at(time.Date(2026, 10, 1, 0, 0, 0, 0, model.CST)),
+				at(time.Date(2026, 10, 19, 0, 0, 0, 0, model.CST)),
+			},
+		},
+	}
+
+	for name, c := range cases {
+		f := &rootfilter.Filter[model.Datetime]{}
+
+		Then(
+			t, name,
+			ExpectDo(func() error {
+				return f.UnmarshalText([]byte(c.filter))
+			}),
+		)
+
+		q, args := sqlfrag.Collect(ctx, sqlpipe.FromAll[model.User]().Pipe(
+			AsWhere(model.UserT.CreatedAt, f),
+		))
+
+		Then(
+			t, name,
+			Expect(q, Equal(c.query)),
+			Expect(args, Equal(c.args)),
+		)
+	}
+
+	Then(
+		t, "无法由 time.Time 转换的列不生成条件",
+		Expect(TimeRange(model.UserT.Name, rootfilter.Since[string](rootfilter.Now())), Equal(sqlfrag.Fragment(nil))),
+	)
+}
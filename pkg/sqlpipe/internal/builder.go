@@ -59,6 +59,8 @@ type Builder[M sqlbuilder.Model] struct {
 	Pager sqlbuilder.Addition
 
 	Additions []sqlbuilder.Addition
+
+	Merge *Merge
 }
 
 func (s Builder[M]) WithFlag(f flags.Flag) *Builder[M] {
@@ -117,6 +119,9 @@ func (s *Builder[M]) BuildStmt(ctx context.Context) sqlfrag.Fragment {
 		if x.ForUpdate {
 			return s.buildUpdate(ctx, x)
 		}
+		if s.Merge != nil {
+			return s.buildMerge(ctx, x)
+		}
 		return s.buildInsert(ctx, x)
 	default:
 		return s.buildSelect(ctx)
@@ -255,7 +260,7 @@ func (s *Builder[M]) buildInsert(ctx context.Context, m *Mutation[M]) sqlfrag.Fr
 
 			for sfv := range structs.AllFieldValue(ctx, value) {
 				if includes.F(sfv.Field.FieldName) != nil || !reflect.IsEmptyValue(sfv.Value) {
-					if col := t.F(sfv.Field.FieldName); col != nil {
+					if col := t.F(sfv.Field.FieldName); col != nil && sqlbuilder.GetColumnDef(col).Generated == nil {
 						orderedCols.(sqlbuilder.ColumnCollectionManger).AddCol(col)
 						values = append(values, sfv.Value.Interface())
 					}
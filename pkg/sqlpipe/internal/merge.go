@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlbuilder/structs"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqltype"
+)
+
+// Merge 描述以 MERGE 语句渲染的插入冲突处理。
+type Merge struct {
+	// On 冲突目标列
+	On sqlbuilder.ColumnSeq
+	// Where 冲突目标附加条件
+	Where sqlfrag.Fragment
+	// Assignments 命中时的更新赋值，为空时不更新
+	Assignments []sqlbuilder.Assignment
+	// UpdateWhere 命中时的更新条件
+	UpdateWhere sqlfrag.Fragment
+}
+
+func (s Builder[M]) WithMerge(merge *Merge) *Builder[M] {
+	s.Merge = merge
+	return &s
+}
+
+func (s *Builder[M]) buildMerge(ctx context.Context, m *Mutation[M]) sqlfrag.Fragment {
+	t := s.T(ctx, new(M))
+
+	additions := make([]sqlbuilder.Addition, 0)
+
+	if projects := s.prepareProjects(); len(projects) > 0 {
+		additions = append(additions, sqlbuilder.Returning(sqlfrag.JoinValues(", ", projects...)))
+	}
+
+	cols := m.PrepareColumnCollectionForInsert(t)
+
+	orderedCols := sqlbuilder.Cols()
+
+	for value := range m.Values {
+		for sfv := range structs.AllFieldValue(ctx, value) {
+			if col := cols.F(sfv.Field.FieldName); col != nil {
+				orderedCols.(sqlbuilder.ColumnCollectionManger).AddCol(col)
+			}
+		}
+		break
+	}
+
+	if orderedCols.Len() == 0 {
+		return sqlfrag.Empty()
+	}
+
+	values := slices.Collect(func(yield func(any) bool) {
+		for value := range m.Values {
+			if canSetModification, ok := any(value).(sqltype.WithModificationTime); ok {
+				canSetModification.MarkModifiedAt()
+			} else if canSetCreationTime, ok := any(value).(sqltype.WithCreationTime); ok {
+				canSetCreationTime.MarkCreatedAt()
+			}
+
+			for sfv := range structs.AllFieldValue(ctx, value) {
+				if col := cols.F(sfv.Field.FieldName); col != nil {
+					if !yield(sfv.Value.Interface()) {
+						return
+					}
+				}
+			}
+		}
+	})
+
+	on := make([]sqlfrag.Fragment, 0)
+	for col := range s.Merge.On.Cols() {
+		on = append(on, col.Fragment("# = EXCLUDED.?", sqlfrag.Const(col.Name())))
+	}
+	on = append(on, s.Merge.Where)
+
+	excludedValues := make([]sqlfrag.Fragment, 0, orderedCols.Len())
+	for col := range orderedCols.Cols() {
+		excludedValues = append(excludedValues, sqlfrag.Pair("EXCLUDED.?", sqlfrag.Const(col.Name())))
+	}
+
+	stmt := sqlbuilder.MergeInto(t, additions...).Using(
+		sqlfrag.Pair(
+			"? AS EXCLUDED",
+			sqlfrag.Block(sqlfrag.Pair(
+				// select from target first to make VALUES typed as target columns
+				"?\nUNION ALL\nVALUES ?",
+				sqlbuilder.Select(orderedCols).From(t, sqlbuilder.Where(sqlfrag.Const("FALSE"))),
+				sqlfrag.BlockWithoutBrackets(sqlfrag.Map(slices.Chunk(values, orderedCols.Len()), func(values []any) sqlfrag.Fragment {
+					return sqlfrag.Pair("\n("+strings.Repeat(",?", len(values))[1:]+")", values...)
+				})),
+			)),
+		),
+		sqlbuilder.And(on...),
+	)
+
+	if len(s.Merge.Assignments) > 0 {
+		stmt = stmt.WhenMatched(s.Merge.UpdateWhere, sqlbuilder.MergeUpdateSet(s.Merge.Assignments...))
+	} else {
+		stmt = stmt.WhenMatched(nil, sqlbuilder.MergeDoNothing())
+	}
+
+	return stmt.WhenNotMatched(nil, sqlbuilder.MergeInsert(orderedCols, excludedValues...))
+}
@@ -65,7 +65,8 @@ func (s *Strict[M]) StrictColumnCollection(t sqlbuilder.Table) sqlbuilder.Column
 			for col := range t.Cols() {
 				def := sqlbuilder.GetColumnDef(col)
 
-				if def.DeprecatedActions != nil || def.AutoIncrement {
+				// generated column could not be written
+				if def.DeprecatedActions != nil || def.AutoIncrement || def.Generated != nil {
 					continue
 				}
 
@@ -78,7 +79,7 @@ func (s *Strict[M]) StrictColumnCollection(t sqlbuilder.Table) sqlbuilder.Column
 		}
 
 		for _, c := range s.Columns {
-			if col := t.F(c.FieldName()); col != nil {
+			if col := t.F(c.FieldName()); col != nil && sqlbuilder.GetColumnDef(col).Generated == nil {
 				cols.(sqlbuilder.ColumnCollectionManger).AddCol(col)
 			}
 		}
@@ -89,7 +90,8 @@ func (s *Strict[M]) StrictColumnCollection(t sqlbuilder.Table) sqlbuilder.Column
 	for col := range t.Cols() {
 		def := sqlbuilder.GetColumnDef(col)
 
-		if def.DeprecatedActions != nil || def.AutoIncrement {
+		// generated column could not be written
+		if def.DeprecatedActions != nil || def.AutoIncrement || def.Generated != nil {
 			continue
 		}
 
@@ -153,7 +155,7 @@ func (m *Mutation[M]) PrepareAssignments(ctx context.Context, t sqlbuilder.Table
 		return func(yield func(sqlbuilder.Assignment) bool) {
 			for sfv := range structs.AllFieldValue(ctx, values[0]) {
 				if includes.F(sfv.Field.FieldName) != nil || !reflectx.IsEmptyValue(sfv.Value) {
-					if col := t.F(sfv.Field.FieldName); col != nil {
+					if col := t.F(sfv.Field.FieldName); col != nil && sqlbuilder.GetColumnDef(col).Generated == nil {
 						if !yield(sqlbuilder.CastColumn[any](col).By(sqlbuilder.Value(sfv.Value.Interface()))) {
 							return
 						}
@@ -36,6 +36,31 @@ func TestMutationStrict(t *testing.T) {
 	)
 }
 
+type orderWithGenerated struct {
+	ID    uint64 `db:"f_id,autoincrement"`
+	Price int64  `db:"f_price"`
+	Total int64  `db:"f_total,stored" generated:"f_price * 2"`
+}
+
+func (orderWithGenerated) TableName() string {
+	return "t_order"
+}
+
+func TestMutationStrictSkipGenerated(t *testing.T) {
+	tbl := sqlbuilder.TableFromModel(&orderWithGenerated{})
+	mut := &Mutation[orderWithGenerated]{}
+
+	names := make([]string, 0)
+	for col := range mut.Strict.StrictColumnCollection(tbl).Cols() {
+		names = append(names, col.FieldName())
+	}
+
+	Then(
+		t, "生成列与自增列不会出现在写入列集合中",
+		Expect(names, Equal([]string{"Price"})),
+	)
+}
+
 func TestMutationBranches(t *testing.T) {
 	tbl := sqlbuilder.TableFromModel(&model.User{})
 
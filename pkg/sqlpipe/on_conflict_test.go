@@ -2,6 +2,7 @@ package sqlpipe
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	. "github.com/octohelm/x/testing/v2"
@@ -53,3 +54,74 @@ func TestOnConflictBranches(t *testing.T) {
 		Expect(q, Equal("ON CONFLICT (f_name,f_deleted_at) DO UPDATE SET f_name = EXCLUDED.f_name")),
 	)
 }
+
+func TestOnConflictWithOptions(t *testing.T) {
+	partial := &onConflictSource[model.User]{
+		cols:    model.UserT.I.IName,
+		updates: []modelscoped.Column[model.User]{model.UserT.Nickname},
+	}
+	partial.build(
+		ConflictWhere(model.UserT.DeletedAt.V(sqlbuilder.Eq[int64](0))),
+		UpdateWhere(model.UserT.Nickname.V(sqlbuilder.Neq(""))),
+	)
+
+	q, args := sqlfrag.Collect(context.Background(), partial.toOnConflictAddition(context.Background(), 0))
+	Then(
+		t, "冲突目标谓词与更新条件分别渲染在 DO 前后",
+		Expect(q, Equal("ON CONFLICT (f_name,f_deleted_at) WHERE f_deleted_at = ? DO UPDATE SET f_nickname = EXCLUDED.f_nickname WHERE t_user.f_nickname <> ?")),
+		Expect(args, Equal([]any{int64(0), ""})),
+	)
+
+	doNothing := &onConflictSource[model.User]{
+		cols: model.UserT.I.IName,
+	}
+	doNothing.build(UpdateWhere(model.UserT.Nickname.V(sqlbuilder.Neq(""))))
+
+	q, _ = sqlfrag.Collect(context.Background(), doNothing.toOnConflictAddition(context.Background(), flags.ForReturning))
+	Then(
+		t, "未声明更新列时忽略更新条件",
+		Expect(q, Equal("ON CONFLICT (f_name,f_deleted_at) DO UPDATE SET f_name = EXCLUDED.f_name")),
+	)
+
+	q, _ = sqlfrag.Collect(context.Background(), upsertDialectOf[model.User](context.Background()).Excluded(model.UserT.Name))
+	Then(
+		t, "无会话时按标准 SQL 渲染 EXCLUDED",
+		Expect(q, Equal("EXCLUDED.f_name")),
+	)
+}
+
+func TestOnConflictUseMerge(t *testing.T) {
+	src := Values([]*model.User{
+		{Name: "alice", Nickname: "a"},
+	}).Pipe(
+		Upsert(
+			model.UserT.I.IName,
+			[]modelscoped.Column[model.User]{model.UserT.Nickname},
+			UpdateWhere(model.UserT.Nickname.V(sqlbuilder.Neq(""))),
+			UseMerge(),
+		),
+	)
+
+	q, _ := sqlfrag.Collect(context.Background(), src)
+
+	Then(
+		t, "UseMerge 会把插入渲染为 MERGE",
+		Expect(strings.HasPrefix(strings.TrimSpace(q), "MERGE INTO t_user"), Equal(true)),
+		Expect(strings.Contains(q, "UNION ALL"), Equal(true)),
+		Expect(strings.Contains(q, ") AS EXCLUDED"), Equal(true)),
+		Expect(strings.Contains(q, "ON (t_user.f_name = EXCLUDED.f_name) AND (t_user.f_deleted_at = EXCLUDED.f_deleted_at)"), Equal(true)),
+		Expect(strings.Contains(q, "WHEN MATCHED AND t_user.f_nickname <> ? THEN UPDATE SET f_nickname = EXCLUDED.f_nickname"), Equal(true)),
+		Expect(strings.Contains(q, "WHEN NOT MATCHED THEN INSERT ("), Equal(true)),
+	)
+
+	omitZero := ValueOmitZero(&model.User{Name: "alice"}).Pipe(
+		OnConflictDoNothing(model.UserT.I.IName, UseMerge()),
+	)
+
+	q, _ = sqlfrag.Collect(context.Background(), omitZero)
+
+	Then(
+		t, "无法转换为 MERGE 时回退为 ON CONFLICT",
+		Expect(strings.Contains(q, "ON CONFLICT (f_name,f_deleted_at) DO NOTHING"), Equal(true)),
+	)
+}
@@ -119,6 +119,15 @@ func CastOrWhere[M Model, U Model, T comparable](col modelscoped.TypedColumn[U,
 	})
 }
 
+// WhereCond 以已组合好的条件片段构造 AND 过滤条件，条件中的列可来自连接的其他模型。
+func WhereCond[M Model](cond sqlfrag.Fragment) SourceOperator[M] {
+	return SourceOperatorFunc[M](OperatorFilter, func(src Source[M]) Source[M] {
+		return newFilteredSource[M](src, FilterOpAnd, func(ctx context.Context) sqlfrag.Fragment {
+			return cond
+		})
+	})
+}
+
 func newFilteredSource[M Model](src Source[M], op FilterOp, builder func(ctx context.Context) sqlfrag.Fragment) Source[M] {
 	switch x := src.(type) {
 	case *filteredSource[M]:
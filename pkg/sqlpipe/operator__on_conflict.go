@@ -4,6 +4,8 @@ import (
 	"context"
 	"iter"
 
+	"github.com/octohelm/storage/internal/sql/adapter"
+	"github.com/octohelm/storage/pkg/session"
 	"github.com/octohelm/storage/pkg/sqlbuilder"
 	"github.com/octohelm/storage/pkg/sqlbuilder/modelscoped"
 	"github.com/octohelm/storage/pkg/sqlfrag"
@@ -11,28 +13,61 @@ import (
 	"github.com/octohelm/storage/pkg/sqlpipe/internal/flags"
 )
 
+// OnConflictOptionFunc 表示 ON CONFLICT 的选项函数。
+type OnConflictOptionFunc = func(o OnConflictOption)
+
+// OnConflictOption 接收 ON CONFLICT 的附加配置。
+type OnConflictOption interface {
+	SetConflictWhere(where sqlfrag.Fragment)
+	SetUpdateWhere(where sqlfrag.Fragment)
+	SetUseMerge(useMerge bool)
+}
+
+// ConflictWhere 为冲突目标追加索引谓词，用于匹配部分唯一索引。
+func ConflictWhere(where sqlfrag.Fragment) OnConflictOptionFunc {
+	return func(o OnConflictOption) {
+		o.SetConflictWhere(where)
+	}
+}
+
+// UpdateWhere 为冲突更新追加条件，不满足时保留原记录。
+func UpdateWhere(where sqlfrag.Fragment) OnConflictOptionFunc {
+	return func(o OnConflictOption) {
+		o.SetUpdateWhere(where)
+	}
+}
+
+// UseMerge 在 PostgreSQL 15+ 上改用 MERGE 渲染，需要 RETURNING 时要求 17+；SQLite 上忽略。
+func UseMerge() OnConflictOptionFunc {
+	return func(o OnConflictOption) {
+		o.SetUseMerge(true)
+	}
+}
+
 // OnConflictDoNothing 为插入源追加 ON CONFLICT DO NOTHING。
-func OnConflictDoNothing[M sqlbuilder.Model](cols modelscoped.ColumnSeq[M]) SourceOperator[M] {
-	return SourceOperatorFunc[M](OperatorOnConflict, func(src Source[M]) Source[M] {
-		return &onConflictSource[M]{
-			Embed: Embed[M]{
-				Underlying: src,
-			},
-			cols: cols,
-		}
-	})
+func OnConflictDoNothing[M sqlbuilder.Model](cols modelscoped.ColumnSeq[M], optFns ...OnConflictOptionFunc) SourceOperator[M] {
+	return Upsert(cols, nil, optFns...)
 }
 
 // OnConflictDoUpdateSet 为插入源追加 ON CONFLICT DO UPDATE SET。
 func OnConflictDoUpdateSet[M sqlbuilder.Model](cols modelscoped.ColumnSeq[M], toUpdates ...modelscoped.Column[M]) SourceOperator[M] {
+	return Upsert(cols, toUpdates)
+}
+
+// Upsert 为插入源追加按方言渲染的冲突处理，toUpdates 为空时不更新已存在记录。
+//
+// cols 可直接传入 modelscoped.Key，此时按索引名从当前会话的表中解析冲突列。
+func Upsert[M sqlbuilder.Model](cols modelscoped.ColumnSeq[M], toUpdates []modelscoped.Column[M], optFns ...OnConflictOptionFunc) SourceOperator[M] {
 	return SourceOperatorFunc[M](OperatorOnConflict, func(src Source[M]) Source[M] {
-		return &onConflictSource[M]{
+		s := &onConflictSource[M]{
 			Embed: Embed[M]{
 				Underlying: src,
 			},
 			cols:    cols,
 			updates: toUpdates,
 		}
+		s.build(optFns...)
+		return s
 	})
 }
 
@@ -58,6 +93,28 @@ type onConflictSource[M sqlbuilder.Model] struct {
 	cols    modelscoped.ColumnSeq[M]
 	updates []modelscoped.Column[M]
 	with    func(onConflictAddition sqlbuilder.OnConflictAddition) sqlbuilder.OnConflictAddition
+
+	conflictWhere sqlfrag.Fragment
+	updateWhere   sqlfrag.Fragment
+	useMerge      bool
+}
+
+func (s *onConflictSource[M]) SetConflictWhere(where sqlfrag.Fragment) {
+	s.conflictWhere = where
+}
+
+func (s *onConflictSource[M]) SetUpdateWhere(where sqlfrag.Fragment) {
+	s.updateWhere = where
+}
+
+func (s *onConflictSource[M]) SetUseMerge(useMerge bool) {
+	s.useMerge = useMerge
+}
+
+func (s *onConflictSource[M]) build(optFns ...OnConflictOptionFunc) {
+	for _, optFn := range optFns {
+		optFn(s)
+	}
 }
 
 func (s *onConflictSource[M]) Frag(ctx context.Context) iter.Seq2[string, []any] {
@@ -65,38 +122,112 @@ func (s *onConflictSource[M]) Frag(ctx context.Context) iter.Seq2[string, []any]
 }
 
 func (s *onConflictSource[M]) ApplyStmt(ctx context.Context, b *internal.Builder[M]) *internal.Builder[M] {
+	if s.useMerge && s.with == nil && upsertDialectOf[M](ctx).SupportMerge() {
+		next := s.Underlying.ApplyStmt(ctx, b)
+
+		// only values insert could be converted to MERGE
+		if mut, ok := next.Source.(*internal.Mutation[M]); ok && mut.Values != nil && !mut.OmitZero.Enabled && !mut.ForUpdate && mut.ForDelete == internal.DeleteTypeNone {
+			return next.WithMerge(s.toMerge(ctx, s.GetFlag(ctx)))
+		}
+
+		return next.WithAdditions(s.toOnConflictAddition(ctx, s.GetFlag(ctx)))
+	}
+
 	return s.Underlying.ApplyStmt(
 		ctx,
 		b.WithAdditions(s.toOnConflictAddition(ctx, s.GetFlag(ctx))),
 	)
 }
 
-func (s *onConflictSource[M]) toOnConflictAddition(ctx context.Context, f flags.Flag) sqlbuilder.OnConflictAddition {
-	if s.with != nil {
-		return s.with(sqlbuilder.OnConflict(s.cols))
+func (s *onConflictSource[M]) conflictCols(ctx context.Context) sqlbuilder.ColumnSeq {
+	if k, ok := s.cols.(sqlbuilder.Key); ok {
+		// resolve key by name to follow the table of session
+		if key := (&internal.Builder[M]{}).T(ctx, nil).K(k.Name()); key != nil {
+			return key
+		}
 	}
+	return s.cols
+}
+
+// conflictWhereOf 未显式指定 ConflictWhere 时，部分唯一索引沿用索引谓词作为冲突目标谓词。
+func (s *onConflictSource[M]) conflictWhereOf(cols sqlbuilder.ColumnSeq) sqlfrag.Fragment {
+	if !sqlfrag.IsNil(s.conflictWhere) {
+		return s.conflictWhere
+	}
+
+	if k, ok := cols.(sqlbuilder.Key); ok {
+		if keyDef := sqlbuilder.GetKeyDef(k); keyDef != nil && keyDef.Where() != "" {
+			return sqlfrag.Const(keyDef.Where())
+		}
+	}
+
+	return nil
+}
+
+func (s *onConflictSource[M]) toAssignments(ctx context.Context, f flags.Flag, cols sqlbuilder.ColumnSeq) []sqlbuilder.Assignment {
+	d := upsertDialectOf[M](ctx)
 
 	if len(s.updates) > 0 {
 		assignments := make([]sqlbuilder.Assignment, len(s.updates))
 
 		for idx, col := range s.updates {
-			assignments[idx] = sqlbuilder.ColumnsAndValues(
-				col, col.Fragment("EXCLUDED.?", sqlfrag.Const(col.Name())),
-			)
+			assignments[idx] = sqlbuilder.ColumnsAndValues(col, d.Excluded(col))
 		}
 
-		return sqlbuilder.OnConflict(s.cols).DoUpdateSet(assignments...)
+		return assignments
 	}
 
 	if f.Is(flags.ForReturning) {
-		for col := range s.cols.Cols() {
-			return sqlbuilder.OnConflict(s.cols).DoUpdateSet(sqlbuilder.ColumnsAndValues(
-				col, col.Fragment("EXCLUDED.?", sqlfrag.Const(col.Name())),
-			))
+		// self update to make conflicted rows returned
+		for col := range cols.Cols() {
+			return []sqlbuilder.Assignment{
+				sqlbuilder.ColumnsAndValues(col, d.Excluded(col)),
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *onConflictSource[M]) toOnConflictAddition(ctx context.Context, f flags.Flag) sqlbuilder.OnConflictAddition {
+	cols := s.conflictCols(ctx)
+
+	onConflict := sqlbuilder.OnConflict(cols)
+	if conflictWhere := s.conflictWhereOf(cols); !sqlfrag.IsNil(conflictWhere) {
+		onConflict = onConflict.Where(conflictWhere)
+	}
+
+	if s.with != nil {
+		return s.with(onConflict)
+	}
+
+	if assignments := s.toAssignments(ctx, f, cols); len(assignments) > 0 {
+		onConflict = onConflict.DoUpdateSet(assignments...)
+
+		if !sqlfrag.IsNil(s.updateWhere) && len(s.updates) > 0 {
+			onConflict = onConflict.DoUpdateWhere(s.updateWhere)
 		}
+
+		return onConflict
+	}
+
+	return onConflict.DoNothing()
+}
+
+func (s *onConflictSource[M]) toMerge(ctx context.Context, f flags.Flag) *internal.Merge {
+	cols := s.conflictCols(ctx)
+
+	m := &internal.Merge{
+		On:          cols,
+		Where:       s.conflictWhere,
+		Assignments: s.toAssignments(ctx, f, cols),
+	}
+
+	if len(s.updates) > 0 {
+		m.UpdateWhere = s.updateWhere
 	}
 
-	return sqlbuilder.OnConflict(s.cols).DoNothing()
+	return m
 }
 
 func (s *onConflictSource[M]) Pipe(operators ...SourceOperator[M]) Source[M] {
@@ -106,3 +237,23 @@ func (s *onConflictSource[M]) Pipe(operators ...SourceOperator[M]) Source[M] {
 func (s *onConflictSource[M]) String() string {
 	return internal.ToString(s)
 }
+
+// upsertDialectOf 返回当前会话方言的冲突更新渲染方式，无会话或方言未实现时按标准 SQL 渲染。
+func upsertDialectOf[M Model](ctx context.Context) adapter.UpsertDialect {
+	if s := session.For(ctx, new(M)); s != nil {
+		if d, ok := s.Adapter().Dialect().(adapter.UpsertDialect); ok {
+			return d
+		}
+	}
+	return standardUpsert{}
+}
+
+type standardUpsert struct{}
+
+func (standardUpsert) Excluded(col sqlbuilder.Column) sqlfrag.Fragment {
+	return col.Fragment("EXCLUDED.?", sqlfrag.Const(col.Name()))
+}
+
+func (standardUpsert) SupportMerge() bool {
+	return true
+}
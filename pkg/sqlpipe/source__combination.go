@@ -0,0 +1,119 @@
+package sqlpipe
+
+import (
+	"context"
+	"iter"
+
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlpipe/internal"
+	"github.com/octohelm/storage/pkg/sqlpipe/internal/flags"
+)
+
+// Union 以 UNION 合并多个数据源，并去除重复记录。
+func Union[M Model](sources ...Source[M]) Source[M] {
+	return combine("UNION", "", sources...)
+}
+
+// UnionAll 以 UNION ALL 合并多个数据源，并保留重复记录。
+func UnionAll[M Model](sources ...Source[M]) Source[M] {
+	return combine("UNION", "ALL", sources...)
+}
+
+// Intersect 以 INTERSECT 取多个数据源的交集。
+func Intersect[M Model](sources ...Source[M]) Source[M] {
+	return combine("INTERSECT", "", sources...)
+}
+
+// IntersectAll 以 INTERSECT ALL 取多个数据源的交集，SQLite 不支持。
+func IntersectAll[M Model](sources ...Source[M]) Source[M] {
+	return combine("INTERSECT", "ALL", sources...)
+}
+
+// Except 以 EXCEPT 从首个数据源中排除后续数据源的记录。
+func Except[M Model](sources ...Source[M]) Source[M] {
+	return combine("EXCEPT", "", sources...)
+}
+
+// ExceptAll 以 EXCEPT ALL 从首个数据源中排除后续数据源的记录，SQLite 不支持。
+func ExceptAll[M Model](sources ...Source[M]) Source[M] {
+	return combine("EXCEPT", "ALL", sources...)
+}
+
+func combine[M Model](operator string, method string, sources ...Source[M]) Source[M] {
+	nonNil := make([]Source[M], 0, len(sources))
+	for _, src := range sources {
+		if !sqlfrag.IsNil(src) {
+			nonNil = append(nonNil, src)
+		}
+	}
+
+	switch len(nonNil) {
+	case 0:
+		return &noop[M]{}
+	case 1:
+		return nonNil[0]
+	}
+
+	s := &combinedSource[M]{
+		operator: operator,
+		method:   method,
+		sources:  nonNil,
+	}
+
+	// soft delete already patched in each source
+	s.Flag = flags.IncludesAll
+
+	return s
+}
+
+type combinedSource[M Model] struct {
+	internal.Seed
+
+	operator string // UNION | INTERSECT | EXCEPT
+	method   string // ALL
+	sources  []Source[M]
+}
+
+func (s *combinedSource[M]) IsNil() bool {
+	return s == nil || len(s.sources) == 0
+}
+
+func (s *combinedSource[M]) Pipe(operators ...SourceOperator[M]) Source[M] {
+	return Pipe[M](s, operators...)
+}
+
+func (s *combinedSource[M]) Frag(ctx context.Context) iter.Seq2[string, []any] {
+	return internal.CollectStmt(ctx, s)
+}
+
+func (s *combinedSource[M]) String() string {
+	return internal.ToString(s)
+}
+
+func (s *combinedSource[M]) ApplyStmt(ctx context.Context, b *internal.Builder[M]) *internal.Builder[M] {
+	operator := s.operator
+	if s.method != "" {
+		operator += " " + s.method
+	}
+
+	tableName := sqlfrag.Const((*new(M)).TableName())
+
+	stmts := make([]sqlfrag.Fragment, 0, len(s.sources))
+	for _, src := range s.sources {
+		// keep ORDER BY and LIMIT of each member inside its own sub select,
+		// sqlite not allow parenthesized member of compound select
+		stmts = append(stmts, sqlfrag.Pair(
+			"\nSELECT *\nFROM ? AS ?",
+			sqlfrag.Block(internal.BuildStmt[M](ctx, src)),
+			tableName,
+		))
+	}
+
+	return b.WithFlag(s.Flag).WithSource(
+		sqlfrag.Pair(
+			"? AS ?",
+			sqlfrag.Block(sqlfrag.JoinValues("\n"+operator, stmts...)),
+			tableName,
+		),
+	)
+}
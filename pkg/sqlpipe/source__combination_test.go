@@ -0,0 +1,154 @@
+package sqlpipe_test
+
+import (
+	"testing"
+
+	testingx "github.com/octohelm/x/testing"
+
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+	"github.com/octohelm/storage/pkg/sqlfrag"
+	"github.com/octohelm/storage/pkg/sqlfrag/testutil"
+	"github.com/octohelm/storage/pkg/sqlpipe"
+	"github.com/octohelm/storage/testdata/model"
+)
+
+func TestCombination(t *testing.T) {
+	t.Run("union all", func(t *testing.T) {
+		src := sqlpipe.UnionAll(
+			sqlpipe.From[model.User]().Pipe(
+				sqlpipe.Where(model.UserT.Name, sqlbuilder.Eq("a")),
+			),
+			sqlpipe.FromAll[model.User]().Pipe(
+				sqlpipe.Where(model.UserT.Age, sqlbuilder.Eq(int64(18))),
+			),
+		)
+
+		testingx.Expect[sqlfrag.Fragment](t, src, testutil.BeFragment(`
+SELECT *
+FROM (
+	SELECT *
+	FROM (
+		SELECT *
+		FROM t_user
+		WHERE (f_name = ?) AND (f_deleted_at = ?)
+	) AS t_user
+	UNION ALL
+	SELECT *
+	FROM (
+		SELECT *
+		FROM t_user
+		WHERE f_age = ?
+	) AS t_user
+) AS t_user
+`, "a", int64(0), int64(18)))
+	})
+
+	t.Run("union could be piped", func(t *testing.T) {
+		src := sqlpipe.Union(
+			sqlpipe.FromAll[model.User](),
+			sqlpipe.FromAll[model.User](),
+		).Pipe(
+			sqlpipe.Where(model.UserT.Age, sqlbuilder.Eq(int64(18))),
+			sqlpipe.DescSort(model.UserT.Age),
+			sqlpipe.Limit[model.User](10),
+		)
+
+		testingx.Expect[sqlfrag.Fragment](t, src, testutil.BeFragment(`
+SELECT *
+FROM (
+	SELECT *
+	FROM (
+		SELECT *
+		FROM t_user
+	) AS t_user
+	UNION
+	SELECT *
+	FROM (
+		SELECT *
+		FROM t_user
+	) AS t_user
+) AS t_user
+WHERE f_age = ?
+ORDER BY (f_age) DESC
+LIMIT 10
+`, int64(18)))
+	})
+
+	t.Run("intersect and except", func(t *testing.T) {
+		testingx.Expect[sqlfrag.Fragment](t, sqlpipe.Intersect(
+			sqlpipe.FromAll[model.User](),
+			sqlpipe.FromAll[model.User](),
+		), testutil.BeFragment(`
+SELECT *
+FROM (
+	SELECT *
+	FROM (
+		SELECT *
+		FROM t_user
+	) AS t_user
+	INTERSECT
+	SELECT *
+	FROM (
+		SELECT *
+		FROM t_user
+	) AS t_user
+) AS t_user
+`))
+
+		testingx.Expect[sqlfrag.Fragment](t, sqlpipe.ExceptAll(
+			sqlpipe.FromAll[model.User](),
+			sqlpipe.FromAll[model.User](),
+		), testutil.BeFragment(`
+SELECT *
+FROM (
+	SELECT *
+	FROM (
+		SELECT *
+		FROM t_user
+	) AS t_user
+	EXCEPT ALL
+	SELECT *
+	FROM (
+		SELECT *
+		FROM t_user
+	) AS t_user
+) AS t_user
+`))
+	})
+
+	t.Run("members keep their own sort and limit", func(t *testing.T) {
+		src := sqlpipe.UnionAll(
+			sqlpipe.FromAll[model.User]().Pipe(
+				sqlpipe.DescSort(model.UserT.Age),
+				sqlpipe.Limit[model.User](1),
+			),
+			sqlpipe.FromAll[model.User](),
+		)
+
+		testingx.Expect[sqlfrag.Fragment](t, src, testutil.BeFragment(`
+SELECT *
+FROM (
+	SELECT *
+	FROM (
+		SELECT *
+		FROM t_user
+		ORDER BY (f_age) DESC
+		LIMIT 1
+	) AS t_user
+	UNION ALL
+	SELECT *
+	FROM (
+		SELECT *
+		FROM t_user
+	) AS t_user
+) AS t_user
+`))
+	})
+
+	t.Run("single source should be returned directly", func(t *testing.T) {
+		testingx.Expect[sqlfrag.Fragment](t, sqlpipe.Union(sqlpipe.FromAll[model.User]()), testutil.BeFragment(`
+SELECT *
+FROM t_user
+`))
+	})
+}
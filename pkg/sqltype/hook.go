@@ -0,0 +1,37 @@
+package sqltype
+
+import (
+	"context"
+
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+)
+
+// BeforeInsertHook 表示模型在插入前、同一事务内的回调。
+type BeforeInsertHook interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// AfterInsertHook 表示模型在插入后、同一事务内的回调。
+type AfterInsertHook interface {
+	AfterInsert(ctx context.Context) error
+}
+
+// BeforeUpdateHook 表示模型在更新前、同一事务内的回调；按条件更新且未提供模型值时不调用。
+type BeforeUpdateHook interface {
+	BeforeUpdate(ctx context.Context, assignments []sqlbuilder.Assignment) error
+}
+
+// AfterUpdateHook 表示模型在更新后、同一事务内的回调；有 RETURNING 记录时以其为接收者，按条件更新且无模型值时不调用。
+type AfterUpdateHook interface {
+	AfterUpdate(ctx context.Context, assignments []sqlbuilder.Assignment) error
+}
+
+// BeforeDeleteHook 表示模型在删除前、同一事务内的回调；按条件删除时不调用。
+type BeforeDeleteHook interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+// AfterDeleteHook 表示模型在删除后、同一事务内的回调；按条件删除时仅对 RETURNING 的记录调用。
+type AfterDeleteHook interface {
+	AfterDelete(ctx context.Context) error
+}
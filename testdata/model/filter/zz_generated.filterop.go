@@ -10,7 +10,7 @@ import (
 
 type UserByID struct {
 	// 通过 用户ID 筛选
-	ID *filter.Filter[model.UserID] `name:"user~id,omitzero" in:"query"`
+	ID *filter.Filter[model.UserID] `name:"user~id,omitzero" in:"query" ops:"eq,neq,in,notin,gt,gte,lt,lte"`
 }
 
 func (f *UserByID) OperatorType() sqlpipe.OperatorType {
@@ -23,7 +23,7 @@ func (f *UserByID) Next(src sqlpipe.Source[model.User]) sqlpipe.Source[model.Use
 
 type UserByName struct {
 	// 通过 姓名 筛选
-	Name *filter.Filter[string] `name:"user~name,omitzero" in:"query"`
+	Name *filter.Filter[string] `name:"user~name,omitzero" in:"query" ops:"eq,neq,in,notin,prefix,suffix,contains,notcontains,iprefix,isuffix,icontains,noticontains"`
 }
 
 func (f *UserByName) OperatorType() sqlpipe.OperatorType {
@@ -36,7 +36,7 @@ func (f *UserByName) Next(src sqlpipe.Source[model.User]) sqlpipe.Source[model.U
 
 type UserByNickname struct {
 	// 通过 昵称 筛选
-	Nickname *filter.Filter[string] `name:"user~nickname,omitzero" in:"query"`
+	Nickname *filter.Filter[string] `name:"user~nickname,omitzero" in:"query" ops:"eq,neq,in,notin,prefix,suffix,contains,notcontains,iprefix,isuffix,icontains,noticontains"`
 }
 
 func (f *UserByNickname) OperatorType() sqlpipe.OperatorType {
@@ -49,7 +49,7 @@ func (f *UserByNickname) Next(src sqlpipe.Source[model.User]) sqlpipe.Source[mod
 
 type UserByAge struct {
 	// 通过 年龄 筛选
-	Age *filter.Filter[int64] `name:"user~age,omitzero" in:"query"`
+	Age *filter.Filter[int64] `name:"user~age,omitzero" in:"query" ops:"eq,neq,in,notin,gt,gte,lt,lte"`
 }
 
 func (f *UserByAge) OperatorType() sqlpipe.OperatorType {
@@ -61,7 +61,7 @@ func (f *UserByAge) Next(src sqlpipe.Source[model.User]) sqlpipe.Source[model.Us
 }
 
 type UserByCreatedAt struct {
-	CreatedAt *filter.Filter[model.Datetime] `name:"user~createdAt,omitzero" in:"query"`
+	CreatedAt *filter.Filter[model.Datetime] `name:"user~createdAt,omitzero" in:"query" ops:"eq,neq,in,notin,gt,gte,lt,lte,between,since,before,day"`
 }
 
 func (f *UserByCreatedAt) OperatorType() sqlpipe.OperatorType {
@@ -73,7 +73,7 @@ func (f *UserByCreatedAt) Next(src sqlpipe.Source[model.User]) sqlpipe.Source[mo
 }
 
 type UserByDeletedAt struct {
-	DeletedAt *filter.Filter[int64] `name:"user~deletedAt,omitzero" in:"query"`
+	DeletedAt *filter.Filter[int64] `name:"user~deletedAt,omitzero" in:"query" ops:"eq,neq,in,notin,gt,gte,lt,lte"`
 }
 
 func (f *UserByDeletedAt) OperatorType() sqlpipe.OperatorType {
@@ -84,8 +84,18 @@ func (f *UserByDeletedAt) Next(src sqlpipe.Source[model.User]) sqlpipe.Source[mo
 	return src.Pipe(sqlpipefilter.AsWhere(model.UserT.DeletedAt, f.DeletedAt))
 }
 
+// UserFields 汇总 User 的可筛选字段，用于翻译 filter.Composed
+var UserFields = sqlpipefilter.Fields{
+	sqlpipefilter.FieldOf("user~id", model.UserT.ID),
+	sqlpipefilter.FieldOf("user~name", model.UserT.Name),
+	sqlpipefilter.FieldOf("user~nickname", model.UserT.Nickname),
+	sqlpipefilter.FieldOf("user~age", model.UserT.Age),
+	sqlpipefilter.FieldOf("user~createdAt", model.UserT.CreatedAt),
+	sqlpipefilter.FieldOf("user~deletedAt", model.UserT.DeletedAt),
+}
+
 type OrgByID struct {
-	ID *filter.Filter[model.OrgID] `name:"org~id,omitzero" in:"query"`
+	ID *filter.Filter[model.OrgID] `name:"org~id,omitzero" in:"query" ops:"eq,neq,in,notin,gt,gte,lt,lte"`
 }
 
 func (f *OrgByID) OperatorType() sqlpipe.OperatorType {
@@ -97,7 +107,7 @@ func (f *OrgByID) Next(src sqlpipe.Source[model.Org]) sqlpipe.Source[model.Org]
 }
 
 type OrgByName struct {
-	Name *filter.Filter[string] `name:"org~name,omitzero" in:"query"`
+	Name *filter.Filter[string] `name:"org~name,omitzero" in:"query" ops:"eq,neq,in,notin,prefix,suffix,contains,notcontains,iprefix,isuffix,icontains,noticontains"`
 }
 
 func (f *OrgByName) OperatorType() sqlpipe.OperatorType {
@@ -109,7 +119,7 @@ func (f *OrgByName) Next(src sqlpipe.Source[model.Org]) sqlpipe.Source[model.Org
 }
 
 type OrgByCreatedAt struct {
-	CreatedAt *filter.Filter[model.Datetime] `name:"org~createdAt,omitzero" in:"query"`
+	CreatedAt *filter.Filter[model.Datetime] `name:"org~createdAt,omitzero" in:"query" ops:"eq,neq,in,notin,gt,gte,lt,lte,between,since,before,day"`
 }
 
 func (f *OrgByCreatedAt) OperatorType() sqlpipe.OperatorType {
@@ -120,8 +130,15 @@ func (f *OrgByCreatedAt) Next(src sqlpipe.Source[model.Org]) sqlpipe.Source[mode
 	return src.Pipe(sqlpipefilter.AsWhere(model.OrgT.CreatedAt, f.CreatedAt))
 }
 
+// OrgFields 汇总 Org 的可筛选字段，用于翻译 filter.Composed
+var OrgFields = sqlpipefilter.Fields{
+	sqlpipefilter.FieldOf("org~id", model.OrgT.ID),
+	sqlpipefilter.FieldOf("org~name", model.OrgT.Name),
+	sqlpipefilter.FieldOf("org~createdAt", model.OrgT.CreatedAt),
+}
+
 type OrgUserByID struct {
-	ID *filter.Filter[uint64] `name:"org-user~id,omitzero" in:"query"`
+	ID *filter.Filter[uint64] `name:"org-user~id,omitzero" in:"query" ops:"eq,neq,in,notin,gt,gte,lt,lte"`
 }
 
 func (f *OrgUserByID) OperatorType() sqlpipe.OperatorType {
@@ -133,7 +150,7 @@ func (f *OrgUserByID) Next(src sqlpipe.Source[model.OrgUser]) sqlpipe.Source[mod
 }
 
 type OrgUserByUserID struct {
-	UserID *filter.Filter[model.UserID] `name:"org-user~userID,omitzero" in:"query"`
+	UserID *filter.Filter[model.UserID] `name:"org-user~userID,omitzero" in:"query" ops:"eq,neq,in,notin,gt,gte,lt,lte"`
 }
 
 func (f *OrgUserByUserID) OperatorType() sqlpipe.OperatorType {
@@ -145,7 +162,7 @@ func (f *OrgUserByUserID) Next(src sqlpipe.Source[model.OrgUser]) sqlpipe.Source
 }
 
 type OrgUserByOrgID struct {
-	OrgID *filter.Filter[model.OrgID] `name:"org-user~orgID,omitzero" in:"query"`
+	OrgID *filter.Filter[model.OrgID] `name:"org-user~orgID,omitzero" in:"query" ops:"eq,neq,in,notin,gt,gte,lt,lte"`
 }
 
 func (f *OrgUserByOrgID) OperatorType() sqlpipe.OperatorType {
@@ -155,3 +172,10 @@ func (f *OrgUserByOrgID) OperatorType() sqlpipe.OperatorType {
 func (f *OrgUserByOrgID) Next(src sqlpipe.Source[model.OrgUser]) sqlpipe.Source[model.OrgUser] {
 	return src.Pipe(sqlpipefilter.AsWhere(model.OrgUserT.OrgID, f.OrgID))
 }
+
+// OrgUserFields 汇总 OrgUser 的可筛选字段，用于翻译 filter.Composed
+var OrgUserFields = sqlpipefilter.Fields{
+	sqlpipefilter.FieldOf("org-user~id", model.OrgUserT.ID),
+	sqlpipefilter.FieldOf("org-user~userID", model.OrgUserT.UserID),
+	sqlpipefilter.FieldOf("org-user~orgID", model.OrgUserT.OrgID),
+}
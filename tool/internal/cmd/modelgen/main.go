@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/octohelm/storage/devpkg/modelgen"
+	"github.com/octohelm/storage/pkg/session"
+	"github.com/octohelm/storage/pkg/sqlbuilder"
+
+	_ "github.com/octohelm/storage/internal/sql/adapter/postgres"
+	_ "github.com/octohelm/storage/internal/sql/adapter/sqlite"
+)
+
+func main() {
+	g := &modelgen.Generator{
+		TypeMapping: modelgen.TypeMapping{},
+	}
+
+	endpoint := flag.String("endpoint", "", "database endpoint, like postgres://postgres@localhost/db?sslmode=disable")
+	output := flag.String("o", "", "output file, default stdout")
+	tables := flag.String("tables", "", "comma separated table names to generate, default all")
+
+	flag.StringVar(&g.Package, "pkg", "model", "package name of generated file")
+	flag.StringVar(&g.TablePrefix, "table-prefix", "t_", "table name prefix to trim")
+	flag.StringVar(&g.ColumnPrefix, "column-prefix", "f_", "column name prefix to trim")
+	flag.BoolVar(&g.Strict, "strict", false, "fail on data types without type mapping instead of generating string fields")
+	flag.Func("type", "type mapping as data_type=go.Type, like jsonb=encoding/json.RawMessage", func(s string) error {
+		dataType, goType, ok := strings.Cut(s, "=")
+		if !ok || dataType == "" || goType == "" {
+			return fmt.Errorf("invalid type mapping %q", s)
+		}
+		g.TypeMapping[strings.ToLower(dataType)] = goType
+		return nil
+	})
+
+	flag.Parse()
+
+	if err := run(context.Background(), g, *endpoint, *tables, *output); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+		return
+	}
+}
+
+func run(ctx context.Context, g *modelgen.Generator, endpoint string, tables string, output string) error {
+	a, err := session.Open(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	cat, err := a.Catalog(ctx)
+	if err != nil {
+		return err
+	}
+
+	var c sqlbuilder.Catalog = cat
+
+	if tables != "" {
+		picked := &sqlbuilder.Tables{}
+		for name := range strings.SplitSeq(tables, ",") {
+			t := cat.Table(strings.TrimSpace(name))
+			if t == nil {
+				return fmt.Errorf("table %q not found", name)
+			}
+			picked.Add(t)
+		}
+		c = picked
+	}
+
+	code, err := g.Generate(c)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err := os.Stdout.Write(code)
+		return err
+	}
+
+	return os.WriteFile(output, code, 0o644)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// schemadrift 比较线上数据库与代码中声明的 catalog，存在偏差时以非零状态退出。
+//
+// -catalog 指定 sqlbuilder.Catalog 类型的包级变量（如 catalog.From(models...) 的结果），
+// 命令生成引用该变量的检查程序，在当前模块内编译后运行，直接以该 catalog 调用 migrator.DetectDrift。
+func main() {
+	endpoint := flag.String("endpoint", "", "live database endpoint to check")
+	catalog := flag.String("catalog", "", "package level sqlbuilder.Catalog declared in code, like github.com/x/y/pkg/model.Catalog")
+	output := flag.String("o", "text", "output format, text or json")
+
+	flag.Parse()
+
+	if err := run(context.Background(), *endpoint, *catalog, *output); err != nil {
+		exitErr := &exec.ExitError{}
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+			return
+		}
+
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+		return
+	}
+}
+
+func run(ctx context.Context, endpoint string, catalog string, output string) error {
+	if endpoint == "" || catalog == "" {
+		return fmt.Errorf("both -endpoint and -catalog are required")
+	}
+
+	i := strings.LastIndex(catalog, ".")
+	if i <= 0 || strings.HasSuffix(catalog[:i], "/") || i == len(catalog)-1 {
+		return fmt.Errorf("invalid catalog %q, should be <import path>.<var>", catalog)
+	}
+
+	dir, err := os.MkdirTemp("", "schemadrift")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "main.go")
+
+	if err := os.WriteFile(filename, []byte(fmt.Sprintf(checkerTemplate, catalog[:i], catalog[i+1:])), 0o644); err != nil {
+		return err
+	}
+
+	checker := filepath.Join(dir, "checker")
+
+	// go run exits 1 for any failure, build first to keep exit status 2 for drift
+	build := exec.CommandContext(ctx, "go", "build", "-o", checker, filename)
+	build.Stdout = os.Stderr
+	build.Stderr = os.Stderr
+
+	if err := build.Run(); err != nil {
+		return fmt.Errorf("build checker for %s failed: %v", catalog, err)
+	}
+
+	cmd := exec.CommandContext(ctx, checker, "-endpoint="+endpoint, "-o="+output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+const checkerTemplate = `package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/octohelm/storage/pkg/migrator"
+	"github.com/octohelm/storage/pkg/session"
+
+	_ "github.com/octohelm/storage/pkg/session/db"
+
+	catalog %q
+)
+
+func main() {
+	endpoint := flag.String("endpoint", "", "")
+	output := flag.String("o", "text", "")
+
+	flag.Parse()
+
+	ctx := context.Background()
+
+	a, err := session.Open(ctx, *endpoint)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer a.Close()
+
+	r, err := migrator.DetectDrift(ctx, a, catalog.%s)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	switch *output {
+	case "json":
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		_ = e.Encode(r)
+	default:
+		_, _ = fmt.Fprint(os.Stdout, r.String())
+	}
+
+	if r.HasDrift() {
+		os.Exit(2)
+	}
+}
+`